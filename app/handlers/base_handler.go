@@ -32,7 +32,24 @@ func GetPageInfo(ctx iris.Context) (*vo.PageInfo, exception.Exception) {
 	var err error
 	maxPageSize := config.GetConfig().Server.MaxPageSize
 	textSearch := ctx.URLParam(constants.TextSearch)
+	sorts, err := vo.ParseSort(ctx.URLParam(constants.Sort))
+	if err != nil {
+		return nil, exception.Wrap(response.ExceptionInvalidRequestParameters, err)
+	}
+	withCount, err := ctx.URLParamBool(constants.WithCount)
+	if err != nil && ctx.URLParamExists(constants.WithCount) {
+		return nil, exception.Wrap(response.ExceptionInvalidRequestParameters, err)
+	}
+	cursor := ctx.URLParam(constants.Cursor)
 	switch {
+	// 游标分页忽略 page, 未传 page_size 时取最大页大小
+	case cursor != "":
+		page = 1
+		pageSize, err = ctx.URLParamInt(constants.PageSize)
+		if err != nil || pageSize < 1 || pageSize > maxPageSize {
+			pageSize = maxPageSize
+		}
+
 	case ctx.URLParamExists(constants.Page) && ctx.URLParamExists(constants.PageSize):
 		page, err = ctx.URLParamInt(constants.Page)
 		if err != nil || page < 1 {
@@ -52,8 +69,11 @@ func GetPageInfo(ctx iris.Context) (*vo.PageInfo, exception.Exception) {
 	}
 
 	return &vo.PageInfo{
-		Page:     page,
-		PageSize: pageSize,
-		Keywords: textSearch,
+		Page:      page,
+		PageSize:  pageSize,
+		Keywords:  textSearch,
+		Sorts:     sorts,
+		Cursor:    cursor,
+		WithCount: withCount,
 	}, nil
 }
@@ -0,0 +1,143 @@
+package v1
+
+import (
+	"lpms/app/handlers"
+	"lpms/app/response"
+	"lpms/app/service"
+	"lpms/app/vo"
+	"lpms/constant"
+	"lpms/exception"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/mvc"
+)
+
+type AttachmentHandler struct {
+	handlers.BaseHandler
+	Svc service.AttachmentService
+}
+
+func NewAttachmentHandler() *AttachmentHandler {
+	return &AttachmentHandler{
+		Svc: service.GetAttachmentService(),
+	}
+}
+
+// List godoc
+// @Summary 项目附件列表
+// @Description 项目的全部附件, 按排序排列; 仅项目创建者及管理员可查看
+// @Tags 项目 - 附件
+// @Param source query string true "所属项目库 reserve:储备库项目, gov:实施库政府投资项目, industry:实施库产业项目"
+// @Param project_id query int true "项目id"
+// @Param category query int false "类别 0:其他,1:CAD图纸,2:现场照片,3:立项批复,4:可行性研究报告,5:用地预审意见,6:环境影响评价"
+// @Success 200 {array} vo.AttachmentResp "查询附件成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 404 {object} vo.Error "项目不存在"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/attachments [get]
+func (ah *AttachmentHandler) List(ctx iris.Context) mvc.Result {
+	projectID, err := ctx.URLParamInt64(constant.ProjectID)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	var category *int
+	if ctx.URLParamExists(constant.Category) {
+		value, err := ctx.URLParamInt(constant.Category)
+		if err != nil {
+			return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+		}
+		category = &value
+	}
+	resp, ex := ah.Svc.List(ah.UserName, &vo.AttachmentProject{
+		Source:    ctx.URLParam(constant.Source),
+		ProjectID: projectID,
+	}, category)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// Attach godoc
+// @Summary 添加项目附件
+// @Description 将已上传的文件添加为项目附件, 可一次添加多个同类别文件, 排在已有附件之后; 仅可添加本人上传的文件, 返回项目全部附件
+// @Tags 项目 - 附件
+// @Param parameters body vo.AttachmentReq true "AttachmentReq"
+// @Success 200 {array} vo.AttachmentResp "添加附件成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 404 {object} vo.Error "项目不存在"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/attachments [post]
+func (ah *AttachmentHandler) Attach(ctx iris.Context) mvc.Result {
+	req := &vo.AttachmentReq{}
+	if err := ctx.ReadJSON(req); err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	resp, ex := ah.Svc.Attach(ah.UserName, req)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// Detach godoc
+// @Summary 移除项目附件
+// @Description 移除附件与项目的关联, 不删除文件
+// @Tags 项目 - 附件
+// @Param id path string true "附件id"
+// @Success 200 "移除附件成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 404 {object} vo.Error "附件不存在"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/attachments/{id} [delete]
+func (ah *AttachmentHandler) Detach(ctx iris.Context) mvc.Result {
+	id, err := ctx.Params().GetInt64(constant.ID)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	if ex := ah.Svc.Detach(ah.UserName, id); ex != nil {
+		return response.Error(ex)
+	}
+	return response.OK()
+}
+
+// Sort godoc
+// @Summary 调整项目附件顺序
+// @Description 按传入的附件id顺序重排, 须包含项目的全部附件
+// @Tags 项目 - 附件
+// @Param parameters body vo.AttachmentSortReq true "AttachmentSortReq"
+// @Success 200 "调整顺序成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 404 {object} vo.Error "项目不存在"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/attachments/sort [put]
+func (ah *AttachmentHandler) Sort(ctx iris.Context) mvc.Result {
+	req := &vo.AttachmentSortReq{}
+	if err := ctx.ReadJSON(req); err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	if ex := ah.Svc.Sort(ah.UserName, req); ex != nil {
+		return response.Error(ex)
+	}
+	return response.OK()
+}
+
+// BeforeActivation 初始化路由
+func (ah *AttachmentHandler) BeforeActivation(b mvc.BeforeActivation) {
+	b.Handle(iris.MethodGet, "/", "List")
+	b.Handle(iris.MethodPost, "/", "Attach")
+	b.Handle(iris.MethodPut, "/sort", "Sort")
+	b.Handle(iris.MethodDelete, "/{id:string}", "Detach")
+}
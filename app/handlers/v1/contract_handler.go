@@ -0,0 +1,211 @@
+package v1
+
+import (
+	"lpms/app/handlers"
+	"lpms/app/response"
+	"lpms/app/service"
+	"lpms/app/vo"
+	"lpms/constant"
+	"lpms/exception"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/mvc"
+)
+
+type ContractHandler struct {
+	handlers.BaseHandler
+	Svc service.ContractService
+}
+
+func NewContractHandler() *ContractHandler {
+	return &ContractHandler{
+		Svc: service.GetContractService(),
+	}
+}
+
+// Create godoc
+// @Summary 新增实施库项目合同
+// @Description 新增实施库项目合同
+// @Tags 实施库 - 合同台账
+// @Param parameters body vo.ContractReq true "ContractReq"
+// @Success 200  "新增合同成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/gov/contract [post]
+func (ch *ContractHandler) Create(ctx iris.Context) mvc.Result {
+	req := &vo.ContractReq{}
+	if err := ctx.ReadJSON(req); err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	if ex := ch.Svc.Create(ch.UserName, req); ex != nil {
+		return response.Error(ex)
+	}
+	return response.OK()
+}
+
+// Create godoc
+// @Summary 查看实施库项目合同
+// @Description 查看实施库项目合同
+// @Tags 实施库 - 合同台账
+// @Param id path string true "合同id"
+// @Success 200 {object} vo.ContractResp "查询合同成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/gov/contract/{id} [get]
+func (ch *ContractHandler) Get(ctx iris.Context) mvc.Result {
+	id, err := ctx.Params().GetInt64(constant.ID)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	resp, ex := ch.Svc.Get(id)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// Create godoc
+// @Summary 获取实施库项目合同列表
+// @Description 获取实施库项目合同列表
+// @Tags 实施库 - 合同台账
+// @Param page query int false "请求页"
+// @Param page_size query int false "页大小"
+// @Param parameters body vo.ContractFilterParam true "ContractFilterParam"
+// @Success 200 {object} vo.DataPagination{data=[]vo.ContractResp} "查询合同列表成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/gov/contracts [post]
+func (ch *ContractHandler) List(ctx iris.Context) mvc.Result {
+	page, ex := handlers.GetPageInfo(ctx)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	params := &vo.ContractFilterParam{}
+	if err := ctx.ReadJSON(params); err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	resp, ex := ch.Svc.List(params, page)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// Create godoc
+// @Summary 修改实施库项目合同
+// @Description 修改实施库项目合同, 仅合同或所属项目的创建者及管理员可修改
+// @Tags 实施库 - 合同台账
+// @Param id path string true "合同id"
+// @Param parameters body vo.ContractReq true "ContractReq"
+// @Success 200  "修改合同成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/gov/contract/{id} [put]
+func (ch *ContractHandler) Update(ctx iris.Context) mvc.Result {
+	id, err := ctx.Params().GetInt64(constant.ID)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	param := &vo.ContractReq{}
+	if err := ctx.ReadJSON(param); err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	if ex := ch.Svc.Update(ch.UserName, id, param); ex != nil {
+		return response.Error(ex)
+	}
+	return response.OK()
+}
+
+// Create godoc
+// @Summary 删除实施库项目合同
+// @Description 删除实施库项目合同, 仅合同或所属项目的创建者及管理员可删除
+// @Tags 实施库 - 合同台账
+// @Param id path string true "合同id"
+// @Success 200 "删除合同成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/gov/contract/{id} [delete]
+func (ch *ContractHandler) Delete(ctx iris.Context) mvc.Result {
+	id, err := ctx.Params().GetInt64(constant.ID)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	if ex := ch.Svc.Delete(ch.UserName, id); ex != nil {
+		return response.Error(ex)
+	}
+	return response.OK()
+}
+
+// Create godoc
+// @Summary 项目合同汇总
+// @Description 项目合同数量及合同总额
+// @Tags 实施库 - 合同台账
+// @Param project_id path string true "所属项目id"
+// @Success 200 {object} vo.ContractTotalResp "查询项目合同汇总成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/gov/contract/{project_id}/total [get]
+func (ch *ContractHandler) ProjectTotal(ctx iris.Context) mvc.Result {
+	projectID, err := ctx.Params().GetInt64(constant.ProjectID)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	resp, ex := ch.Svc.ProjectTotal(projectID)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// Create godoc
+// @Summary 合同相对方统计
+// @Description 按合同相对方统计合同数量及金额, 按合同数量倒序
+// @Tags 实施库 - 合同台账
+// @Param parameters body vo.ContractFilterParam true "ContractFilterParam"
+// @Success 200 {array} vo.ContractCounterpartyResp "查询合同相对方统计成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/gov/contract/counterparty [post]
+func (ch *ContractHandler) CounterpartyStat(ctx iris.Context) mvc.Result {
+	params := &vo.ContractFilterParam{}
+	if err := ctx.ReadJSON(params); err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	resp, ex := ch.Svc.CounterpartyStat(params)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// BeforeActivation 初始化路由
+func (ch *ContractHandler) BeforeActivation(b mvc.BeforeActivation) {
+	b.Handle(iris.MethodPost, "/gov/contract", "Create")
+	b.Handle(iris.MethodGet, "/gov/contract/{id:string}", "Get")
+	b.Handle(iris.MethodPost, "/gov/contracts", "List")
+	b.Handle(iris.MethodPut, "/gov/contract/{id:string}", "Update")
+	b.Handle(iris.MethodDelete, "/gov/contract/{id:string}", "Delete")
+	b.Handle(iris.MethodGet, "/gov/contract/{project_id:string}/total", "ProjectTotal")
+	b.Handle(iris.MethodPost, "/gov/contract/counterparty", "CounterpartyStat")
+}
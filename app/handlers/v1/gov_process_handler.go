@@ -167,10 +167,46 @@ func (ih *GovProgressHandler) ListGovProgressCompare(ctx iris.Context) mvc.Resul
 	return response.JSON(resp)
 }
 
+// Report godoc
+// @Summary 导出政府投资项目月度进展情况表
+// @Description 按责任单位、项目类型分组导出当月全部在建政府投资项目的计划与完成投资、累计投资、形象进度及存在问题, 含小计与总计
+// @Tags 实施库 - 政府投资项目 - 进度
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param year query string true "年份 eg: 2022"
+// @Param month query string true "月份 eg: 3"
+// @Param snapshot query bool false "是否使用该月月末快照数据, 默认实时数据"
+// @Success 200 {file} file "导出成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/gov/progress/report [get]
+func (ih *GovProgressHandler) Report(ctx iris.Context) mvc.Result {
+	year, err := ctx.URLParamInt(constant.Year)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	month, err := ctx.URLParamInt(constant.Month)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	snapshot, err := ctx.URLParamBool(constant.Snapshot)
+	if err != nil && ctx.URLParamExists(constant.Snapshot) {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	file, ex := ih.Svc.Report(ih.UserName, year, month, snapshot)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.Excel(service.ProgressReportFilename(year, month), file)
+}
+
 // BeforeActivation 初始化路由
 func (ih *GovProgressHandler) BeforeActivation(b mvc.BeforeActivation) {
 	b.Handle(iris.MethodPost, "/gov/progress", "Create")
 	b.Handle(iris.MethodGet, "/gov/progress", "Get")
+	b.Handle(iris.MethodGet, "/gov/progress/report", "Report")
 	b.Handle(iris.MethodPut, "/gov/progress/{id:string}", "Update")
 	b.Handle(iris.MethodGet, "/gov/progress/{project_id:string}/list", "ListPlan")
 	b.Handle(iris.MethodGet, "/gov/progress/compare/{project_id:string}", "ListGovProgressCompare")
@@ -77,6 +77,10 @@ func (ih *ImplementGovHandler) Get(ctx iris.Context) mvc.Result {
 // @Tags 实施库 - 政府投资项目
 // @Param page query int false "请求页"
 // @Param page_size query int false "页大小"
+// @Param sort query string false "排序, 格式 字段[:asc|desc], 多个字段以逗号分隔, eg: total_investment:desc,name; 可选字段: id,name,type,project_code,level,project_type,total_investment,plan_begin,start_time,finish_time,create_at,status"
+// @Param cursor query string false "游标, 取上一页返回的 next_cursor, 传入时忽略 page; 按关键词相关度排序时不返回游标"
+// @Param with_count query bool false "游标分页时是否统计总数, 默认不统计, total_count 为 -1"
+// @Param keywords query string false "关键词, 检索项目名称、建设地点、建设内容等, 多个词以空格分隔, 结果按相关度排序"
 // @Param parameters body vo.ImplementGovFilterParam true "ImplementGovFilterParam"
 // @Success 200 {object} vo.DataPagination{data=[]vo.ListImplementGovResp} "查询实施库政府投资项目列表成功"
 // @Failure 400 {object} vo.Error "请求参数错误"
@@ -170,12 +174,167 @@ func (ih *ImplementGovHandler) ListStatusCount(ctx iris.Context) mvc.Result {
 	return response.JSON(resp)
 }
 
+// PortfolioStat godoc
+// @Summary 实施库项目投资统计
+// @Description 政府投资项目与产业项目合并统计项目数、总投资、当年累计完成投资及完成率,
+// @Description 分别按项目分类、项目类型、项目级别、重点类型、责任单位汇总; 筛选条件与列表一致
+// @Tags 实施库 - 政府投资项目
+// @Param parameters body vo.PortfolioStatFilter true "PortfolioStatFilter"
+// @Success 200 {object} vo.PortfolioStatResp "成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/stat/portfolio [post]
+func (ih *ImplementGovHandler) PortfolioStat(ctx iris.Context) mvc.Result {
+	params := &vo.PortfolioStatFilter{}
+	if err := ctx.ReadJSON(params); err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	resp, ex := ih.Svc.PortfolioStat(ih.UserName, params)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// MapProjects godoc
+// @Summary 实施库项目地图点位
+// @Description 返回有项目位置的政府投资项目与产业项目 GeoJSON FeatureCollection, 要素属性含项目状态及当月进度灯;
+// @Description 按地图范围(bbox)及与列表一致的筛选条件查询, with_boundary=true 时有红线范围的项目另返回 layer=boundary 的要素
+// @Tags 实施库 - 政府投资项目
+// @Param parameters body vo.MapFilterParam true "MapFilterParam"
+// @Success 200 {object} vo.GeoFeatureCollection "成功, 要素属性见 vo.MapProjectProps"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/map/projects [post]
+func (ih *ImplementGovHandler) MapProjects(ctx iris.Context) mvc.Result {
+	params := &vo.MapFilterParam{}
+	if err := ctx.ReadJSON(params); err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	resp, ex := ih.Svc.MapProjects(ih.UserName, params)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// MapTownships godoc
+// @Summary 实施库项目地图街镇汇总
+// @Description 按所属街镇汇总项目数、总投资、各状态项目数及红灯项目数, 返回 GeoJSON FeatureCollection,
+// @Description 要素位置为街镇内有位置项目的平均位置; 筛选条件与地图点位一致, 传 bbox 时仅统计范围内有位置的项目
+// @Tags 实施库 - 政府投资项目
+// @Param parameters body vo.MapFilterParam true "MapFilterParam"
+// @Success 200 {object} vo.GeoFeatureCollection "成功, 要素属性见 vo.MapTownshipProps"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/map/townships [post]
+func (ih *ImplementGovHandler) MapTownships(ctx iris.Context) mvc.Result {
+	params := &vo.MapFilterParam{}
+	if err := ctx.ReadJSON(params); err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	resp, ex := ih.Svc.MapTownships(ih.UserName, params)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// DataAnalysis godoc
+// @Summary 实施库项目数据分析
+// @Description 按月或按年统计新开工项目数(按开工时间)、竣工项目数(按竣工时间)及月度进度填报的完成投资额, 无数据的时间段返回0;
+// @Description compare=true 时同时返回上年同期数据及同比增长率
+// @Tags 实施库 - 政府投资项目
+// @Param parameters body vo.ImplementAnalysisFilter true "ImplementAnalysisFilter"
+// @Success 200 {array} vo.ImplementAnalysisResp "成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/stat/data-analysis [post]
+func (ih *ImplementGovHandler) DataAnalysis(ctx iris.Context) mvc.Result {
+	params := &vo.ImplementAnalysisFilter{}
+	if err := ctx.ReadJSON(params); err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	resp, ex := ih.Svc.DataAnalysis(ih.UserName, params)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// Create godoc
+// @Summary 年度资金计划与完成投资对账
+// @Description 按项目对比资金详情中当年各资金来源计划与月度进度填报的完成投资额, 标记超计划及严重滞后项目
+// @Tags 实施库 - 政府投资项目
+// @Param parameters body vo.ReconcileFilterParam true "ReconcileFilterParam"
+// @Success 200 {array} vo.ReconcileResp "查询资金对账成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/gov/reconcile [post]
+func (ih *ImplementGovHandler) Reconcile(ctx iris.Context) mvc.Result {
+	params := &vo.ReconcileFilterParam{}
+	if err := ctx.ReadJSON(params); err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	resp, ex := ih.Svc.Reconcile(ih.UserName, params)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// Export godoc
+// @Summary 导出政府投资项目列表
+// @Description 按列表筛选条件导出政府投资项目 .xlsx, 枚举字段导出为中文名称
+// @Tags 实施库 - 政府投资项目
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param parameters body vo.ImplementGovFilterParam true "ImplementGovFilterParam"
+// @Success 200 {file} file "导出成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/gov/projects/export [post]
+func (ih *ImplementGovHandler) Export(ctx iris.Context) mvc.Result {
+	params := &vo.ImplementGovFilterParam{}
+	if err := ctx.ReadJSON(params); err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	file, ex := ih.Svc.Export(ih.UserName, params)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.Excel(service.ExportFilename("政府投资项目"), file)
+}
+
 // BeforeActivation 初始化路由
 func (ih *ImplementGovHandler) BeforeActivation(b mvc.BeforeActivation) {
 	b.Handle(iris.MethodPost, "/gov/project", "Create")
 	b.Handle(iris.MethodGet, "/gov/project/{id:string}", "Get")
 	b.Handle(iris.MethodPost, "/gov/projects", "List")
+	b.Handle(iris.MethodPost, "/gov/projects/export", "Export")
 	b.Handle(iris.MethodDelete, "/gov/project/{id:string}", "Delete")
 	b.Handle(iris.MethodDelete, "/gov/project/multi", "MultiDelete")
 	b.Handle(iris.MethodPost, "/gov/list/count", "ListStatusCount")
+	b.Handle(iris.MethodPost, "/stat/portfolio", "PortfolioStat")
+	b.Handle(iris.MethodPost, "/stat/data-analysis", "DataAnalysis")
+	b.Handle(iris.MethodPost, "/gov/reconcile", "Reconcile")
+	b.Handle(iris.MethodPost, "/map/projects", "MapProjects")
+	b.Handle(iris.MethodPost, "/map/townships", "MapTownships")
 }
@@ -77,6 +77,10 @@ func (ih *ImpleIndustryHandler) Get(ctx iris.Context) mvc.Result {
 // @Tags 实施库 - 产业项目
 // @Param page query int false "请求页"
 // @Param page_size query int false "页大小"
+// @Param sort query string false "排序, 格式 字段[:asc|desc], 多个字段以逗号分隔, eg: total_investment:desc,name; 可选字段: id,name,level,project_type,total_investment,plan_begin,start_time,finish_time,create_at,status"
+// @Param cursor query string false "游标, 取上一页返回的 next_cursor, 传入时忽略 page; 按关键词相关度排序时不返回游标"
+// @Param with_count query bool false "游标分页时是否统计总数, 默认不统计, total_count 为 -1"
+// @Param keywords query string false "关键词, 检索项目名称、建设地点、建设内容等, 多个词以空格分隔, 结果按相关度排序"
 // @Param parameters body vo.ImpleIndustryFilterParam true "ImpleIndustryFilterParam"
 // @Success 200 {object} vo.DataPagination{data=[]vo.ListImpleIndustryResp} "查询实施库产业项目列表成功"
 // @Failure 400 {object} vo.Error "请求参数错误"
@@ -146,11 +150,37 @@ func (ih *ImpleIndustryHandler) MultiDelete(ctx iris.Context) mvc.Result {
 	return response.OK()
 }
 
+// Export godoc
+// @Summary 导出产业项目列表
+// @Description 按列表筛选条件导出产业项目 .xlsx, 枚举字段导出为中文名称
+// @Tags 实施库 - 产业项目
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param parameters body vo.ImpleIndustryFilterParam true "ImpleIndustryFilterParam"
+// @Success 200 {file} file "导出成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/indust/projects/export [post]
+func (ih *ImpleIndustryHandler) Export(ctx iris.Context) mvc.Result {
+	params := &vo.ImpleIndustryFilterParam{}
+	if err := ctx.ReadJSON(params); err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	file, ex := ih.Svc.Export(ih.UserName, params)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.Excel(service.ExportFilename("产业项目"), file)
+}
+
 // BeforeActivation 初始化路由
 func (ih *ImpleIndustryHandler) BeforeActivation(b mvc.BeforeActivation) {
 	b.Handle(iris.MethodPost, "/indust/project", "Create")
 	b.Handle(iris.MethodGet, "/indust/project/{id:string}", "Get")
 	b.Handle(iris.MethodPost, "/indust/projects", "List")
+	b.Handle(iris.MethodPost, "/indust/projects/export", "Export")
 	b.Handle(iris.MethodDelete, "/indust/project/{id:string}", "Delete")
 	b.Handle(iris.MethodDelete, "/indust/project/multi", "MultiDelete")
 }
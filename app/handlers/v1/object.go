@@ -26,12 +26,14 @@ func NewObjectHandler() *ObjectHandler {
 
 // Upload godoc
 // @Summary 存储对象
-// @Description 存储对象并返回对象的id
+// @Description 存储一个或多个文件(同名字段 uploadfile 可重复), 按上传顺序返回文件id、名称、大小及类型; 任一文件失败时本次均不保存
+// @Description 文件按上传类别校验扩展名、大小及文件头识别的类型, 并经安全扫描, 未通过扫描的文件隔离保存且不返回id
 // @Tags 项目 - 文件
 // @Accept mpfd
 // @Produce json
-// @Param uploadfile formData file true "文件"
-// @Success 200 {object} types.UUID "响应成功"
+// @Param uploadfile formData file true "文件, 可多个"
+// @Param category formData string false "上传类别 default:默认, cad:CAD文件, image:图片, document:文档; 不传时为 default"
+// @Success 200 {array} vo.ObjectMeta "响应成功"
 // @Failure 400 {object} vo.Error "请求参数错误"
 // @Failure 401 {object} vo.Error "当前用户登录令牌失效"
 // @Failure 403 {object} vo.Error "当前操作无权限"
@@ -39,40 +41,166 @@ func NewObjectHandler() *ObjectHandler {
 // @Security ApiKeyAuth
 // @Router /api/v1/object/file/upload [post]
 func (oh *ObjectHandler) Upload(ctx iris.Context) mvc.Result {
-	file, info, err := ctx.FormFile(constant.File)
+	_, files, err := ctx.FormFiles(constant.File)
 	if err != nil {
 		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
 	}
 
-	id, ex := oh.Service.UploadFromReader("oh.UserName", info.Filename, info.Size, file)
+	result, ex := oh.Service.Upload(oh.UserName, ctx.FormValue(constant.Category), files)
 	if ex != nil {
 		return response.Error(ex)
 	}
-	return response.JSON(vo.UUID{
-		ID: id,
-	})
+	return response.JSON(result)
 }
 
 // Get godoc
-// @Summary 获取对象
-// @Description 获取对象
+// @Summary 下载对象
+// @Description 下载文件, 仅上传者、管理员及可查看引用该文件项目的用户可下载; 支持 Range 分段下载及 If-None-Match 缓存校验
 // @Tags 项目 - 文件
 // @Param id path string true "对象id"
+// @Param Range header string false "分段下载范围 eg: bytes=0-1023"
 // @Success 200 {string} byte "获取文件成功"
-// @Failure 500 {string} byte "服务器内部错误"
+// @Success 206 {string} byte "获取文件分段成功"
+// @Success 304 {string} string "文件未修改"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 404 {object} vo.Error "文件不存在"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/object/file/{id} [get]
+func (oh *ObjectHandler) Get(ctx iris.Context) mvc.Result {
+	obj, ex := oh.Service.Download(oh.UserName, ctx.Params().Get(constant.ID))
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.Stream(obj)
+}
+
+// SignURL godoc
+// @Summary 获取对象下载链接
+// @Description 生成短期有效(5分钟)的签名下载链接, 下载时无需登录令牌, 权限同下载对象
+// @Tags 项目 - 文件
+// @Produce json
+// @Param id path string true "对象id"
+// @Success 200 {object} vo.ObjectURL "响应成功"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 404 {object} vo.Error "文件不存在"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/object/file/{id}/url [get]
+func (oh *ObjectHandler) SignURL(ctx iris.Context) mvc.Result {
+	resp, ex := oh.Service.SignURL(oh.UserName, ctx.Params().Get(constant.ID))
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// SignedGet godoc
+// @Summary 按签名链接下载对象
+// @Description 按获取对象下载链接接口返回的地址下载文件, 链接过期或签名无效时无权限
+// @Tags 项目 - 文件
+// @Param id path string true "对象id"
+// @Param expires query int true "过期时间戳"
+// @Param signature query string true "签名"
+// @Param Range header string false "分段下载范围 eg: bytes=0-1023"
+// @Success 200 {string} byte "获取文件成功"
+// @Success 206 {string} byte "获取文件分段成功"
+// @Success 304 {string} string "文件未修改"
+// @Failure 401 {object} vo.Error "缺少下载签名"
+// @Failure 403 {object} vo.Error "签名无效或已过期"
+// @Failure 404 {object} vo.Error "文件不存在"
+// @Failure 500 {object} vo.Error "服务器内部错误"
 // @Router /object/file/{id} [get]
-func (oh *ObjectHandler) Get(ctx iris.Context) {
-	id := ctx.Params().Get(constant.ID)
+func (oh *ObjectHandler) SignedGet(ctx iris.Context) {
+	obj, ex := oh.Service.DownloadSigned(ctx.Params().Get(constant.ID), ctx.URLParam(constant.Expires),
+		ctx.URLParam(constant.Signature))
+	if ex != nil {
+		response.Error(ex).Dispatch(ctx)
+		return
+	}
+	response.Stream(obj).Dispatch(ctx)
+}
+
+// PresignUpload godoc
+// @Summary 申请直传存储
+// @Description 返回预签名上传地址(15分钟有效), 客户端以 PUT 方式将文件内容直接上传到存储, 完成后调用完成上传接口保存文件记录; 存储为本地文件系统时不支持, 返回 400
+// @Tags 项目 - 文件
+// @Accept json
+// @Produce json
+// @Param param body vo.ObjectUploadReq true "文件信息"
+// @Success 200 {object} vo.ObjectUploadURL "响应成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/object/presign/upload [post]
+func (oh *ObjectHandler) PresignUpload(ctx iris.Context) mvc.Result {
+	param := &vo.ObjectUploadReq{}
+	if err := ctx.ReadJSON(param); err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	resp, ex := oh.Service.PresignUpload(oh.UserName, param)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
 
-	obj, ex := oh.Service.Download(id)
+// FinalizeUpload godoc
+// @Summary 完成直传存储
+// @Description 校验文件已上传且大小与申请时一致后保存文件记录并返回文件信息; 大小不一致时删除已上传的内容
+// @Tags 项目 - 文件
+// @Accept json
+// @Produce json
+// @Param param body vo.ObjectFinalizeReq true "上传凭证"
+// @Success 200 {object} vo.ObjectMeta "响应成功"
+// @Failure 400 {object} vo.Error "请求参数错误、文件尚未上传或大小不一致"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/object/presign/finalize [post]
+func (oh *ObjectHandler) FinalizeUpload(ctx iris.Context) mvc.Result {
+	param := &vo.ObjectFinalizeReq{}
+	if err := ctx.ReadJSON(param); err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	resp, ex := oh.Service.FinalizeUpload(oh.UserName, param)
 	if ex != nil {
-		ctx.ResponseWriter().Write([]byte(ex.Error()))
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// PresignDownload godoc
+// @Summary 获取存储直接下载地址
+// @Description 返回直接从存储下载文件的预签名地址(5分钟有效), 权限同下载对象; 存储为本地文件系统时返回服务端签名的下载链接
+// @Tags 项目 - 文件
+// @Produce json
+// @Param id path string true "对象id"
+// @Success 200 {object} vo.ObjectURL "响应成功"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 404 {object} vo.Error "文件不存在"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/object/file/{id}/presign [get]
+func (oh *ObjectHandler) PresignDownload(ctx iris.Context) mvc.Result {
+	resp, ex := oh.Service.PresignDownload(oh.UserName, ctx.Params().Get(constant.ID))
+	if ex != nil {
+		return response.Error(ex)
 	}
-	ctx.ResponseWriter().Write([]byte(obj.Content))
+	return response.JSON(resp)
 }
 
 // BeforeActivation 初始化路由
 func (oh *ObjectHandler) BeforeActivation(b mvc.BeforeActivation) {
 	b.Handle(iris.MethodPost, "/file/upload", "Upload")
-	// b.Handle(iris.MethodGet, "/file/{id:string}", "Get")
+	b.Handle(iris.MethodGet, "/file/{id:string}", "Get")
+	b.Handle(iris.MethodGet, "/file/{id:string}/url", "SignURL")
+	b.Handle(iris.MethodGet, "/file/{id:string}/presign", "PresignDownload")
+	b.Handle(iris.MethodPost, "/presign/upload", "PresignUpload")
+	b.Handle(iris.MethodPost, "/presign/finalize", "FinalizeUpload")
 }
@@ -0,0 +1,135 @@
+package v1
+
+import (
+	"lpms/app/handlers"
+	"lpms/app/response"
+	"lpms/app/service"
+	"lpms/constant"
+	"lpms/exception"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/mvc"
+)
+
+type ProgressPhotoHandler struct {
+	handlers.BaseHandler
+	Svc service.ProgressPhotoService
+}
+
+func NewProgressPhotoHandler() *ProgressPhotoHandler {
+	return &ProgressPhotoHandler{
+		Svc: service.GetProgressPhotoService(),
+	}
+}
+
+// Upload godoc
+// @Summary 上传月度进度现场照片
+// @Description 上传月度进度现场照片, 支持多张; 自动读取照片EXIF中的拍摄时间及GPS坐标, 并生成缩略图
+// @Tags 实施库 - 政府投资项目 - 进度
+// @Accept mpfd
+// @Param id path string true "项目进度记录id"
+// @Param uploadfile formData file true "照片文件(可多个), 类型及大小按 image 上传类别的规则校验"
+// @Param description formData string false "照片说明"
+// @Success 200 {array} vo.ProgressPhotoResp "上传现场照片成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/gov/progress/photos/{id} [post]
+func (ph *ProgressPhotoHandler) Upload(ctx iris.Context) mvc.Result {
+	id, err := ctx.Params().GetInt64(constant.ID)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	_, files, err := ctx.FormFiles(constant.File)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	resp, ex := ph.Svc.Upload(ph.UserName, id, ctx.FormValue(constant.Description), files)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// List godoc
+// @Summary 查看月度进度现场照片
+// @Description 查看某次月度进度填报的现场照片, 按拍摄时间排序
+// @Tags 实施库 - 政府投资项目 - 进度
+// @Param id path string true "项目进度记录id"
+// @Success 200 {array} vo.ProgressPhotoResp "查询现场照片成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/gov/progress/photos/{id} [get]
+func (ph *ProgressPhotoHandler) List(ctx iris.Context) mvc.Result {
+	id, err := ctx.Params().GetInt64(constant.ID)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	resp, ex := ph.Svc.ListByProgressID(id)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// Timeline godoc
+// @Summary 项目现场照片时间轴
+// @Description 按月份分组列出项目现场照片, 月份倒序; 照片通过 /object/file/{thumbnail_id} 获取缩略图
+// @Tags 实施库 - 政府投资项目 - 进度
+// @Param project_id path string true "所属项目id"
+// @Param year query string false "年份, 不传则查询全部"
+// @Success 200 {array} vo.ProgressPhotoMonth "查询现场照片时间轴成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/gov/progress/timeline/{project_id} [get]
+func (ph *ProgressPhotoHandler) Timeline(ctx iris.Context) mvc.Result {
+	projectID, err := ctx.Params().GetInt64(constant.ProjectID)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	year := ctx.URLParamIntDefault(constant.Year, 0)
+	resp, ex := ph.Svc.Timeline(projectID, year)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// Delete godoc
+// @Summary 删除月度进度现场照片
+// @Description 删除现场照片及其缩略图, 仅上传者、项目创建者及管理员可删除; 文件在删除记录后移除
+// @Tags 实施库 - 政府投资项目 - 进度
+// @Param id path string true "照片id"
+// @Success 200 "删除现场照片成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/gov/progress/photo/{id} [delete]
+func (ph *ProgressPhotoHandler) Delete(ctx iris.Context) mvc.Result {
+	id, err := ctx.Params().GetInt64(constant.ID)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	if ex := ph.Svc.Delete(ph.UserName, id); ex != nil {
+		return response.Error(ex)
+	}
+	return response.OK()
+}
+
+// BeforeActivation 初始化路由
+func (ph *ProgressPhotoHandler) BeforeActivation(b mvc.BeforeActivation) {
+	b.Handle(iris.MethodPost, "/gov/progress/photos/{id:string}", "Upload")
+	b.Handle(iris.MethodGet, "/gov/progress/photos/{id:string}", "List")
+	b.Handle(iris.MethodGet, "/gov/progress/timeline/{project_id:string}", "Timeline")
+	b.Handle(iris.MethodDelete, "/gov/progress/photo/{id:string}", "Delete")
+}
@@ -0,0 +1,119 @@
+package v1
+
+import (
+	"lpms/app/handlers"
+	"lpms/app/response"
+	"lpms/app/service"
+	"lpms/app/vo"
+	"lpms/constant"
+	"lpms/exception"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/mvc"
+)
+
+type ProgressReminderHandler struct {
+	handlers.BaseHandler
+	Svc service.ProgressReminderService
+}
+
+func NewProgressReminderHandler() *ProgressReminderHandler {
+	return &ProgressReminderHandler{
+		Svc: service.GetProgressReminderService(),
+	}
+}
+
+// List godoc
+// @Summary 进度逾期提醒列表
+// @Description 月度进度逾期未填报提醒, 管理员查看全部, 其他用户仅查看本人填报的项目
+// @Tags 实施库 - 政府投资项目 - 进度
+// @Param page query int false "请求页"
+// @Param page_size query int false "页大小"
+// @Param parameters body vo.ProgressReminderFilterParam true "ProgressReminderFilterParam"
+// @Success 200 {object} vo.DataPagination{data=[]vo.ProgressReminderResp} "查询逾期提醒成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/gov/progress/reminders [post]
+func (ph *ProgressReminderHandler) List(ctx iris.Context) mvc.Result {
+	page, ex := handlers.GetPageInfo(ctx)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	params := &vo.ProgressReminderFilterParam{}
+	if err := ctx.ReadJSON(params); err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	resp, ex := ph.Svc.List(ph.UserName, params, page)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// Generate godoc
+// @Summary 生成进度逾期提醒
+// @Description 为指定月份应填报而未提交进度的项目生成提醒(仅管理员), 定时任务会在每月填报截止后自动生成
+// @Tags 实施库 - 政府投资项目 - 进度
+// @Param month query string true "月份 eg: 1月 --> 1"
+// @Param year query string true "年份 eg: 2022年 --> 2022"
+// @Success 200 {integer} int "新生成提醒数量"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/gov/progress/reminder [post]
+func (ph *ProgressReminderHandler) Generate(ctx iris.Context) mvc.Result {
+	month, err := ctx.URLParamInt(constant.Month)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	year, err := ctx.URLParamInt(constant.Year)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	count, ex := ph.Svc.Generate(ph.UserName, year, month)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(count)
+}
+
+// Compliance godoc
+// @Summary 进度填报率
+// @Description 指定月份的进度填报率, 按责任单位及街镇统计; 应填报项目为当月底前已入库且当月初未竣工的项目
+// @Tags 实施库 - 政府投资项目 - 进度
+// @Param month query string true "月份 eg: 1月 --> 1"
+// @Param year query string true "年份 eg: 2022年 --> 2022"
+// @Success 200 {object} vo.ComplianceResp "查询填报率成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/gov/progress/compliance [get]
+func (ph *ProgressReminderHandler) Compliance(ctx iris.Context) mvc.Result {
+	month, err := ctx.URLParamInt(constant.Month)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	year, err := ctx.URLParamInt(constant.Year)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	resp, ex := ph.Svc.Compliance(year, month)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// BeforeActivation 初始化路由
+func (ph *ProgressReminderHandler) BeforeActivation(b mvc.BeforeActivation) {
+	b.Handle(iris.MethodPost, "/gov/progress/reminders", "List")
+	b.Handle(iris.MethodPost, "/gov/progress/reminder", "Generate")
+	b.Handle(iris.MethodGet, "/gov/progress/compliance", "Compliance")
+}
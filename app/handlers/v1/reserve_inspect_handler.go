@@ -29,6 +29,9 @@ func NewReserveInspectHandler() *ReserveInspectHandler {
 // @Tags 审批中心 - 项目审核 - 储备库审核
 // @Param page query int false "请求页"
 // @Param page_size query int false "页大小"
+// @Param sort query string false "排序, 格式 字段[:asc|desc], 多个字段以逗号分隔, eg: total_investment:desc,name; 可选字段: id,name,level,project_type,total_investment,plan_begin,create_at,status"
+// @Param cursor query string false "游标, 取上一页返回的 next_cursor, 传入时忽略 page; 按关键词相关度排序时不返回游标"
+// @Param with_count query bool false "游标分页时是否统计总数, 默认不统计, total_count 为 -1"
 // @Param parameters body vo.ReserveInspectParam true "ReserveInspectParam"
 // @Success 200 {object} vo.DataPagination{data=[]vo.ListReserveProResp} "查询储备库前期计划项目列表成功"
 // @Failure 400 {object} vo.Error "请求参数错误"
@@ -59,6 +62,9 @@ func (rh *ReserveInspectHandler) EarlyPlanList(ctx iris.Context) mvc.Result {
 // @Tags 审批中心 - 项目审核 - 储备库审核
 // @Param page query int false "请求页"
 // @Param page_size query int false "页大小"
+// @Param sort query string false "排序, 格式 字段[:asc|desc], 多个字段以逗号分隔, eg: total_investment:desc,name; 可选字段: id,name,level,project_type,total_investment,plan_begin,create_at,status"
+// @Param cursor query string false "游标, 取上一页返回的 next_cursor, 传入时忽略 page; 按关键词相关度排序时不返回游标"
+// @Param with_count query bool false "游标分页时是否统计总数, 默认不统计, total_count 为 -1"
 // @Param parameters body vo.ReserveInspectParam true "ReserveInspectParam"
 // @Success 200 {object} vo.DataPagination{data=[]vo.ListReserveProResp} "查询储备库出库审核项目列表成功"
 // @Failure 400 {object} vo.Error "请求参数错误"
@@ -77,6 +77,10 @@ func (rh *ReserveHandler) Get(ctx iris.Context) mvc.Result {
 // @Tags 储备库 - 项目
 // @Param page query int false "请求页"
 // @Param page_size query int false "页大小"
+// @Param sort query string false "排序, 格式 字段[:asc|desc], 多个字段以逗号分隔, eg: total_investment:desc,name; 可选字段: id,name,level,project_type,total_investment,plan_begin,create_at,status"
+// @Param cursor query string false "游标, 取上一页返回的 next_cursor, 传入时忽略 page; 按关键词相关度排序时不返回游标"
+// @Param with_count query bool false "游标分页时是否统计总数, 默认不统计, total_count 为 -1"
+// @Param keywords query string false "关键词, 检索项目名称、建设地点、建设内容等, 多个词以空格分隔, 结果按相关度排序"
 // @Param parameters body vo.ReserveFilterParam true "ReserveFilterParam"
 // @Success 200 {object} vo.DataPagination{data=[]vo.ListReserveProResp} "查询储备库项目列表成功"
 // @Failure 400 {object} vo.Error "请求参数错误"
@@ -297,11 +301,85 @@ func (rh *ReserveHandler) DataAnalysis(ctx iris.Context) mvc.Result {
 	return response.JSON(resp)
 }
 
+// Export godoc
+// @Summary 导出储备库项目列表
+// @Description 按列表筛选条件导出储备库项目 .xlsx, 枚举字段导出为中文名称
+// @Tags 储备库 - 项目
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param parameters body vo.ReserveFilterParam true "ReserveFilterParam"
+// @Success 200 {file} file "导出成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/reserve/projects/export [post]
+func (rh *ReserveHandler) Export(ctx iris.Context) mvc.Result {
+	params := &vo.ReserveFilterParam{}
+	if err := ctx.ReadJSON(params); err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	file, ex := rh.Svc.Export(rh.UserName, params)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.Excel(service.ExportFilename("储备库项目"), file)
+}
+
+// ImportTemplate godoc
+// @Summary 下载储备库项目导入模板
+// @Description 下载储备库项目批量导入 .xlsx 模板, 第二个工作表为各列填写说明
+// @Tags 储备库 - 项目
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Success 200 {file} file "下载成功"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/reserve/projects/import/template [get]
+func (rh *ReserveHandler) ImportTemplate(ctx iris.Context) mvc.Result {
+	return response.Excel("储备库项目导入模板.xlsx", rh.Svc.ImportTemplate())
+}
+
+// Import godoc
+// @Summary 批量导入储备库项目
+// @Description 上传导入模板 .xlsx 并逐行校验; confirm=false(默认) 为试导入, 只返回逐行错误不入库;
+// @Description confirm=true 时在同一事务内将全部校验通过的行导入为草稿, 创建人为当前用户
+// @Tags 储备库 - 项目
+// @Accept mpfd
+// @Param uploadfile formData file true "导入文件(.xlsx)"
+// @Param confirm query bool false "是否确认导入"
+// @Success 200 {object} vo.ImportResp "导入校验结果"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/reserve/projects/import [post]
+func (rh *ReserveHandler) Import(ctx iris.Context) mvc.Result {
+	confirm, err := ctx.URLParamBool(constant.Confirm)
+	if err != nil && ctx.URLParamExists(constant.Confirm) {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	f, fh, err := ctx.FormFile(constant.File)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	defer f.Close()
+	resp, ex := rh.Svc.Import(rh.UserName, fh, confirm)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
 // BeforeActivation 初始化路由
 func (rh *ReserveHandler) BeforeActivation(b mvc.BeforeActivation) {
 	b.Handle(iris.MethodPost, "/project", "Create")
 	b.Handle(iris.MethodGet, "/project/{id:string}", "Get")
 	b.Handle(iris.MethodPost, "/projects", "List")
+	b.Handle(iris.MethodPost, "/projects/export", "Export")
+	b.Handle(iris.MethodGet, "/projects/import/template", "ImportTemplate")
+	b.Handle(iris.MethodPost, "/projects/import", "Import")
 	b.Handle(iris.MethodDelete, "/project/{id:string}", "Delete")
 	b.Handle(iris.MethodPut, "/project/{id:string}", "Update")
 	b.Handle(iris.MethodDelete, "/project/multi", "MultiDelete")
@@ -0,0 +1,186 @@
+package v1
+
+import (
+	"lpms/app/handlers"
+	"lpms/app/response"
+	"lpms/app/service"
+	"lpms/app/vo"
+	"lpms/constant"
+	"lpms/exception"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/mvc"
+)
+
+type SavedViewHandler struct {
+	handlers.BaseHandler
+	Svc service.SavedViewService
+}
+
+func NewSavedViewHandler() *SavedViewHandler {
+	return &SavedViewHandler{
+		Svc: service.GetSavedViewService(),
+	}
+}
+
+// Create godoc
+// @Summary 保存视图
+// @Description 保存列表的筛选条件及排序, 可共享给本单位用户或设为该列表的默认视图
+// @Tags 保存视图
+// @Param parameters body vo.SavedViewReq true "SavedViewReq"
+// @Success 200 {object} vo.SavedViewResp "保存视图成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/views [post]
+func (vh *SavedViewHandler) Create(ctx iris.Context) mvc.Result {
+	req := &vo.SavedViewReq{}
+	if err := ctx.ReadJSON(req); err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	resp, ex := vh.Svc.Create(vh.UserName, req)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// List godoc
+// @Summary 保存视图列表
+// @Description 本人创建及本单位共享的视图, 默认视图排最前
+// @Tags 保存视图
+// @Param list_type query string false "列表类型, 不传返回全部 eg: reserve"
+// @Success 200 {array} vo.SavedViewResp "查询视图成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/views [get]
+func (vh *SavedViewHandler) List(ctx iris.Context) mvc.Result {
+	resp, ex := vh.Svc.List(vh.UserName, ctx.URLParam(constant.ListType))
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// Update godoc
+// @Summary 修改保存视图
+// @Description 修改视图名称、筛选条件、排序及共享设置(仅创建者), 列表类型不可修改
+// @Tags 保存视图
+// @Param id path string true "视图id"
+// @Param parameters body vo.SavedViewReq true "SavedViewReq"
+// @Success 200 "修改视图成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/views/{id} [put]
+func (vh *SavedViewHandler) Update(ctx iris.Context) mvc.Result {
+	id, err := ctx.Params().GetInt64(constant.ID)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	req := &vo.SavedViewReq{}
+	if err := ctx.ReadJSON(req); err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestBody, err))
+	}
+	if ex := vh.Svc.Update(vh.UserName, id, req); ex != nil {
+		return response.Error(ex)
+	}
+	return response.OK()
+}
+
+// Delete godoc
+// @Summary 删除保存视图
+// @Description 删除保存视图(仅创建者)
+// @Tags 保存视图
+// @Param id path string true "视图id"
+// @Success 200 "删除视图成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/views/{id} [delete]
+func (vh *SavedViewHandler) Delete(ctx iris.Context) mvc.Result {
+	id, err := ctx.Params().GetInt64(constant.ID)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	if ex := vh.Svc.Delete(vh.UserName, id); ex != nil {
+		return response.Error(ex)
+	}
+	return response.OK()
+}
+
+// SetDefault godoc
+// @Summary 设为默认视图
+// @Description 设为该列表的默认视图(仅创建者), 原默认视图自动取消
+// @Tags 保存视图
+// @Param id path string true "视图id"
+// @Success 200 "设置默认视图成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/views/{id}/default [put]
+func (vh *SavedViewHandler) SetDefault(ctx iris.Context) mvc.Result {
+	id, err := ctx.Params().GetInt64(constant.ID)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	if ex := vh.Svc.SetDefault(vh.UserName, id); ex != nil {
+		return response.Error(ex)
+	}
+	return response.OK()
+}
+
+// Apply godoc
+// @Summary 应用保存视图
+// @Description 按视图保存的筛选条件查询对应列表, 返回结果与对应列表接口一致; 未传 sort 时使用视图保存的排序
+// @Tags 保存视图
+// @Param id path string true "视图id"
+// @Param page query int false "请求页"
+// @Param page_size query int false "页大小"
+// @Param keywords query string false "关键词"
+// @Param sort query string false "排序, 覆盖视图保存的排序"
+// @Param cursor query string false "游标, 取上一页返回的 next_cursor, 传入时忽略 page; 按关键词相关度排序时不返回游标"
+// @Param with_count query bool false "游标分页时是否统计总数, 默认不统计, total_count 为 -1"
+// @Success 200 {object} vo.DataPagination "查询列表成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/views/{id}/apply [get]
+func (vh *SavedViewHandler) Apply(ctx iris.Context) mvc.Result {
+	id, err := ctx.Params().GetInt64(constant.ID)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	page, ex := handlers.GetPageInfo(ctx)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	resp, ex := vh.Svc.Apply(vh.UserName, id, page)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// BeforeActivation 初始化路由
+func (vh *SavedViewHandler) BeforeActivation(b mvc.BeforeActivation) {
+	b.Handle(iris.MethodPost, "/", "Create")
+	b.Handle(iris.MethodGet, "/", "List")
+	b.Handle(iris.MethodPut, "/{id:string}", "Update")
+	b.Handle(iris.MethodDelete, "/{id:string}", "Delete")
+	b.Handle(iris.MethodPut, "/{id:string}/default", "SetDefault")
+	b.Handle(iris.MethodGet, "/{id:string}/apply", "Apply")
+}
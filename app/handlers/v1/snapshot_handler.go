@@ -0,0 +1,106 @@
+package v1
+
+import (
+	"lpms/app/handlers"
+	"lpms/app/response"
+	"lpms/app/service"
+	"lpms/constant"
+	"lpms/exception"
+
+	"github.com/kataras/iris/v12"
+	"github.com/kataras/iris/v12/mvc"
+)
+
+type SnapshotHandler struct {
+	handlers.BaseHandler
+	Svc service.SnapshotService
+}
+
+func NewSnapshotHandler() *SnapshotHandler {
+	return &SnapshotHandler{
+		Svc: service.GetSnapshotService(),
+	}
+}
+
+// Take godoc
+// @Summary 生成月末统计快照
+// @Description 冻结指定月份的项目汇总数及各项目关键数据(仅管理员), 只能生成本月或上月的快照, 快照生成后不可修改; 定时任务会在每月初自动生成上月快照
+// @Tags 实施库 - 统计快照
+// @Param year query string true "年份 eg: 2022"
+// @Param month query string true "月份 eg: 3"
+// @Success 200 {object} vo.SnapshotResp "生成快照成功"
+// @Failure 400 {object} vo.Error "请求参数错误或月份不是本月或上月"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/snapshot [post]
+func (sh *SnapshotHandler) Take(ctx iris.Context) mvc.Result {
+	year, err := ctx.URLParamInt(constant.Year)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	month, err := ctx.URLParamInt(constant.Month)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	resp, ex := sh.Svc.Take(sh.UserName, year, month)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// List godoc
+// @Summary 统计快照列表
+// @Description 全部月末统计快照, 按期间倒序
+// @Tags 实施库 - 统计快照
+// @Success 200 {array} vo.SnapshotResp "查询快照成功"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/snapshots [get]
+func (sh *SnapshotHandler) List() mvc.Result {
+	resp, ex := sh.Svc.List()
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// Diff godoc
+// @Summary 统计快照对比
+// @Description 对比两个快照的汇总数变化、新增/移除项目及各项目关键字段变化, 非管理员仅对比本人创建的项目
+// @Tags 实施库 - 统计快照
+// @Param from query string true "对比基准快照ID"
+// @Param to query string true "对比目标快照ID"
+// @Success 200 {object} vo.SnapshotDiffResp "对比成功"
+// @Failure 400 {object} vo.Error "请求参数错误"
+// @Failure 401 {object} vo.Error "当前用户登录令牌失效"
+// @Failure 403 {object} vo.Error "当前操作无权限"
+// @Failure 500 {object} vo.Error "服务器内部错误"
+// @Security ApiKeyAuth
+// @Router /api/v1/implement/snapshot/diff [get]
+func (sh *SnapshotHandler) Diff(ctx iris.Context) mvc.Result {
+	from, err := ctx.URLParamInt64(constant.From)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	to, err := ctx.URLParamInt64(constant.To)
+	if err != nil {
+		return response.Error(exception.Wrap(response.ExceptionInvalidRequestParameters, err))
+	}
+	resp, ex := sh.Svc.Diff(sh.UserName, from, to)
+	if ex != nil {
+		return response.Error(ex)
+	}
+	return response.JSON(resp)
+}
+
+// BeforeActivation 初始化路由
+func (sh *SnapshotHandler) BeforeActivation(b mvc.BeforeActivation) {
+	b.Handle(iris.MethodPost, "/snapshot", "Take")
+	b.Handle(iris.MethodGet, "/snapshots", "List")
+	b.Handle(iris.MethodGet, "/snapshot/diff", "Diff")
+}
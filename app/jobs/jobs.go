@@ -0,0 +1,53 @@
+package jobs
+
+import (
+	"log"
+	"lpms/app/service"
+	"time"
+)
+
+// 定时任务执行间隔
+const interval = time.Hour
+
+// Start 启动服务内定时任务, 启动时先执行一次
+func Start() {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		progressReminder()
+		reportSnapshot()
+		objectReconcile()
+		<-ticker.C
+	}
+}
+
+// progressReminder 月度进度逾期提醒
+func progressReminder() {
+	if ex := service.GetProgressReminderService().Run(time.Now()); ex != nil {
+		log.Printf("progress reminder job failed, err is %s", ex.Error())
+	}
+}
+
+// reportSnapshot 月末统计快照
+func reportSnapshot() {
+	if ex := service.GetSnapshotService().Run(time.Now()); ex != nil {
+		log.Printf("report snapshot job failed, err is %s", ex.Error())
+	}
+}
+
+// objectReconcile 文件记录与存储对账
+func objectReconcile() {
+	report, ex := service.GetObjectReconcileService().Run(time.Now())
+	if ex != nil {
+		log.Printf("object reconcile job failed, err is %s", ex.Error())
+		return
+	}
+	if report == nil {
+		return
+	}
+	log.Printf("object reconcile job: %d unreferenced, %d missing content, %d stray, removed: %t",
+		len(report.Unreferenced), len(report.Missing), len(report.Stray), report.Removed)
+	if len(report.Missing) > 0 {
+		log.Printf("object reconcile job: objects missing content: %v", report.Missing)
+	}
+}
@@ -0,0 +1,27 @@
+package common
+
+import "lpms/app/models/tables"
+
+// ProjectAttachment 项目附件, 关联储备库、实施库政府投资及产业项目与文件
+type ProjectAttachment struct {
+	Base        `gorm:"embedded"`
+	ID          int64  `gorm:"column:id;primaryKey;unique;not null;comment:id"`
+	Source      string `gorm:"column:source;type:varchar(20);not null;uniqueIndex:uk_project_attachment,priority:1;comment:所属项目库 reserve:储备库,gov:实施库政府投资项目,industry:实施库产业项目"`
+	ProjectID   int64  `gorm:"column:project_id;type:bigint;not null;uniqueIndex:uk_project_attachment,priority:2;comment:项目ID"`
+	ObjectID    string `gorm:"column:object_id;type:varchar(40);not null;uniqueIndex:uk_project_attachment,priority:3;index;comment:文件ID"`
+	Category    int    `gorm:"column:category;type:integer;not null;default:0;comment:类别 0:其他,1:CAD图纸,2:现场照片,3:立项批复,4:可行性研究报告,5:用地预审意见,6:环境影响评价"`
+	Description string `gorm:"column:description;type:text;comment:附件说明"`
+	Sort        int    `gorm:"column:sort;type:integer;not null;default:0;comment:排序, 越小越靠前"`
+}
+
+func (ProjectAttachment) TableName() string {
+	return tables.ProjectAttachment
+}
+
+// ProjectAttachmentFile 项目附件及文件信息
+type ProjectAttachmentFile struct {
+	ProjectAttachment `gorm:"embedded"`
+	Filename          string `gorm:"column:filename"`
+	Size              int64  `gorm:"column:size"`
+	ContentType       string `gorm:"column:content_type"`
+}
@@ -10,6 +10,8 @@ type Object struct {
 	Path     string `gorm:"column:path;type:varchar(255);not null;comment:文件路径"`
 	Buff     []byte `gorm:"-"`
 	Size     int64  `gorm:"column:size;type:bigint;not null;comment:文件大小"`
+	// 上传时的文件类型(MIME)
+	ContentType string `gorm:"column:content_type;type:varchar(255);comment:文件类型"`
 }
 
 func (Object) TableName() string {
@@ -0,0 +1,57 @@
+package implement
+
+import (
+	"lpms/app/models/internal/common"
+	"lpms/app/models/tables"
+	"time"
+
+	"github.com/goccy/go-json"
+
+	"gorm.io/gorm"
+)
+
+type Contract struct {
+	common.Base  `gorm:"embedded"`
+	ID           int64           `gorm:"column:id;primaryKey;unique;not null;comment:id"`
+	ProjectID    int64           `gorm:"column:project_id;type:bigint;not null;index;comment:项目ID"`
+	ProgressID   *int64          `gorm:"column:progress_id;type:bigint;index;comment:来源月度进度ID"`
+	Year         int             `gorm:"column:year;type:integer;comment:填报年份"`
+	Month        int             `gorm:"column:month;type:integer;comment:填报月份"`
+	ContractNo   string          `gorm:"column:contract_no;type:varchar(100);not null;comment:合同编号"`
+	Name         string          `gorm:"column:name;type:varchar(200);comment:合同名称"`
+	Counterparty string          `gorm:"column:counterparty;type:varchar(200);not null;index;comment:合同相对方"`
+	Amount       *float64        `gorm:"column:amount;type:numeric;comment:合同金额(万)"`
+	SignDate     *time.Time      `gorm:"column:sign_date;type:timestamp;comment:签订日期"`
+	ContractType *int            `gorm:"column:contract_type;type:integer;comment:合同类型 0:施工,1:监理,2:设计,3:勘察,4:采购,5:其他"`
+	Attachments  json.RawMessage `gorm:"column:attachments;type:jsonb;comment:附件文件ID"`
+	Comment      string          `gorm:"column:comment;type:text;comment:备注"`
+}
+
+func (Contract) TableName() string {
+	return tables.Contract
+}
+
+func (b *Contract) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	b.CreateAt = now
+	b.UpdateAt = now
+	return nil
+}
+
+func (b *Contract) BeforeUpdate(tx *gorm.DB) error {
+	b.UpdateAt = time.Now()
+	return nil
+}
+
+type ContractTotal struct {
+	ProjectID int64   `gorm:"column:project_id"`
+	Count     int64   `gorm:"column:count"`
+	Amount    float64 `gorm:"column:amount"`
+}
+
+type ContractCounterpartyStat struct {
+	Counterparty string  `gorm:"column:counterparty"`
+	Count        int64   `gorm:"column:count"`
+	Amount       float64 `gorm:"column:amount"`
+	ProjectCount int64   `gorm:"column:project_count"`
+}
@@ -13,9 +13,10 @@ import (
 type GovProgress struct {
 	common.Base            `gorm:"embedded"`
 	ID                     int64           `gorm:"column:id;primaryKey;unique;not null;comment:id"`
-	ProjectID              int64           `gorm:"column:project_id;type:bigint;not null;comment:项目ID"`
+	ProjectID              int64           `gorm:"column:project_id;type:bigint;not null;index:idx_gov_progress_period,priority:1;comment:项目ID"`
 	Year                   int             `gorm:"column:year;type:integer;not null;comment:年份"`
 	Month                  int             `gorm:"column:month;type:integer;not null;comment:月份"`
+	Period                 int             `gorm:"column:period;type:integer;index:idx_gov_progress_period,priority:2;comment:期间 year*12+month"`
 	PlanInvest             *float64        `gorm:"column:plan_invest;type:numeric;comment:本月计划投资额(万)"`
 	PlanProgress           string          `gorm:"column:plan_progress;type:text;not null;comment:本月计划形象进度"`
 	PlanInvested           *float64        `gorm:"column:plan_invested;type:numeric;comment:本月完成投资额(万)"`
@@ -25,7 +26,7 @@ type GovProgress struct {
 	ActualProgress         string          `gorm:"column:actual_progress;type:text;comment:本月完成形象进度"`
 	ProblemDetail          json.RawMessage `gorm:"column:problem_detail;type:jsonb;comment:需协调问题详情"`
 	ChangeContent          json.RawMessage `gorm:"column:change_content;type:jsonb;comment:本月产生联系单变更"`
-	Contracts              json.RawMessage `gorm:"column:contracts;type:jsonb;comment:本月新增合同信息"`
+	Contracts              json.RawMessage `gorm:"column:contracts;type:jsonb;comment:本月新增合同信息(历史数据, 已迁入合同台账)"`
 	Status                 int             `gorm:"column:status;type:integer;default(0);comment:填报状态 0:未提交, 1:已提交"`
 	Comment                string          `gorm:"column:comment;type:text;comment:备注"`
 }
@@ -38,6 +39,7 @@ func (b *GovProgress) BeforeCreate(tx *gorm.DB) error {
 	now := time.Now()
 	b.CreateAt = now
 	b.UpdateAt = now
+	b.Period = Period(b.Year, b.Month)
 	return nil
 }
 
@@ -46,6 +48,24 @@ func (b *GovProgress) BeforeUpdate(tx *gorm.DB) error {
 	return nil
 }
 
+// Period 月份的连续编号, 跨年比较时使用
+func Period(year, month int) int {
+	return year*12 + month
+}
+
+// InvestmentSum 截至某月的累计投资
+type InvestmentSum struct {
+	ProjectID int64 `gorm:"column:project_id"`
+	// 当年累计完成投资额(一月至查询月份, 不含之后月份)
+	YearInvested float64 `gorm:"column:year_invested"`
+	// 当年累计固投(一月至查询月份)
+	YearFixedInvested float64 `gorm:"column:year_fixed_invested"`
+	// 开工至今累计投资额
+	StartInvested float64 `gorm:"column:start_invested"`
+	// 开工至今累计固投
+	StartFixedInvested float64 `gorm:"column:start_fixed_invested"`
+}
+
 type ListGovProgressPlan struct {
 	ID           int64    `gorm:"column:id"`
 	Year         int      `gorm:"column:year"`
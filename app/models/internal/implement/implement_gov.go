@@ -47,6 +47,9 @@ type ImplementGov struct {
 	Type                    int             `gorm:"column:type;type:integer;not null;comment:项目本质类型 1:政府项目,2:产业项目"`
 	ProjectCode             string          `gorm:"column:project_code;type:varchar(50);not null;comment:项目编码"`
 	DutyUint                string          `gorm:"column:duty_unit;type:varchar(500);comment:责任单位"`
+	Township                string          `gorm:"column:township;type:varchar(100);index;comment:所属街镇"`
+	Location                json.RawMessage `gorm:"column:location;type:jsonb;comment:项目位置(GeoJSON Point)"`
+	Boundary                json.RawMessage `gorm:"column:boundary;type:jsonb;comment:红线范围(GeoJSON Polygon)"`
 }
 
 func (ImplementGov) TableName() string {
@@ -64,3 +67,61 @@ func (b *ImplementGov) BeforeUpdate(tx *gorm.DB) error {
 	b.UpdateAt = time.Now()
 	return nil
 }
+
+// PortfolioStat 实施库项目按维度汇总的投资统计
+type PortfolioStat struct {
+	// 统计维度 all/type/project_type/level/point_type/duty_unit
+	Dimension string `gorm:"column:dimension"`
+	// 分组取值, 未填写时为空
+	GroupKey *string `gorm:"column:group_key"`
+	// 项目数
+	Count int64 `gorm:"column:count"`
+	// 总投资(万)
+	TotalInvestment float64 `gorm:"column:total_investment"`
+	// 当年截至统计月累计完成投资(万)
+	YearInvested float64 `gorm:"column:year_invested"`
+}
+
+// ImplementAnalysis 实施库按时间段统计
+type ImplementAnalysis struct {
+	Bucket string `gorm:"column:bucket"`
+	// 新开工项目数
+	Started int64 `gorm:"column:started"`
+	// 竣工项目数
+	Finished int64 `gorm:"column:finished"`
+	// 完成投资额(万)
+	Invested float64 `gorm:"column:invested"`
+}
+
+// MapProject 地图项目点位, 政府项目与产业项目合并
+type MapProject struct {
+	ID int64 `gorm:"column:id"`
+	// 来源 1:政府投资项目表,2:产业项目表
+	Source          int             `gorm:"column:source"`
+	Type            int             `gorm:"column:type"`
+	Name            string          `gorm:"column:name"`
+	Level           *int            `gorm:"column:level"`
+	ProjectType     *int            `gorm:"column:project_type"`
+	Status          int             `gorm:"column:status"`
+	Township        string          `gorm:"column:township"`
+	TotalInvestment *float64        `gorm:"column:total_investment"`
+	Location        json.RawMessage `gorm:"column:location"`
+	Boundary        json.RawMessage `gorm:"column:boundary"`
+	// 进度灯 1:红灯,2:绿灯; 产业项目无进度填报, 为空
+	Progress *int `gorm:"column:progress"`
+}
+
+// MapTownship 地图街镇汇总
+type MapTownship struct {
+	Township        string  `gorm:"column:township"`
+	Count           int64   `gorm:"column:count"`
+	TotalInvestment float64 `gorm:"column:total_investment"`
+	UnStart         int64   `gorm:"column:un_start"`
+	Started         int64   `gorm:"column:started"`
+	Finished        int64   `gorm:"column:finished"`
+	// 红灯项目数
+	Red int64 `gorm:"column:red"`
+	// 有位置项目的平均经纬度, 均无位置时为空
+	Lng *float64 `gorm:"column:lng"`
+	Lat *float64 `gorm:"column:lat"`
+}
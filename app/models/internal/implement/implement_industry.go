@@ -44,6 +44,8 @@ type ImpleIndustry struct {
 	Status                  int             `gorm:"column:status;type:integer;;not null;comment:项目状态 0:未开工,1:开工待审核,2:已开工;3:竣工待审核;4:已竣工"`
 	StartTime               *time.Time      `gorm:"column:start_time;type:timestamp;comment:开工时间"`
 	FinishTime              *time.Time      `gorm:"column:finish_time;type:timestamp;comment:竣工时间"`
+	Location                json.RawMessage `gorm:"column:location;type:jsonb;comment:项目位置(GeoJSON Point)"`
+	Boundary                json.RawMessage `gorm:"column:boundary;type:jsonb;comment:红线范围(GeoJSON Polygon)"`
 }
 
 func (ImpleIndustry) TableName() string {
@@ -0,0 +1,44 @@
+package implement
+
+import (
+	"lpms/app/models/internal/common"
+	"lpms/app/models/tables"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProgressPhoto 月度进度现场照片
+type ProgressPhoto struct {
+	common.Base `gorm:"embedded"`
+	ID          int64      `gorm:"column:id;primaryKey;unique;not null;comment:id"`
+	ProjectID   int64      `gorm:"column:project_id;type:bigint;not null;index:idx_progress_photo_period,priority:1;comment:项目ID"`
+	ProgressID  int64      `gorm:"column:progress_id;type:bigint;not null;index;comment:月度进度ID"`
+	Year        int        `gorm:"column:year;type:integer;not null;comment:填报年份"`
+	Month       int        `gorm:"column:month;type:integer;not null;comment:填报月份"`
+	Period      int        `gorm:"column:period;type:integer;index:idx_progress_photo_period,priority:2;comment:期间 year*12+month"`
+	ObjectID    string     `gorm:"column:object_id;type:varchar(40);not null;comment:原图文件ID"`
+	ThumbnailID string     `gorm:"column:thumbnail_id;type:varchar(40);comment:缩略图文件ID"`
+	Filename    string     `gorm:"column:filename;type:varchar(255);comment:文件名称"`
+	CaptureAt   *time.Time `gorm:"column:capture_at;type:timestamp;comment:拍摄时间(EXIF)"`
+	Latitude    *float64   `gorm:"column:latitude;type:numeric;comment:纬度(EXIF)"`
+	Longitude   *float64   `gorm:"column:longitude;type:numeric;comment:经度(EXIF)"`
+	Description string     `gorm:"column:description;type:text;comment:照片说明"`
+}
+
+func (ProgressPhoto) TableName() string {
+	return tables.ProgressPhoto
+}
+
+func (b *ProgressPhoto) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	b.CreateAt = now
+	b.UpdateAt = now
+	b.Period = Period(b.Year, b.Month)
+	return nil
+}
+
+func (b *ProgressPhoto) BeforeUpdate(tx *gorm.DB) error {
+	b.UpdateAt = time.Now()
+	return nil
+}
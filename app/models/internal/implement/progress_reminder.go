@@ -0,0 +1,53 @@
+package implement
+
+import (
+	"lpms/app/models/internal/common"
+	"lpms/app/models/tables"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProgressReminder 月度进度逾期未填报提醒
+type ProgressReminder struct {
+	common.Base `gorm:"embedded"`
+	ID          int64      `gorm:"column:id;primaryKey;unique;not null;comment:id"`
+	ProjectID   int64      `gorm:"column:project_id;type:bigint;not null;uniqueIndex:idx_progress_reminder_period,priority:1;comment:项目ID"`
+	Year        int        `gorm:"column:year;type:integer;not null;comment:填报年份"`
+	Month       int        `gorm:"column:month;type:integer;not null;comment:填报月份"`
+	Period      int        `gorm:"column:period;type:integer;not null;uniqueIndex:idx_progress_reminder_period,priority:2;comment:期间 year*12+month"`
+	ProjectName string     `gorm:"column:project_name;type:varchar(60);comment:项目名称"`
+	ProjectCode string     `gorm:"column:project_code;type:varchar(50);comment:项目编码"`
+	DutyUnit    string     `gorm:"column:duty_unit;type:varchar(500);index;comment:责任单位"`
+	Township    string     `gorm:"column:township;type:varchar(100);comment:所属街镇"`
+	Receiver    string     `gorm:"column:receiver;type:varchar(50);index;comment:提醒对象(项目填报人)"`
+	Status      int        `gorm:"column:status;type:integer;not null;default:0;comment:状态 0:未补报,1:已补报"`
+	ResolvedAt  *time.Time `gorm:"column:resolved_at;type:timestamp;comment:补报时间"`
+}
+
+func (ProgressReminder) TableName() string {
+	return tables.ProgressReminder
+}
+
+func (b *ProgressReminder) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	b.CreateAt = now
+	b.UpdateAt = now
+	b.Period = Period(b.Year, b.Month)
+	return nil
+}
+
+func (b *ProgressReminder) BeforeUpdate(tx *gorm.DB) error {
+	b.UpdateAt = time.Now()
+	return nil
+}
+
+// ComplianceStat 月度进度填报率统计
+type ComplianceStat struct {
+	// 分组名称(责任单位/街镇)
+	Name string `gorm:"column:name"`
+	// 应填报项目数
+	Total int64 `gorm:"column:total"`
+	// 已提交项目数
+	Submitted int64 `gorm:"column:submitted"`
+}
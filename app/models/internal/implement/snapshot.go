@@ -0,0 +1,79 @@
+package implement
+
+import (
+	"lpms/app/models/internal/common"
+	"lpms/app/models/tables"
+	"time"
+
+	"github.com/goccy/go-json"
+	"gorm.io/gorm"
+)
+
+// ReportSnapshot 月末统计快照, 生成后不再修改
+type ReportSnapshot struct {
+	common.Base `gorm:"embedded"`
+	ID          int64 `gorm:"column:id;primaryKey;unique;not null;comment:id"`
+	Year        int   `gorm:"column:year;type:integer;not null;comment:年份"`
+	Month       int   `gorm:"column:month;type:integer;not null;comment:月份"`
+	Period      int   `gorm:"column:period;type:integer;not null;uniqueIndex;comment:期间 year*12+month"`
+	// 以下为汇总数, 口径与投资统计一致(未开工/已开工/已竣工项目)
+	ProjectCount    int64   `gorm:"column:project_count;type:bigint;comment:项目数"`
+	TotalInvestment float64 `gorm:"column:total_investment;type:numeric;comment:总投资(万)"`
+	YearInvested    float64 `gorm:"column:year_invested;type:numeric;comment:当年累计完成投资(万)"`
+}
+
+func (ReportSnapshot) TableName() string {
+	return tables.ReportSnapshot
+}
+
+func (b *ReportSnapshot) BeforeCreate(tx *gorm.DB) error {
+	now := time.Now()
+	b.CreateAt = now
+	b.UpdateAt = now
+	b.Period = Period(b.Year, b.Month)
+	return nil
+}
+
+// SnapshotProject 快照中的项目数据, 字段与实施库项目、进度统计同名以复用统计查询
+type SnapshotProject struct {
+	ID               int64      `gorm:"column:id;primaryKey;unique;not null;comment:id"`
+	SnapshotID       int64      `gorm:"column:snapshot_id;type:bigint;not null;index;comment:快照ID"`
+	Source           int        `gorm:"column:source;type:integer;not null;comment:来源 1:政府投资项目表,2:产业项目表"`
+	ProjectID        int64      `gorm:"column:project_id;type:bigint;not null;comment:项目ID"`
+	Type             int        `gorm:"column:type;type:integer;comment:项目本质类型 1:政府项目,2:产业项目"`
+	Name             string     `gorm:"column:name;type:varchar(60);comment:项目名称"`
+	ProjectCode      string     `gorm:"column:project_code;type:varchar(50);comment:项目编码"`
+	ConstructSubject string     `gorm:"column:construct_subject;type:varchar(60);comment:建设主体"`
+	Level            *int       `gorm:"column:level;type:integer;comment:项目级别"`
+	ProjectType      *int       `gorm:"column:project_type;type:integer;comment:项目类型"`
+	PointType        *int       `gorm:"column:point_type;type:integer;comment:重点类型"`
+	DutyUnit         string     `gorm:"column:duty_unit;type:varchar(500);comment:责任单位"`
+	Township         string     `gorm:"column:township;type:varchar(100);comment:所属街镇"`
+	Status           int        `gorm:"column:status;type:integer;comment:项目状态"`
+	PlanBegin        *time.Time `gorm:"column:plan_begin;type:timestamp;comment:计划开工时间"`
+	StartTime        *time.Time `gorm:"column:start_time;type:timestamp;comment:开工时间"`
+	FinishTime       *time.Time `gorm:"column:finish_time;type:timestamp;comment:竣工时间"`
+	ProjectCreateAt  time.Time  `gorm:"column:project_create_at;type:timestamp;comment:项目创建时间"`
+	CreateBy         string     `gorm:"column:create_by;type:varchar(40);comment:项目创建者ID"`
+	TotalInvestment  *float64   `gorm:"column:total_investment;type:numeric;comment:总投资(万)"`
+	YearPlan         float64    `gorm:"column:year_plan;type:numeric;comment:当年计划投资(万)"`
+	YearInvested     float64    `gorm:"column:year_invested;type:numeric;comment:当年累计完成投资(万)"`
+	StartInvested    float64    `gorm:"column:start_invested;type:numeric;comment:开工至今累计完成投资(万)"`
+	// 以下为快照月份的进度填报, 未填报时 ProgressStatus 为空
+	ProgressStatus *int            `gorm:"column:progress_status;type:integer;comment:填报状态 0:未提交,1:已提交"`
+	MonthPlan      *float64        `gorm:"column:month_plan;type:numeric;comment:本月计划投资(万)"`
+	MonthInvested  *float64        `gorm:"column:month_invested;type:numeric;comment:本月完成投资(万)"`
+	PlanProgress   string          `gorm:"column:plan_progress;type:text;comment:本月计划形象进度"`
+	ActualProgress string          `gorm:"column:actual_progress;type:text;comment:本月完成形象进度"`
+	ProblemDetail  json.RawMessage `gorm:"column:problem_detail;type:jsonb;comment:需协调问题详情"`
+}
+
+func (SnapshotProject) TableName() string {
+	return tables.SnapshotProject
+}
+
+// 快照项目来源表
+const (
+	SnapshotSourceGov      = 1
+	SnapshotSourceIndustry = 2
+)
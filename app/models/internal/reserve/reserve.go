@@ -46,6 +46,8 @@ type ReservePro struct {
 	Status                  int             `gorm:"column:status;type:integer;comment:项目状态 0:草稿,1:已入库,2:前期计划;3:已发文"`
 	IsCaseFinish            *bool           `gorm:"column:is_case_finish;type:boolean;comment:方案是否完成"`
 	IsResearch              *int            `gorm:"column:is_research;type:integer;comment:是否可研编制; 0:编制中 1:已完成"`
+	Location                json.RawMessage `gorm:"column:location;type:jsonb;comment:项目位置(GeoJSON Point)"`
+	Boundary                json.RawMessage `gorm:"column:boundary;type:jsonb;comment:红线范围(GeoJSON Polygon)"`
 }
 
 type InvestDetail struct {
@@ -109,6 +111,8 @@ func (r *ReservePro) ToGovReserveModel(openID string) *implement.ImplementGov {
 		Contract:                r.Contract,
 		Phone:                   r.Phone,
 		Status:                  constant.UnStart,
+		Location:                r.Location,
+		Boundary:                r.Boundary,
 		Base: common.Base{
 			UpdateBy: openID,
 			CreateBy: openID,
@@ -0,0 +1,25 @@
+package user
+
+import (
+	"lpms/app/models/internal/common"
+	"lpms/app/models/tables"
+
+	"github.com/goccy/go-json"
+)
+
+// SavedView 列表保存视图(筛选条件及排序), 创建者可共享给本单位用户
+type SavedView struct {
+	common.Base `gorm:"embedded"`
+	ID          int64           `gorm:"column:id;primaryKey;unique;not null;comment:id"`
+	ListType    string          `gorm:"column:list_type;type:varchar(30);not null;index;comment:列表类型"`
+	Name        string          `gorm:"column:name;type:varchar(60);not null;comment:视图名称"`
+	Filter      json.RawMessage `gorm:"column:filter;type:jsonb;comment:筛选条件, 与列表接口请求体一致"`
+	Sort        string          `gorm:"column:sort;type:varchar(200);comment:排序, 与列表接口 sort 参数一致"`
+	Shared      bool            `gorm:"column:shared;type:boolean;not null;default:false;comment:是否共享给本单位"`
+	Unit        string          `gorm:"column:unit;type:varchar(100);index;comment:创建者所属单位"`
+	IsDefault   bool            `gorm:"column:is_default;type:boolean;not null;default:false;comment:是否为创建者该列表的默认视图"`
+}
+
+func (SavedView) TableName() string {
+	return tables.SavedView
+}
@@ -12,6 +12,7 @@ type User struct {
 	ID          int64  `gorm:"column:id;primaryKey;unique;not null;comment:id"`
 	IsAdmin     bool   `gorm:"column:is_admin;type:boolean;not null;comment:是否是超管"`
 	Status      bool   `gorm:"column:status;type:boolean;comment:状态"`
+	Unit        string `gorm:"column:unit;type:varchar(100);comment:所属单位"`
 }
 
 func (User) TableName() string {
@@ -11,17 +11,44 @@ import (
 type (
 	Base           = common.Base
 	User           = user.User
+	SavedView      = user.SavedView
 	ReservePro     = reserve.ReservePro
 	InvestDetail   = reserve.InvestDetail
 	ListReservePro = reserve.ListReservePro
 
-	Object = common.Object
+	Object                = common.Object
+	ProjectAttachment     = common.ProjectAttachment
+	ProjectAttachmentFile = common.ProjectAttachmentFile
 
-	ImplementGov        = implement.ImplementGov
-	ImpleIndustry       = implement.ImpleIndustry
-	GovProgress         = implement.GovProgress
-	ListGovProgressPlan = implement.ListGovProgressPlan
-	GovProgressCompare  = implement.GovProgressCompare
-	WindowSetting       = inspect.WindowSetting
-	ReserveAnalysis     = reserve.ReserveAnalysis
+	ImplementGov             = implement.ImplementGov
+	ImpleIndustry            = implement.ImpleIndustry
+	GovProgress              = implement.GovProgress
+	ListGovProgressPlan      = implement.ListGovProgressPlan
+	GovProgressCompare       = implement.GovProgressCompare
+	InvestmentSum            = implement.InvestmentSum
+	Contract                 = implement.Contract
+	ContractTotal            = implement.ContractTotal
+	ContractCounterpartyStat = implement.ContractCounterpartyStat
+	ProgressPhoto            = implement.ProgressPhoto
+	ProgressReminder         = implement.ProgressReminder
+	ComplianceStat           = implement.ComplianceStat
+	PortfolioStat            = implement.PortfolioStat
+	ImplementAnalysis        = implement.ImplementAnalysis
+	MapProject               = implement.MapProject
+	MapTownship              = implement.MapTownship
+	ReportSnapshot           = implement.ReportSnapshot
+	SnapshotProject          = implement.SnapshotProject
+	WindowSetting            = inspect.WindowSetting
+	ReserveAnalysis          = reserve.ReserveAnalysis
+)
+
+// Period 月份的连续编号 year*12+month
+func Period(year, month int) int {
+	return implement.Period(year, month)
+}
+
+// 快照项目来源表
+const (
+	SnapshotSourceGov      = implement.SnapshotSourceGov
+	SnapshotSourceIndustry = implement.SnapshotSourceIndustry
 )
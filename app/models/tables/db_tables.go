@@ -17,4 +17,34 @@ const (
 	GovProgress = "lpms_gov_progress"
 	// 窗口期设置
 	Window = "lpms_window_setting"
+	// 实施库-合同台账
+	Contract = "lpms_contract"
+	// 实施库-进度现场照片
+	ProgressPhoto = "lpms_progress_photo"
+	// 实施库-进度逾期提醒
+	ProgressReminder = "lpms_progress_reminder"
+	// 实施库-月末统计快照
+	ReportSnapshot = "lpms_report_snapshot"
+	// 实施库-月末统计快照项目数据
+	SnapshotProject = "lpms_snapshot_project"
+	// 列表保存视图
+	SavedView = "lpms_saved_view"
+	// 项目附件
+	ProjectAttachment = "lpms_project_attachment"
+)
+
+// 关键词检索文本表达式, 迁移中按相同表达式建立 pg_trgm 索引, 修改时需同步调整索引
+const (
+	// 储备库: 项目名称、建设地点、建设内容及规模
+	ReserveSearchDoc = `coalesce(name, '') || ' ' || coalesce(construct_site, '') || ' ' || coalesce(construct_content_scope, '')`
+	// 实施库-政府项目: 项目名称、建设地点、建设内容及规模、责任单位
+	ImplementGovSearchDoc = `coalesce(name, '') || ' ' || coalesce(construct_site, '') || ' ' || coalesce(construct_content_scope, '') || ' ' || coalesce(duty_unit, '')`
+	// 实施库-产业项目: 项目名称、建设地点、建设内容及规模
+	ImplementIndustrySearchDoc = `coalesce(name, '') || ' ' || coalesce(construct_site, '') || ' ' || coalesce(construct_content_scope, '')`
+)
+
+// 项目位置(GeoJSON Point)经纬度表达式, 迁移中按相同表达式建立索引, 修改时需同步调整索引
+const (
+	LocationLng = `((location->'coordinates'->>0)::float8)`
+	LocationLat = `((location->'coordinates'->>1)::float8)`
 )
@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"fmt"
+	"lpms/app/models"
+	"lpms/app/models/tables"
+	"lpms/app/response"
+	"lpms/constant"
+	"lpms/exception"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// attachmentProjectTables 附件所属项目库 -> 项目表
+var attachmentProjectTables = map[string]string{
+	constant.AttachReserve:  tables.Reserve,
+	constant.AttachGov:      tables.ImplementGov,
+	constant.AttachIndustry: tables.ImplementIndustry,
+}
+
+var (
+	attachmentRepoInstance AttachmentRepo
+	attachmentOnce         sync.Once
+)
+
+type AttachmentRepoImpl struct{}
+
+func GetAttachmentRepo() AttachmentRepo {
+	attachmentOnce.Do(func() {
+		attachmentRepoInstance = &AttachmentRepoImpl{}
+	})
+	return attachmentRepoInstance
+}
+
+type AttachmentRepo interface {
+	ProjectOwner(db *gorm.DB, source string, projectID int64) (string, exception.Exception)
+	List(db *gorm.DB, source string, projectID int64, category *int) ([]models.ProjectAttachmentFile, exception.Exception)
+	Get(db *gorm.DB, id int64) (*models.ProjectAttachment, exception.Exception)
+	MaxSort(db *gorm.DB, source string, projectID int64) (int, exception.Exception)
+	Create(db *gorm.DB, attachments []models.ProjectAttachment) exception.Exception
+	UpdateSort(db *gorm.DB, id int64, sort int, user string) exception.Exception
+	Delete(db *gorm.DB, id int64) exception.Exception
+	DeleteByProject(db *gorm.DB, source string, projectID ...int64) exception.Exception
+	AddLegacy(db *gorm.DB, source string, projectID int64, objectID string, category int, user string) exception.Exception
+	DeleteLegacy(db *gorm.DB, source string, projectID int64, objectID string, category int) exception.Exception
+	Copy(db *gorm.DB, fromSource string, fromID int64, toSource string, toID int64) exception.Exception
+}
+
+// ProjectOwner 项目创建者, 项目不存在时返回记录不存在
+func (ari *AttachmentRepoImpl) ProjectOwner(db *gorm.DB, source string, projectID int64) (string, exception.Exception) {
+	table, ok := attachmentProjectTables[source]
+	if !ok {
+		return "", exception.New(response.ExceptionInvalidRequestParameters, "invalid source")
+	}
+	owners := make([]string, 0, 1)
+	if err := db.Table(table).Where("id = ?", projectID).Limit(1).Pluck("create_by", &owners).Error; err != nil {
+		return "", exception.Wrap(response.ExceptionDatabase, err)
+	}
+	if len(owners) == 0 {
+		return "", exception.New(response.ExceptionRecordNotFound, "recode not found")
+	}
+	return owners[0], nil
+}
+
+// List 项目附件, 按排序及上传先后排列; category 不为空时只返回该类别
+func (ari *AttachmentRepoImpl) List(db *gorm.DB, source string, projectID int64, category *int) (
+	[]models.ProjectAttachmentFile, exception.Exception) {
+	res := make([]models.ProjectAttachmentFile, 0)
+	tx := db.Table(tables.ProjectAttachment+" AS a").
+		Select("a.*, o.filename, o.size, o.content_type").
+		Joins(fmt.Sprintf("JOIN %s o ON o.id = a.object_id", tables.Object)).
+		Where("a.source = ? AND a.project_id = ?", source, projectID)
+	if category != nil {
+		tx = tx.Where("a.category = ?", *category)
+	}
+	err := tx.Order("a.sort ASC").Order("a.id ASC").Scan(&res).Error
+	return res, exception.Wrap(response.ExceptionDatabase, err)
+}
+
+func (ari *AttachmentRepoImpl) Get(db *gorm.DB, id int64) (*models.ProjectAttachment, exception.Exception) {
+	attachment := models.ProjectAttachment{}
+	res := db.Where(&models.ProjectAttachment{ID: id}).Find(&attachment)
+	if res.RowsAffected == 0 {
+		return nil, exception.New(response.ExceptionRecordNotFound, "recode not found")
+	}
+	if res.Error != nil {
+		return nil, exception.Wrap(response.ExceptionDatabase, res.Error)
+	}
+	return &attachment, nil
+}
+
+// MaxSort 项目附件的最大排序值, 无附件时为 -1
+func (ari *AttachmentRepoImpl) MaxSort(db *gorm.DB, source string, projectID int64) (int, exception.Exception) {
+	sort := -1
+	err := db.Model(&models.ProjectAttachment{}).Select("coalesce(max(sort), -1)").
+		Where("source = ? AND project_id = ?", source, projectID).Scan(&sort).Error
+	return sort, exception.Wrap(response.ExceptionDatabase, err)
+}
+
+func (ari *AttachmentRepoImpl) Create(db *gorm.DB, attachments []models.ProjectAttachment) exception.Exception {
+	if len(attachments) == 0 {
+		return nil
+	}
+	return exception.Wrap(response.ExceptionDatabase, db.Create(&attachments).Error)
+}
+
+func (ari *AttachmentRepoImpl) UpdateSort(db *gorm.DB, id int64, sort int, user string) exception.Exception {
+	return exception.Wrap(response.ExceptionDatabase, db.Model(&models.ProjectAttachment{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"sort": sort, "update_by": user}).Error)
+}
+
+// Delete 仅删除附件关联, 不删除文件
+func (ari *AttachmentRepoImpl) Delete(db *gorm.DB, id int64) exception.Exception {
+	return exception.Wrap(response.ExceptionDatabase, db.Where("id = ?", id).Delete(&models.ProjectAttachment{}).Error)
+}
+
+// DeleteByProject 删除项目的全部附件关联, 不删除文件
+func (ari *AttachmentRepoImpl) DeleteByProject(db *gorm.DB, source string, projectID ...int64) exception.Exception {
+	if len(projectID) == 0 {
+		return nil
+	}
+	return exception.Wrap(response.ExceptionDatabase, db.Where("source = ? AND project_id IN ?", source, projectID).
+		Delete(&models.ProjectAttachment{}).Error)
+}
+
+// AddLegacy 将兼容字段(upload_cad_id、site_photo)引用的文件添加为附件, 排在已有附件之后; 已是项目附件时不变
+func (ari *AttachmentRepoImpl) AddLegacy(db *gorm.DB, source string, projectID int64, objectID string, category int,
+	user string) exception.Exception {
+	sqlStr := fmt.Sprintf(`INSERT INTO %s (create_at, update_at, create_by, update_by, source, project_id,
+object_id, category, description, sort)
+SELECT now(), now(), ?, ?, ?, ?, ?, ?, '', coalesce(max(sort), -1) + 1
+FROM %s WHERE source = ? AND project_id = ?
+ON CONFLICT DO NOTHING`, tables.ProjectAttachment, tables.ProjectAttachment)
+	return exception.Wrap(response.ExceptionDatabase, db.Exec(sqlStr, user, user, source, projectID, objectID, category,
+		source, projectID).Error)
+}
+
+// DeleteLegacy 删除兼容字段原引用文件对应的附件关联, 不删除文件
+func (ari *AttachmentRepoImpl) DeleteLegacy(db *gorm.DB, source string, projectID int64, objectID string,
+	category int) exception.Exception {
+	return exception.Wrap(response.ExceptionDatabase, db.Where(
+		"source = ? AND project_id = ? AND object_id = ? AND category = ?", source, projectID, objectID, category).
+		Delete(&models.ProjectAttachment{}).Error)
+}
+
+// Copy 复制项目附件到另一项目, 用于储备库项目出库进入实施库
+func (ari *AttachmentRepoImpl) Copy(db *gorm.DB, fromSource string, fromID int64, toSource string, toID int64) exception.Exception {
+	sqlStr := fmt.Sprintf(`INSERT INTO %s (create_at, update_at, create_by, update_by, source, project_id,
+object_id, category, description, sort)
+SELECT create_at, now(), create_by, update_by, ?, ?, object_id, category, description, sort
+FROM %s WHERE source = ? AND project_id = ?
+ON CONFLICT DO NOTHING`, tables.ProjectAttachment, tables.ProjectAttachment)
+	return exception.Wrap(response.ExceptionDatabase, db.Exec(sqlStr, toSource, toID, fromSource, fromID).Error)
+}
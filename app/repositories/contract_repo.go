@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"lpms/app/models"
+	"lpms/app/models/tables"
+	"lpms/app/response"
+	"lpms/app/vo"
+	"lpms/exception"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+var (
+	contractRepoInstance ContractRepo
+	contractOnce         sync.Once
+)
+
+type ContractRepoImpl struct{}
+
+func GetContractRepo() ContractRepo {
+	contractOnce.Do(func() {
+		contractRepoInstance = &ContractRepoImpl{}
+	})
+	return contractRepoInstance
+}
+
+type ContractRepo interface {
+	Create(db *gorm.DB, contracts []models.Contract) exception.Exception
+	Get(db *gorm.DB, id int64) (*models.Contract, exception.Exception)
+	List(db *gorm.DB, pageInfo *vo.PageInfo, params *vo.ContractFilterParam) (int64, []models.Contract, exception.Exception)
+	ListByProgressID(db *gorm.DB, progressID int64) ([]models.Contract, exception.Exception)
+	Update(db *gorm.DB, id int64, param map[string]interface{}) exception.Exception
+	Delete(db *gorm.DB, id int64) exception.Exception
+	DeleteByProgressID(db *gorm.DB, progressID int64) exception.Exception
+	DeleteByProjectID(db *gorm.DB, projectID ...int64) exception.Exception
+	ProjectTotal(db *gorm.DB, projectID ...int64) ([]models.ContractTotal, exception.Exception)
+	CounterpartyStat(db *gorm.DB, params *vo.ContractFilterParam) ([]models.ContractCounterpartyStat, exception.Exception)
+}
+
+func (cri *ContractRepoImpl) Create(db *gorm.DB, contracts []models.Contract) exception.Exception {
+	if len(contracts) == 0 {
+		return nil
+	}
+	return exception.Wrap(response.ExceptionDatabase, db.Create(&contracts).Error)
+}
+
+func (cri *ContractRepoImpl) Get(db *gorm.DB, id int64) (*models.Contract, exception.Exception) {
+	contract := models.Contract{}
+	res := db.Where(&models.Contract{ID: id}).Find(&contract)
+	if res.RowsAffected == 0 {
+		return nil, exception.New(response.ExceptionRecordNotFound, "recode not found")
+	}
+	if res.Error != nil {
+		return nil, exception.Wrap(response.ExceptionDatabase, res.Error)
+	}
+	return &contract, nil
+}
+
+func contractFilter(tx *gorm.DB, params *vo.ContractFilterParam) *gorm.DB {
+	if params.ProjectID != nil {
+		tx = tx.Where("project_id = ?", params.ProjectID)
+	}
+	if params.ContractNo != "" {
+		tx = tx.Where("contract_no = ?", params.ContractNo)
+	}
+	if params.Counterparty != "" {
+		tx = tx.Where("counterparty = ?", params.Counterparty)
+	}
+	if params.ContractType != nil {
+		tx = tx.Where("contract_type = ?", params.ContractType)
+	}
+	if params.SignBegin != "" && params.SignEnd != "" {
+		tx = tx.Where("sign_date < ? and sign_date >= ?", params.SignEnd, params.SignBegin)
+	}
+	return tx
+}
+
+func (cri *ContractRepoImpl) List(db *gorm.DB, pageInfo *vo.PageInfo, params *vo.ContractFilterParam) (int64, []models.Contract,
+	exception.Exception) {
+	data := make([]models.Contract, 0)
+	tx := contractFilter(db.Table(tables.Contract), params)
+	count := int64(0)
+	tx = tx.Limit(pageInfo.PageSize).Offset(pageInfo.Offset()).Order("sign_date DESC NULLS LAST").Order("id DESC").
+		Scan(&data).Limit(-1).Offset(-1).Count(&count)
+	return count, data, exception.Wrap(response.ExceptionDatabase, tx.Error)
+}
+
+func (cri *ContractRepoImpl) ListByProgressID(db *gorm.DB, progressID int64) ([]models.Contract, exception.Exception) {
+	data := make([]models.Contract, 0)
+	tx := db.Table(tables.Contract).Where("progress_id = ?", progressID).Order("id").Find(&data)
+	return data, exception.Wrap(response.ExceptionDatabase, tx.Error)
+}
+
+func (cri *ContractRepoImpl) Update(db *gorm.DB, id int64, param map[string]interface{}) exception.Exception {
+	return exception.Wrap(response.ExceptionDatabase,
+		db.Model(&models.Contract{}).Where(&models.Contract{ID: id}).Updates(param).Error)
+}
+
+func (cri *ContractRepoImpl) Delete(db *gorm.DB, id int64) exception.Exception {
+	return exception.Wrap(response.ExceptionDatabase, db.Delete(&models.Contract{}, id).Error)
+}
+
+func (cri *ContractRepoImpl) DeleteByProgressID(db *gorm.DB, progressID int64) exception.Exception {
+	return exception.Wrap(response.ExceptionDatabase, db.Where("progress_id = ?", progressID).Delete(&models.Contract{}).Error)
+}
+
+func (cri *ContractRepoImpl) DeleteByProjectID(db *gorm.DB, projectID ...int64) exception.Exception {
+	return exception.Wrap(response.ExceptionDatabase, db.Where("project_id in (?)", projectID).Delete(&models.Contract{}).Error)
+}
+
+// 项目合同数量及合同总额
+func (cri *ContractRepoImpl) ProjectTotal(db *gorm.DB, projectID ...int64) ([]models.ContractTotal, exception.Exception) {
+	res := make([]models.ContractTotal, 0, len(projectID))
+	tx := db.Table(tables.Contract).Select("project_id, count(*) AS count, coalesce(sum(amount), 0) AS amount").
+		Where("project_id in (?)", projectID).Group("project_id").Find(&res)
+	return res, exception.Wrap(response.ExceptionDatabase, tx.Error)
+}
+
+// 按合同相对方统计合同数量及金额
+func (cri *ContractRepoImpl) CounterpartyStat(db *gorm.DB, params *vo.ContractFilterParam) ([]models.ContractCounterpartyStat,
+	exception.Exception) {
+	res := make([]models.ContractCounterpartyStat, 0)
+	tx := contractFilter(db.Table(tables.Contract), params).
+		Select("counterparty, count(*) AS count, coalesce(sum(amount), 0) AS amount, count(distinct project_id) AS project_count").
+		Group("counterparty").Order("count DESC").Order("amount DESC").Find(&res)
+	return res, exception.Wrap(response.ExceptionDatabase, tx.Error)
+}
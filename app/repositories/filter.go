@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"fmt"
+	"lpms/app/response"
+	"lpms/app/vo"
+	"lpms/exception"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	emptyFilterType = reflect.TypeOf(vo.EmptyFilter{})
+	// filterOps 筛选方式 -> 条件
+	filterOps = map[string]string{
+		"eq":  " = ?",
+		"in":  " IN ?",
+		"gte": " >= ?",
+		"lte": " <= ?",
+		"lt":  " < ?",
+	}
+)
+
+// filterColumn 可按未填写筛选的列
+type filterColumn struct {
+	name     string
+	isString bool
+}
+
+// rangeFilter 同一列的区间端点, 仅端点全部传入时筛选
+type rangeFilter struct {
+	column string
+	conds  []string
+	args   []interface{}
+	// 已传入的端点数
+	set int
+}
+
+// filterState 遍历筛选参数时收集的可按未填写筛选的列、未填写筛选字段及区间
+type filterState struct {
+	columns map[string]filterColumn
+	empty   []string
+	ranges  []*rangeFilter
+}
+
+func (s *filterState) rangeOf(column string) *rangeFilter {
+	for _, r := range s.ranges {
+		if r.column == column {
+			return r
+		}
+	}
+	r := &rangeFilter{column: column}
+	s.ranges = append(s.ranges, r)
+	return r
+}
+
+// applyFilter 按筛选参数结构体(含嵌入结构体)的 filter 标签生成查询条件, 标签格式见 vo 包说明;
+// alias 不为空时列名加表别名前缀. 同一列的区间端点须全部传入才筛选. 未填写筛选仅允许结构体中声明为 eq/in 的列
+func applyFilter(tx *gorm.DB, alias string, params interface{}) (*gorm.DB, exception.Exception) {
+	state := &filterState{columns: make(map[string]filterColumn)}
+	tx, err := applyFilterFields(tx, alias, reflect.Indirect(reflect.ValueOf(params)), state)
+	if err != nil {
+		return nil, exception.Wrap(response.ExceptionInvalidRequestParameters, err)
+	}
+	for _, r := range state.ranges {
+		if r.set != len(r.conds) {
+			continue
+		}
+		for i := range r.conds {
+			tx = tx.Where(r.column+r.conds[i], r.args[i])
+		}
+	}
+	for _, field := range state.empty {
+		column, ok := state.columns[field]
+		if !ok {
+			return nil, exception.New(response.ExceptionInvalidRequestParameters,
+				fmt.Sprintf("unsupported empty filter field %q", field))
+		}
+		if column.isString {
+			tx = tx.Where(fmt.Sprintf("(%s IS NULL OR %s = '')", column.name, column.name))
+		} else {
+			tx = tx.Where(column.name + " IS NULL")
+		}
+	}
+	return tx, nil
+}
+
+func applyFilterFields(tx *gorm.DB, alias string, v reflect.Value, state *filterState) (*gorm.DB, error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		if field.Type == emptyFilterType {
+			state.empty = append(state.empty, value.Interface().(vo.EmptyFilter).Empty...)
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			var err error
+			if tx, err = applyFilterFields(tx, alias, value, state); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		tag := field.Tag.Get("filter")
+		if tag == "" {
+			continue
+		}
+		name, op := tag, "eq"
+		if i := strings.Index(tag, ","); i >= 0 {
+			name, op = tag[:i], tag[i+1:]
+		}
+		cond, ok := filterOps[op]
+		if !ok {
+			return nil, fmt.Errorf("unsupported filter op %q of field %s", op, field.Name)
+		}
+		column := name
+		if alias != "" {
+			column = alias + "." + name
+		}
+		arg, set := filterValue(value)
+		if op != "eq" && op != "in" {
+			r := state.rangeOf(column)
+			r.conds = append(r.conds, cond)
+			r.args = append(r.args, arg)
+			if set {
+				r.set++
+			}
+			continue
+		}
+		kind := field.Type.Kind()
+		if kind == reflect.Ptr || kind == reflect.Slice {
+			kind = field.Type.Elem().Kind()
+		}
+		state.columns[name] = filterColumn{name: column, isString: kind == reflect.String}
+		if set {
+			tx = tx.Where(column+cond, arg)
+		}
+	}
+	return tx, nil
+}
+
+// filterValue 取筛选值, 未传参数返回 false
+func filterValue(v reflect.Value) (interface{}, bool) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil, false
+		}
+		return v.Elem().Interface(), true
+	case reflect.Slice, reflect.String:
+		if v.Len() == 0 {
+			return nil, false
+		}
+	}
+	return v.Interface(), true
+}
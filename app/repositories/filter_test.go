@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"lpms/app/response"
+	"lpms/app/vo"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+type testFilterBase struct {
+	Level  *int  `filter:"level"`
+	Levels []int `filter:"level,in"`
+}
+
+type testFilter struct {
+	vo.EmptyFilter
+	testFilterBase
+	Name     string   `filter:"name"`
+	Units    []string `filter:"duty_unit,in"`
+	Begin    string   `filter:"create_at,gte"`
+	End      string   `filter:"create_at,lte"`
+	Before   string   `filter:"finish_time,lt"`
+	Invest   *float64 `filter:"total_investment,gte"`
+	Untagged string
+}
+
+type testBadFilter struct {
+	Name string `filter:"name,like"`
+}
+
+func intPtr(v int) *int { return &v }
+
+func float64Ptr(v float64) *float64 { return &v }
+
+// filterSQL 按筛选参数生成的查询条件
+func filterSQL(t *testing.T, alias string, params interface{}) (string, error) {
+	t.Helper()
+	db, rec := dryRunDB(t)
+	tx, ex := applyFilter(db.Table("t"), alias, params)
+	if ex != nil {
+		return "", ex
+	}
+	tx.Find(&[]map[string]interface{}{})
+	sql := rec.lastSQL(t)
+	if i := strings.Index(sql, " WHERE "); i >= 0 {
+		return sql[i+len(" WHERE "):], nil
+	}
+	return "", nil
+}
+
+func TestApplyFilter(t *testing.T) {
+	cases := []struct {
+		name   string
+		alias  string
+		params interface{}
+		want   string
+	}{
+		{"no params", "", &testFilter{}, ""},
+		{"eq string", "", &testFilter{Name: "a"}, "name = 'a'"},
+		{"eq pointer zero value", "", &testFilter{testFilterBase: testFilterBase{Level: intPtr(0)}}, "level = 0"},
+		{"in from embedded struct", "", &testFilter{testFilterBase: testFilterBase{Levels: []int{1, 2}}}, "level IN (1,2)"},
+		{"in strings", "", &testFilter{Units: []string{"x", "y"}}, "duty_unit IN ('x','y')"},
+		{"empty slice ignored", "", &testFilter{Units: []string{}}, ""},
+		{"gte and lte", "", &testFilter{Begin: "2024-01-01", End: "2024-12-31"},
+			"create_at >= '2024-01-01' AND create_at <= '2024-12-31'"},
+		// 同一列的区间端点须全部传入
+		{"single bound of range ignored", "", &testFilter{End: "2024-12-31"}, ""},
+		{"single bound with eq", "", &testFilter{Name: "a", Begin: "2024-01-01"}, "name = 'a'"},
+		{"lt", "", &testFilter{Before: "2025-01-01"}, "finish_time < '2025-01-01'"},
+		{"gte pointer", "", &testFilter{Invest: float64Ptr(1.5)}, "total_investment >= 1.500000"},
+		{"untagged ignored", "", &testFilter{Untagged: "x"}, ""},
+		{"alias", "u", &testFilter{Name: "a", Begin: "2024-01-01", End: "2024-12-31"},
+			"u.name = 'a' AND u.create_at >= '2024-01-01' AND u.create_at <= '2024-12-31'"},
+		{"struct value", "", testFilter{Name: "a"}, "name = 'a'"},
+		{"empty string column", "", &testFilter{EmptyFilter: vo.EmptyFilter{Empty: []string{"name"}}},
+			"(name IS NULL OR name = '')"},
+		{"empty in string column", "", &testFilter{EmptyFilter: vo.EmptyFilter{Empty: []string{"duty_unit"}}},
+			"(duty_unit IS NULL OR duty_unit = '')"},
+		{"empty non-string column", "u", &testFilter{EmptyFilter: vo.EmptyFilter{Empty: []string{"level"}}},
+			"u.level IS NULL"},
+		{"empty with values", "", &testFilter{Name: "a", EmptyFilter: vo.EmptyFilter{Empty: []string{"level"}}},
+			"name = 'a' AND level IS NULL"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := filterSQL(t, c.alias, c.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Fatalf("expected %q, got %q", c.want, got)
+			}
+		})
+	}
+}
+
+func TestApplyFilterInvalid(t *testing.T) {
+	cases := []struct {
+		name   string
+		params interface{}
+		want   string
+	}{
+		{"unknown op", &testBadFilter{Name: "a"}, `unsupported filter op "like"`},
+		{"unknown op without value", &testBadFilter{}, `unsupported filter op "like"`},
+		{"empty unknown field", &testFilter{EmptyFilter: vo.EmptyFilter{Empty: []string{"password"}}},
+			`unsupported empty filter field "password"`},
+		// 区间筛选的列不能按未填写筛选
+		{"empty range field", &testFilter{EmptyFilter: vo.EmptyFilter{Empty: []string{"create_at"}}},
+			`unsupported empty filter field "create_at"`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			db, _ := dryRunDB(t)
+			_, ex := applyFilter(db.Table("t"), "", c.params)
+			if ex == nil {
+				t.Fatal("expected error")
+			}
+			if ex.Type() != response.ExceptionInvalidRequestParameters || !strings.Contains(ex.Error(), c.want) {
+				t.Fatalf("expected invalid parameters %q, got %v: %s", c.want, ex.Type(), ex.Error())
+			}
+		})
+	}
+}
+
+// 计划开工、开工时间及总投资区间与重构前一致, 只传一端时不筛选
+func TestImplementGovRangeNeedsBothBounds(t *testing.T) {
+	begin := 1.0
+	cases := []struct {
+		name   string
+		params *vo.ImplementGovCountFilter
+	}{
+		{"plan begin only", &vo.ImplementGovCountFilter{PlanBegin: "2024-01-01"}},
+		{"plan end only", &vo.ImplementGovCountFilter{PlanEnd: "2024-12-31"}},
+		{"start time only", &vo.ImplementGovCountFilter{StartTime: "2024-01-01"}},
+		{"begin invest only", &vo.ImplementGovCountFilter{BeginInvest: &begin}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := filterSQL(t, "", c.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != "" {
+				t.Fatalf("expected no condition, got %q", got)
+			}
+		})
+	}
+	got, err := filterSQL(t, "", &vo.ImplementGovCountFilter{PlanBegin: "2024-01-01", PlanEnd: "2024-12-31"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "plan_begin >= '2024-01-01' AND plan_begin <= '2024-12-31'"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+// 开工时间筛选曾误用计划开工时间参数
+func TestListStatusCountStartTime(t *testing.T) {
+	db, rec := dryRunDB(t)
+	params := &vo.ImplementGovCountFilter{
+		PlanBegin: "2020-01-01",
+		PlanEnd:   "2020-12-31",
+		StartTime: "2024-01-01",
+		EndTime:   "2024-12-31",
+	}
+	if _, ex := (&ImplementGovRepoImpl{}).ListStatusCount(db, params, true, ""); ex != nil {
+		t.Fatal(ex)
+	}
+	sql := rec.lastSQL(t)
+	expectSQL(t, sql,
+		"plan_begin >= '2020-01-01'", "plan_begin <= '2020-12-31'",
+		"start_time >= '2024-01-01'", "start_time <= '2024-12-31'")
+	expectNoSQL(t, sql, "start_time >= '2020-01-01'", "start_time <= '2020-12-31'")
+}
+
+// 当年竣工筛选曾误用计划时间参数
+func TestIndustryListCurYear(t *testing.T) {
+	db, rec := dryRunDB(t)
+	params := &vo.ImpleIndustryFilterParam{
+		PlanBegin:    "2020-01-01",
+		PlanEnd:      "2020-12-31",
+		CurYearBegin: "2024-01-01 00:00:00",
+		CurYearEnd:   "2025-01-01 00:00:00",
+	}
+	// 分页查询以 Rows 读取, DryRun 下只生成 SQL 并返回不支持
+	_, _, ex := (&ImpleIndustryRepoImpl{}).List(db, &vo.PageInfo{Page: 1, PageSize: 10}, params, "admin")
+	if ex != nil && ex.Error() != gorm.ErrDryRunModeUnsupported.Error() {
+		t.Fatal(ex)
+	}
+	sql := rec.lastSQL(t)
+	expectSQL(t, sql,
+		"create_at >= '2020-01-01'", "create_at <= '2020-12-31'",
+		"finish_time >= '2024-01-01 00:00:00'", "finish_time < '2025-01-01 00:00:00'")
+	expectNoSQL(t, sql, "finish_time >= '2020-01-01'", "finish_time < '2020-12-31'")
+}
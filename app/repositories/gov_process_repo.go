@@ -30,13 +30,13 @@ type GovProgressRepo interface {
 	Create(db *gorm.DB, impl []models.GovProgress) exception.Exception
 	ListProgressPlan(db *gorm.DB, projectID int64, year int) ([]models.ListGovProgressPlan, exception.Exception)
 	Get(db *gorm.DB, id int64, year, month int) (*models.GovProgress, exception.Exception)
+	GetByID(db *gorm.DB, id int64) (*models.GovProgress, exception.Exception)
 	Update(db *gorm.DB, id int64, param map[string]interface{}) exception.Exception
 	ListGovProgressCompare(db *gorm.DB, projectID int64, year int) ([]models.GovProgressCompare, exception.Exception)
 	DeleteByProjectID(db *gorm.DB, projectID ...int64) exception.Exception
-	ListInvested(db *gorm.DB, projectID int64, year int) ([]models.GovProgress, exception.Exception)
-	FormNowInvested(db *gorm.DB, projectID int64, year, month int) (float64, exception.Exception)
 	BetchCreate(db *gorm.DB, govProgress []models.GovProgress) exception.Exception
-	StartFormNowInvestedAndFixed(db *gorm.DB, projectID int64, startYear, startMonth, year, month int) (float64, float64, exception.Exception)
+	InvestmentSum(db *gorm.DB, year, month int, projectID ...int64) ([]models.InvestmentSum, exception.Exception)
+	ListByMonth(db *gorm.DB, year, month int, projectID ...int64) ([]models.GovProgress, exception.Exception)
 }
 
 func (grr *GovProgressRepoImpl) Create(db *gorm.DB, govProgress []models.GovProgress) exception.Exception {
@@ -56,47 +56,42 @@ func (grr *GovProgressRepoImpl) ListProgressPlan(db *gorm.DB, projectID int64, y
 	return lpg, exception.Wrap(response.ExceptionDatabase, tx.Error)
 }
 
-func (grr *GovProgressRepoImpl) ListInvested(db *gorm.DB, projectID int64, year int) ([]models.GovProgress, exception.Exception) {
-	lpg := make([]models.GovProgress, 0)
-	tx := db.Table(tables.GovProgress).Select("plan_invested, last_month_fixed_invested").Where("project_id = ?", projectID).Where("year = ?", year).Find(&lpg)
-	return lpg, exception.Wrap(response.ExceptionDatabase, tx.Error)
+// InvestmentSum 截至 year 年 month 月的累计投资: 当年累计为一月至 month 月(month 传 12 为全年), 开工至今累计为开工月至 month 月, 均含固投
+func (grr *GovProgressRepoImpl) InvestmentSum(db *gorm.DB, year, month int, projectID ...int64) ([]models.InvestmentSum,
+	exception.Exception) {
+	res := make([]models.InvestmentSum, 0, len(projectID))
+	if len(projectID) == 0 {
+		return res, nil
+	}
+	tx := investmentSum(db, year, month).Where("p.project_id in (?)", projectID).Find(&res)
+	return res, exception.Wrap(response.ExceptionDatabase, tx.Error)
 }
 
-// 开工至今累计投资额 和 开工至今累计固投
-func (grr *GovProgressRepoImpl) StartFormNowInvestedAndFixed(db *gorm.DB, projectID int64, startYear, startMonth, year, month int) (float64, float64, exception.Exception) {
-	lpg := make([]models.GovProgress, 0)
-	tx := db.Table(tables.GovProgress).Select("plan_invested, last_month_fixed_invested").Where("project_id = ?", projectID).
-		Where("year <= ? and month <= ?", year, month).Where("year >= ? and month >= ?", startYear, startMonth).Find(&lpg)
-	if tx.Error != nil {
-		return 0, 0, exception.Wrap(response.ExceptionDatabase, tx.Error)
-	}
-	invested := float64(0)
-	fixedSum := float64(0)
-	for i := range lpg {
-		if lpg[i].PlanInvested != nil {
-			invested += *lpg[i].PlanInvested
-		}
-		if lpg[i].LastMonthFixedInvested != nil {
-			fixedSum += *lpg[i].LastMonthFixedInvested
-		}
-	}
-	return invested, fixedSum, nil
+// investmentSum 截至 year 年 month 月各项目累计投资的查询, 列同 models.InvestmentSum, 也作为统计的子查询
+func investmentSum(db *gorm.DB, year, month int) *gorm.DB {
+	yearBegin := models.Period(year, 1)
+	startPeriod := "(extract(year from g.start_time)::integer * 12 + extract(month from g.start_time)::integer)"
+	return db.Table(tables.GovProgress+" AS p").
+		Joins("JOIN "+tables.ImplementGov+" AS g ON g.id = p.project_id").
+		Select(`p.project_id AS project_id,
+coalesce(sum(p.plan_invested) filter (where p.period >= ?), 0) AS year_invested,
+coalesce(sum(p.last_month_fixed_invested) filter (where p.period >= ?), 0) AS year_fixed_invested,
+coalesce(sum(p.plan_invested) filter (where p.period >= `+startPeriod+`), 0) AS start_invested,
+coalesce(sum(p.last_month_fixed_invested) filter (where p.period >= `+startPeriod+`), 0) AS start_fixed_invested`,
+			yearBegin, yearBegin).
+		Where("p.period <= ?", models.Period(year, month)).
+		Group("p.project_id")
 }
 
-// 一月至本月计划累计完成投资额
-func (grr *GovProgressRepoImpl) FormNowInvested(db *gorm.DB, projectID int64, year, month int) (float64, exception.Exception) {
-	lpg := make([]models.GovProgress, 0)
-	tx := db.Table(tables.GovProgress).Select("plan_invested").Where("project_id = ?", projectID).Where("year = ? and month <= ?", year, month).Find(&lpg)
-	if tx.Error != nil {
-		return 0, exception.Wrap(response.ExceptionDatabase, tx.Error)
+// ListByMonth 多个项目某月的进度填报
+func (grr *GovProgressRepoImpl) ListByMonth(db *gorm.DB, year, month int, projectID ...int64) ([]models.GovProgress,
+	exception.Exception) {
+	res := make([]models.GovProgress, 0, len(projectID))
+	if len(projectID) == 0 {
+		return res, nil
 	}
-	total := float64(0)
-	for i := range lpg {
-		if lpg[i].PlanInvested != nil {
-			total += *lpg[i].PlanInvested
-		}
-	}
-	return total, exception.Wrap(response.ExceptionDatabase, tx.Error)
+	tx := db.Where("project_id in (?)", projectID).Where("period = ?", models.Period(year, month)).Find(&res)
+	return res, exception.Wrap(response.ExceptionDatabase, tx.Error)
 }
 
 func (grr *GovProgressRepoImpl) Get(db *gorm.DB, id int64, year, month int) (*models.GovProgress, exception.Exception) {
@@ -111,6 +106,18 @@ func (grr *GovProgressRepoImpl) Get(db *gorm.DB, id int64, year, month int) (*mo
 	return &govProgress, nil
 }
 
+func (grr *GovProgressRepoImpl) GetByID(db *gorm.DB, id int64) (*models.GovProgress, exception.Exception) {
+	govProgress := models.GovProgress{}
+	res := db.Where(&models.GovProgress{ID: id}).Find(&govProgress)
+	if res.RowsAffected == 0 {
+		return nil, exception.New(response.ExceptionRecordNotFound, "recode not found")
+	}
+	if res.Error != nil {
+		return nil, exception.Wrap(response.ExceptionDatabase, res.Error)
+	}
+	return &govProgress, nil
+}
+
 func (rri *GovProgressRepoImpl) Update(db *gorm.DB, id int64, param map[string]interface{}) exception.Exception {
 	return exception.Wrap(response.ExceptionDatabase,
 		db.Model(&models.GovProgress{}).Where(&models.GovProgress{ID: id}).Updates(param).Error)
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"lpms/app/models"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+const startPeriodSQL = "(extract(year from g.start_time)::integer * 12 + extract(month from g.start_time)::integer)"
+
+var (
+	// 累计的截止期间
+	periodEndRe = regexp.MustCompile(`WHERE p\.period <= (\d+)\b`)
+	// 当年累计的起始期间, 投资额及固投各一处
+	yearBeginRe = regexp.MustCompile(`sum\(p\.(?:plan_invested|last_month_fixed_invested)\) filter \(where p\.period >= (\d+)\), 0\) AS year_`)
+	// 开工至今累计的起始期间表达式
+	startBeginRe = regexp.MustCompile(`sum\(p\.(?:plan_invested|last_month_fixed_invested)\) filter \(where p\.period >= (\(.*?\)::integer\))\), 0\) AS start_`)
+)
+
+// investmentBounds 解析 InvestmentSum 的累计范围: 当年累计起始期间、截止期间及开工至今累计的起始表达式
+func investmentBounds(t *testing.T, sql string) (yearBegin, end int, startBegin string) {
+	t.Helper()
+	m := periodEndRe.FindStringSubmatch(sql)
+	if m == nil {
+		t.Fatalf("no period upper bound\nsql: %s", sql)
+	}
+	end, _ = strconv.Atoi(m[1])
+	begins := yearBeginRe.FindAllStringSubmatch(sql, -1)
+	if len(begins) != 2 || begins[0][1] != begins[1][1] {
+		t.Fatalf("expected the same year begin for invested and fixed invested, got %v\nsql: %s", begins, sql)
+	}
+	yearBegin, _ = strconv.Atoi(begins[0][1])
+	starts := startBeginRe.FindAllStringSubmatch(sql, -1)
+	if len(starts) != 2 || starts[0][1] != starts[1][1] {
+		t.Fatalf("expected the same start begin for invested and fixed invested, got %v\nsql: %s", starts, sql)
+	}
+	return yearBegin, end, starts[0][1]
+}
+
+func TestPeriodAcrossYears(t *testing.T) {
+	if models.Period(2024, 1)-models.Period(2023, 12) != 1 {
+		t.Fatal("expected January to follow December of the previous year")
+	}
+	if models.Period(2024, 12) >= models.Period(2025, 1) {
+		t.Fatal("expected December before January of the next year")
+	}
+}
+
+func TestInvestmentSumNoProjects(t *testing.T) {
+	db, rec := dryRunDB(t)
+	res, ex := (&GovProgressRepoImpl{}).InvestmentSum(db, 2024, 6)
+	if ex != nil || len(res) != 0 {
+		t.Fatalf("expected empty result, got %v, %v", res, ex)
+	}
+	if len(rec.sqls) != 0 {
+		t.Fatalf("expected no query, got %v", rec.sqls)
+	}
+}
+
+func TestInvestmentSumPeriods(t *testing.T) {
+	type month struct{ year, month int }
+	// 各月填报, 按解析出的范围累计
+	filled := []month{{2022, 12}, {2023, 1}, {2023, 6}, {2023, 12}, {2024, 1}, {2024, 5}, {2024, 6}, {2024, 7}, {2025, 1}}
+	cases := []struct {
+		name        string
+		year, month int
+		// 当年累计应包含的月份
+		want []month
+	}{
+		{"mid year", 2024, 6, []month{{2024, 1}, {2024, 5}, {2024, 6}}},
+		{"january only", 2024, 1, []month{{2024, 1}}},
+		{"full year", 2023, 12, []month{{2023, 1}, {2023, 6}, {2023, 12}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			db, rec := dryRunDB(t)
+			if _, ex := (&GovProgressRepoImpl{}).InvestmentSum(db, c.year, c.month, 1, 2); ex != nil {
+				t.Fatal(ex)
+			}
+			sql := rec.lastSQL(t)
+			yearBegin, end, startBegin := investmentBounds(t, sql)
+			got := make([]month, 0)
+			for _, m := range filled {
+				if p := models.Period(m.year, m.month); p >= yearBegin && p <= end {
+					got = append(got, m)
+				}
+			}
+			if len(got) != len(c.want) {
+				t.Fatalf("expected year sum of %v, got %v", c.want, got)
+			}
+			for i := range got {
+				if got[i] != c.want[i] {
+					t.Fatalf("expected year sum of %v, got %v", c.want, got)
+				}
+			}
+			// 开工至今累计从开工当月开始, 截止期间与当年累计相同
+			if startBegin != startPeriodSQL {
+				t.Fatalf("expected start sum from %s, got %s", startPeriodSQL, startBegin)
+			}
+			expectSQL(t, sql, "p.project_id in (1,2)")
+			expectNoSQL(t, sql, "p.period < ", "p.period > ")
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"fmt"
 	"lpms/app/models"
 	"lpms/app/models/tables"
 	"lpms/app/response"
@@ -36,6 +37,16 @@ type ImplementGovRepo interface {
 	MultiDelete(db *gorm.DB, ids []int64) exception.Exception
 	ListStatusCount(db *gorm.DB, params *vo.ImplementGovCountFilter, isAdmin bool, user string) ([]ListCountModel, exception.Exception)
 	ProgressLight(db *gorm.DB, projectID int64, year, month int) (int, exception.Exception)
+	ListReconcile(db *gorm.DB, params *vo.ReconcileFilterParam, isAdmin bool, user string) ([]models.ImplementGov, exception.Exception)
+	ListAll(db *gorm.DB) ([]models.ImplementGov, exception.Exception)
+	PortfolioStat(db *gorm.DB, params *vo.PortfolioStatFilter, snapshotID int64, isAdmin bool, user string) ([]models.PortfolioStat,
+		exception.Exception)
+	DataAnalysis(db *gorm.DB, params *vo.ImplementAnalysisFilter, begin, end time.Time, isAdmin bool, user string) (
+		[]models.ImplementAnalysis, exception.Exception)
+	MapProjects(db *gorm.DB, params *vo.MapFilterParam, year, month int, isAdmin bool, user string) ([]models.MapProject,
+		exception.Exception)
+	MapTownships(db *gorm.DB, params *vo.MapFilterParam, year, month int, isAdmin bool, user string) ([]models.MapTownship,
+		exception.Exception)
 }
 
 func (igi *ImplementGovRepoImpl) Create(db *gorm.DB, impl *models.ImplementGov) exception.Exception {
@@ -74,54 +85,28 @@ func (igi *ImplementGovRepoImpl) List(db *gorm.DB, pageInfo *vo.PageInfo, params
 	if !isAdmin {
 		tx = tx.Where("create_by = ?", user)
 	}
-	if params.Name != "" {
-		tx = tx.Where("name = ?", params.Name)
-	}
-	if params.Level != nil {
-		tx = tx.Where("level = ?", params.Level)
-	}
-	if params.ProjectType != nil {
-		tx = tx.Where("project_type = ?", params.ProjectType)
-	}
-	if params.ConstructSubject != "" {
-		tx = tx.Where("construct_subject = ?", params.ConstructSubject)
-	}
-	if params.PointType != nil {
-		tx = tx.Where("point_type = ?", params.PointType)
+	tx, ex := applyFilter(tx, "", params)
+	if ex != nil {
+		return 0, nil, ex
 	}
-	if params.PlanBegin != "" && params.PlanEnd != "" {
-		tx = tx.Where("plan_begin <= ? and plan_begin >= ?", params.PlanEnd, params.PlanBegin)
-	}
-	if params.StartTime != "" && params.EndTime != "" {
-		tx = tx.Where("start_time <= ? and start_time >= ?", params.EndTime, params.StartTime)
-	}
-	if params.CurYearBegin != "" && params.CurYearEnd != "" {
-		if params.Status != nil {
-			if *params.Status == -1 {
-				tx = tx.Where("create_at < ? and create_at >= ?", params.CurYearEnd, params.CurYearBegin)
-			} else if *params.Status == 4 {
-				tx = tx.Where("finish_time < ? and finish_time >= ?", params.CurYearEnd, params.CurYearBegin)
-			}
+	// 状态 -1 为当年新增的全部项目, 4 且带当年时间为当年竣工
+	if params.CurYearBegin != "" && params.CurYearEnd != "" && params.Status != nil {
+		if *params.Status == -1 {
+			tx = tx.Where("create_at < ? and create_at >= ?", params.CurYearEnd, params.CurYearBegin)
+		} else if *params.Status == constant.Finished {
+			tx = tx.Where("finish_time < ? and finish_time >= ?", params.CurYearEnd, params.CurYearBegin)
 		}
 	}
-	if params.Status != nil {
-		if *params.Status != -1 {
-			tx = tx.Where("status = ?", params.Status)
-		}
+	if params.Status != nil && *params.Status != -1 {
+		tx = tx.Where("status = ?", params.Status)
 	}
-	if params.BeginInvest != nil && params.EndInvest != nil {
-		tx = tx.Where("total_investment <= ? and total_investment >= ?", params.EndInvest, params.BeginInvest)
+	tx = keywordSearch(tx, tables.ImplementGovSearchDoc, pageInfo.Keywords)
+	order, ex := listOrder(pageInfo, implementGovSortFields, tables.ImplementGovSearchDoc, "type", "project_code")
+	if ex != nil {
+		return 0, nil, ex
 	}
-	if params.DutyUnit != "" {
-		tx = tx.Where("duty_unit = ?", params.DutyUnit)
-	}
-	if params.Type != nil {
-		tx = tx.Where("type = ?", params.Type)
-	}
-	count := int64(0)
-	tx = tx.Limit(pageInfo.PageSize).Offset(pageInfo.Offset()).Order("type ASC").Order("project_code ASC").
-		Scan(&data).Limit(-1).Offset(-1).Count(&count)
-	return count, data, exception.Wrap(response.ExceptionDatabase, tx.Error)
+	count, ex := paginate(tx, pageInfo, order, &data)
+	return count, data, ex
 }
 
 func (igi *ImplementGovRepoImpl) Delete(db *gorm.DB, id int64) exception.Exception {
@@ -145,35 +130,9 @@ func (igi *ImplementGovRepoImpl) ListStatusCount(db *gorm.DB, params *vo.Impleme
 	if !isAdmin {
 		subTx = subTx.Where("create_by = ?", user)
 	}
-	if params.Name != "" {
-		subTx = subTx.Where("name = ?", params.Name)
-	}
-	if params.Level != nil {
-		subTx = subTx.Where("level = ?", params.Level)
-	}
-	if params.ProjectType != nil {
-		subTx = subTx.Where("project_type = ?", params.ProjectType)
-	}
-	if params.ConstructSubject != "" {
-		subTx = subTx.Where("construct_subject = ?", params.ConstructSubject)
-	}
-	if params.PointType != nil {
-		subTx = subTx.Where("point_type = ?", params.PointType)
-	}
-	if params.PlanBegin != "" && params.PlanEnd != "" {
-		subTx = subTx.Where("plan_begin <= ? and plan_begin >= ?", params.PlanEnd, params.PlanBegin)
-	}
-	if params.StartTime != "" && params.EndTime != "" {
-		subTx = subTx.Where("start_time <= ? and start_time >= ?", params.PlanEnd, params.PlanBegin)
-	}
-	if params.BeginInvest != nil && params.EndInvest != nil {
-		subTx = subTx.Where("total_investment <= ? and total_investment >= ?", params.EndInvest, params.BeginInvest)
-	}
-	if params.DutyUnit != "" {
-		subTx = subTx.Where("duty_unit = ?", params.DutyUnit)
-	}
-	if params.Type != nil {
-		subTx = subTx.Where("type = ?", params.Type)
+	subTx, ex := applyFilter(subTx, "", params)
+	if ex != nil {
+		return nil, ex
 	}
 	tx := db.Table("(?) AS sub", subTx).Select("sub.status AS status, count(*) AS count").Group("sub.status").Find(&res)
 	if tx.Error != nil {
@@ -206,3 +165,174 @@ func (igi *ImplementGovRepoImpl) ListStatusCount(db *gorm.DB, params *vo.Impleme
 	})
 	return res, nil
 }
+
+// 资金对账项目: 当年底前已入库, 且当年初之前未竣工
+func (igi *ImplementGovRepoImpl) ListReconcile(db *gorm.DB, params *vo.ReconcileFilterParam, isAdmin bool, user string) (
+	[]models.ImplementGov, exception.Exception) {
+	data := make([]models.ImplementGov, 0)
+	begin := time.Date(params.Year, 1, 1, 0, 0, 0, 0, time.Local)
+	tx := db.Table(tables.ImplementGov).Where("create_at < ?", begin.AddDate(1, 0, 0)).
+		Where("finish_time is null or finish_time >= ?", begin)
+	if !isAdmin {
+		tx = tx.Where("create_by = ?", user)
+	}
+	if params.ProjectID != nil {
+		tx = tx.Where("id = ?", params.ProjectID)
+	}
+	if params.DutyUnit != "" {
+		tx = tx.Where("duty_unit = ?", params.DutyUnit)
+	}
+	if params.Township != "" {
+		tx = tx.Where("township = ?", params.Township)
+	}
+	if params.ProjectType != nil {
+		tx = tx.Where("project_type = ?", params.ProjectType)
+	}
+	tx = tx.Order("project_code ASC").Find(&data)
+	return data, exception.Wrap(response.ExceptionDatabase, tx.Error)
+}
+
+func (igi *ImplementGovRepoImpl) ListAll(db *gorm.DB) ([]models.ImplementGov, exception.Exception) {
+	data := make([]models.ImplementGov, 0)
+	tx := db.Order("project_code ASC").Find(&data)
+	return data, exception.Wrap(response.ExceptionDatabase, tx.Error)
+}
+
+// implementUnion 政府项目与产业项目合并, 产业项目 source=2 且无责任单位、所属街镇
+func implementUnion(db *gorm.DB) *gorm.DB {
+	gov := db.Table(tables.ImplementGov).Select(`id, 1 AS source, type, level, project_type, point_type,
+coalesce(duty_unit, '') AS duty_unit, coalesce(township, '') AS township, name, construct_subject, plan_begin, start_time,
+finish_time, total_investment, status, create_by, location, boundary`)
+	industry := db.Table(tables.ImplementIndustry).Select(fmt.Sprintf(`id, 2 AS source, %d AS type, level, project_type,
+point_type, '' AS duty_unit, '' AS township, name, construct_subject, plan_begin, start_time, finish_time, total_investment,
+status, create_by, location, boundary`, constant.IndustryProject))
+	return db.Raw("? UNION ALL ?", gov, industry)
+}
+
+// filterImplementUnion 合并后项目(别名 u)的筛选条件, 与列表筛选一致; params 为带 filter 标签的筛选参数
+func filterImplementUnion(tx *gorm.DB, params interface{}, isAdmin bool, user string) (*gorm.DB,
+	exception.Exception) {
+	tx = tx.Where("u.status in (?, ?, ?)", constant.UnStart, constant.Started, constant.Finished)
+	if !isAdmin {
+		tx = tx.Where("u.create_by = ?", user)
+	}
+	return applyFilter(tx, "u", params)
+}
+
+// PortfolioStat 政府项目与产业项目合并后, 按项目分类、类型、级别、重点类型、责任单位汇总总投资及当年累计完成投资,
+// 各维度在同一查询中以 GROUPING SETS 计算; 产业项目无进度填报, 完成投资计为0.
+// snapshotID 不为0时改为统计该月末快照中冻结的数据
+func (igi *ImplementGovRepoImpl) PortfolioStat(db *gorm.DB, params *vo.PortfolioStatFilter, snapshotID int64, isAdmin bool,
+	user string) ([]models.PortfolioStat, exception.Exception) {
+	res := make([]models.PortfolioStat, 0)
+	tx := db.Table(tables.SnapshotProject+" AS u").Where("u.snapshot_id = ?", snapshotID)
+	invested := "u.year_invested"
+	if snapshotID == 0 {
+		tx = db.Table("(?) AS u", implementUnion(db)).
+			Joins("LEFT JOIN (?) AS p ON u.source = 1 AND p.project_id = u.id", investmentSum(db, params.Year, params.Month))
+		invested = "p.year_invested"
+	}
+	tx, ex := filterImplementUnion(tx, &params.ImplementGovCountFilter, isAdmin, user)
+	if ex != nil {
+		return nil, ex
+	}
+	tx = tx.Select(`CASE WHEN grouping(u.type) = 0 THEN 'type'
+WHEN grouping(u.project_type) = 0 THEN 'project_type'
+WHEN grouping(u.level) = 0 THEN 'level'
+WHEN grouping(u.point_type) = 0 THEN 'point_type'
+WHEN grouping(u.duty_unit) = 0 THEN 'duty_unit'
+ELSE 'all' END AS dimension,
+coalesce(u.type::text, u.project_type::text, u.level::text, u.point_type::text, u.duty_unit) AS group_key,
+count(*) AS count,
+coalesce(sum(u.total_investment), 0) AS total_investment,
+coalesce(sum(` + invested + `), 0) AS year_invested`).
+		Group("GROUPING SETS ((), (u.type), (u.project_type), (u.level), (u.point_type), (u.duty_unit))").
+		Order("dimension, total_investment DESC, group_key").Find(&res)
+	return res, exception.Wrap(response.ExceptionDatabase, tx.Error)
+}
+
+// DataAnalysis 按月/年统计新开工项目数、竣工项目数及进度填报的完成投资额, 时间区间为 [begin, end)
+func (igi *ImplementGovRepoImpl) DataAnalysis(db *gorm.DB, params *vo.ImplementAnalysisFilter, begin, end time.Time,
+	isAdmin bool, user string) ([]models.ImplementAnalysis, exception.Exception) {
+	format := "YYYY-MM"
+	if params.QueryType == 1 {
+		format = "YYYY"
+	}
+	filtered, ex := filterImplementUnion(db.Table("(?) AS u", implementUnion(db)), &params.ImplementGovCountFilter, isAdmin, user)
+	if ex != nil {
+		return nil, ex
+	}
+	// 三组统计共用筛选条件
+	filtered = filtered.Session(&gorm.Session{})
+	started := filtered.Select("to_char(u.start_time, ?) AS bucket, 1 AS started, 0 AS finished, 0 AS invested", format).
+		Where("u.start_time >= ? and u.start_time < ?", begin, end)
+	finished := filtered.Select("to_char(u.finish_time, ?) AS bucket, 0 AS started, 1 AS finished, 0 AS invested", format).
+		Where("u.finish_time >= ? and u.finish_time < ?", begin, end)
+	invested := filtered.Joins("JOIN "+tables.GovProgress+" AS p ON u.source = 1 AND p.project_id = u.id").
+		Select("to_char(make_date(p.year, p.month, 1), ?) AS bucket, 0 AS started, 0 AS finished, coalesce(p.plan_invested, 0) AS invested", format).
+		Where("p.period >= ? and p.period <= ?", models.Period(begin.Year(), int(begin.Month())),
+			models.Period(end.Add(-time.Nanosecond).Year(), int(end.Add(-time.Nanosecond).Month())))
+	res := make([]models.ImplementAnalysis, 0)
+	tx := db.Table("(?) AS sub", db.Raw("? UNION ALL ? UNION ALL ?", started, finished, invested)).
+		Select("sub.bucket AS bucket, sum(sub.started) AS started, sum(sub.finished) AS finished, sum(sub.invested) AS invested").
+		Group("sub.bucket").Order("sub.bucket").Find(&res)
+	return res, exception.Wrap(response.ExceptionDatabase, tx.Error)
+}
+
+// mapUnion 地图查询的合并项目(别名 u)及政府项目截至 year 年 month 月的已提交进度数(别名 p)
+func mapUnion(db *gorm.DB, params *vo.MapFilterParam, year, month int, isAdmin bool, user string) (*gorm.DB,
+	exception.Exception) {
+	progress := db.Table(tables.GovProgress).Select("project_id, count(*) AS submitted").
+		Where("year = ? and month <= ? and status = ?", year, month, 1).Group("project_id")
+	tx := db.Table("(?) AS u", implementUnion(db)).
+		Joins("LEFT JOIN (?) AS p ON u.source = 1 AND p.project_id = u.id", progress)
+	tx, ex := filterImplementUnion(tx, params, isAdmin, user)
+	if ex != nil {
+		return nil, ex
+	}
+	if len(params.BBox) == 4 {
+		tx = tx.Where(tables.LocationLng+" BETWEEN ? AND ? AND "+tables.LocationLat+" BETWEEN ? AND ?",
+			params.BBox[0], params.BBox[2], params.BBox[1], params.BBox[3])
+	}
+	return tx, nil
+}
+
+// mapProgress 进度灯, 与 ProgressLight 一致: 当年截至当月每月均已提交为绿灯; 产业项目为空
+const mapProgress = "CASE WHEN u.source <> 1 THEN NULL WHEN coalesce(p.submitted, 0) = ? THEN ? ELSE ? END"
+
+// MapProjects 有位置的项目点位, 按来源、id 排序
+func (igi *ImplementGovRepoImpl) MapProjects(db *gorm.DB, params *vo.MapFilterParam, year, month int, isAdmin bool,
+	user string) ([]models.MapProject, exception.Exception) {
+	res := make([]models.MapProject, 0)
+	tx, ex := mapUnion(db, params, year, month, isAdmin, user)
+	if ex != nil {
+		return nil, ex
+	}
+	boundary := "NULL::jsonb AS boundary"
+	if params.WithBoundary {
+		boundary = "u.boundary AS boundary"
+	}
+	tx = tx.Where("u.location IS NOT NULL").
+		Select(`u.id AS id, u.source AS source, u.type AS type, u.name AS name, u.level AS level,
+u.project_type AS project_type, u.status AS status, u.township AS township, u.total_investment AS total_investment,
+u.location AS location, `+boundary+`, `+mapProgress+` AS progress`, month, constant.Green, constant.Red).
+		Order("u.source, u.id").Find(&res)
+	return res, exception.Wrap(response.ExceptionDatabase, tx.Error)
+}
+
+// MapTownships 按所属街镇汇总项目数、总投资、各状态及红灯项目数, 位置取街镇内有位置项目的平均经纬度
+func (igi *ImplementGovRepoImpl) MapTownships(db *gorm.DB, params *vo.MapFilterParam, year, month int, isAdmin bool,
+	user string) ([]models.MapTownship, exception.Exception) {
+	res := make([]models.MapTownship, 0)
+	tx, ex := mapUnion(db, params, year, month, isAdmin, user)
+	if ex != nil {
+		return nil, ex
+	}
+	tx = tx.Select(`u.township AS township, count(*) AS count, coalesce(sum(u.total_investment), 0) AS total_investment,
+count(*) FILTER (WHERE u.status = ?) AS un_start, count(*) FILTER (WHERE u.status = ?) AS started,
+count(*) FILTER (WHERE u.status = ?) AS finished, count(*) FILTER (WHERE `+mapProgress+` = ?) AS red,
+avg(`+tables.LocationLng+`) AS lng, avg(`+tables.LocationLat+`) AS lat`,
+		constant.UnStart, constant.Started, constant.Finished, month, constant.Green, constant.Red, constant.Red).
+		Group("u.township").Order("u.township").Find(&res)
+	return res, exception.Wrap(response.ExceptionDatabase, tx.Error)
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"lpms/app/models"
+	"lpms/app/vo"
+	"testing"
+)
+
+// 组合统计的当年累计完成投资与进度填报共用同一累计子查询
+func TestPortfolioStatUsesInvestmentSum(t *testing.T) {
+	db, rec := dryRunDB(t)
+	investmentSum(db, 2024, 6).Find(&[]models.InvestmentSum{})
+	sum := rec.lastSQL(t)
+	if _, ex := (&ImplementGovRepoImpl{}).PortfolioStat(db, &vo.PortfolioStatFilter{Year: 2024, Month: 6}, 0, true, ""); ex != nil {
+		t.Fatal(ex)
+	}
+	expectSQL(t, rec.lastSQL(t), "LEFT JOIN ("+sum+") AS p ON u.source = 1 AND p.project_id = u.id", "sum(p.year_invested)")
+}
+
+// 按快照统计时使用快照冻结的当年累计, 不再查询进度填报
+func TestPortfolioStatSnapshot(t *testing.T) {
+	db, rec := dryRunDB(t)
+	if _, ex := (&ImplementGovRepoImpl{}).PortfolioStat(db, &vo.PortfolioStatFilter{Year: 2024, Month: 6}, 3, true, ""); ex != nil {
+		t.Fatal(ex)
+	}
+	sql := rec.lastSQL(t)
+	expectSQL(t, sql, "u.snapshot_id = 3", "sum(u.year_invested)")
+	expectNoSQL(t, sql, "p.period")
+}
@@ -33,6 +33,7 @@ type ImpleIndustryRepo interface {
 		exception.Exception)
 	Delete(db *gorm.DB, id int64) exception.Exception
 	MultiDelete(db *gorm.DB, ids []int64) exception.Exception
+	ListAll(db *gorm.DB) ([]models.ImpleIndustry, exception.Exception)
 }
 
 func (igi *ImpleIndustryRepoImpl) Create(db *gorm.DB, impl *models.ImpleIndustry) exception.Exception {
@@ -59,34 +60,17 @@ func (igi *ImpleIndustryRepoImpl) List(db *gorm.DB, pageInfo *vo.PageInfo, param
 	if user != "admin" {
 		tx = tx.Where("create_by = ?", user)
 	}
-	if params.Name != "" {
-		tx = tx.Where("name = ?", params.Name)
+	tx, ex := applyFilter(tx, "", params)
+	if ex != nil {
+		return 0, nil, ex
 	}
-	if params.Level != nil {
-		tx = tx.Where("level = ?", params.Level)
+	tx = keywordSearch(tx, tables.ImplementIndustrySearchDoc, pageInfo.Keywords)
+	order, ex := listOrder(pageInfo, implementIndustrySortFields, tables.ImplementIndustrySearchDoc)
+	if ex != nil {
+		return 0, nil, ex
 	}
-	if params.ProjectType != nil {
-		tx = tx.Where("project_type = ?", params.ProjectType)
-	}
-	if params.ConstructSubject != "" {
-		tx = tx.Where("construct_subject = ?", params.ConstructSubject)
-	}
-	if params.PointType != nil {
-		tx = tx.Where("point_type = ?", params.PointType)
-	}
-	if params.PlanBegin != "" && params.PlanEnd != "" {
-		tx = tx.Where("create_at <= ? and create_at >= ?", params.PlanEnd, params.PlanBegin)
-	}
-	if params.CurYearBegin != "" && params.CurYearEnd != "" {
-		tx = tx.Where("finish_time < ? and finish_time >= ?", params.PlanEnd, params.PlanBegin)
-	}
-	if params.Status != nil {
-		tx = tx.Where("status = ?", params.Status)
-	}
-	count := int64(0)
-	tx = tx.Limit(pageInfo.PageSize).Offset(pageInfo.Offset()).
-		Scan(&data).Limit(-1).Offset(-1).Count(&count)
-	return count, data, exception.Wrap(response.ExceptionDatabase, tx.Error)
+	count, ex := paginate(tx, pageInfo, order, &data)
+	return count, data, ex
 }
 
 func (igi *ImpleIndustryRepoImpl) Delete(db *gorm.DB, id int64) exception.Exception {
@@ -96,3 +80,9 @@ func (igi *ImpleIndustryRepoImpl) Delete(db *gorm.DB, id int64) exception.Except
 func (igi *ImpleIndustryRepoImpl) MultiDelete(db *gorm.DB, ids []int64) exception.Exception {
 	return exception.Wrap(response.ExceptionDatabase, db.Delete(&models.ImpleIndustry{}, ids).Error)
 }
+
+func (igi *ImpleIndustryRepoImpl) ListAll(db *gorm.DB) ([]models.ImpleIndustry, exception.Exception) {
+	data := make([]models.ImpleIndustry, 0)
+	tx := db.Order("id ASC").Find(&data)
+	return data, exception.Wrap(response.ExceptionDatabase, tx.Error)
+}
@@ -11,6 +11,7 @@ import (
 	"lpms/constant"
 	"lpms/exception"
 	"lpms/minio_sdk"
+	"net/url"
 	"sync"
 	"time"
 
@@ -20,8 +21,19 @@ import (
 type ObjectRepo interface {
 	Upload(db *gorm.DB, o *models.Object) exception.Exception
 	UploadFromReader(db *gorm.DB, o *models.Object, reader io.Reader) exception.Exception
-	Download(db *gorm.DB, id string) (*models.Object, exception.Exception)
-	Delete(db *gorm.DB, id string) exception.Exception
+	Get(db *gorm.DB, id string) (*models.Object, exception.Exception)
+	Visible(db *gorm.DB, id string, user string) (bool, exception.Exception)
+	Open(o *models.Object) (*minio_sdk.ObjectContent, exception.Exception)
+	Stat(o *models.Object) (*minio_sdk.Object, exception.Exception)
+	PresignPut(o *models.Object, expires time.Duration) (string, exception.Exception)
+	PresignGet(o *models.Object, expires time.Duration, params url.Values) (string, exception.Exception)
+	Create(db *gorm.DB, o *models.Object) exception.Exception
+	DeleteContent(o *models.Object) exception.Exception
+	Quarantine(o *models.Object, reader io.Reader) exception.Exception
+	ListAll(db *gorm.DB) ([]models.Object, exception.Exception)
+	Unreferenced(db *gorm.DB, before time.Time) ([]models.Object, exception.Exception)
+	ListContents() ([]minio_sdk.Object, exception.Exception)
+	Delete(db *gorm.DB, id string) (*models.Object, exception.Exception)
 	Upsert(db *gorm.DB, id string, o *models.Object) error
 	Import(db *gorm.DB, id string, o *models.Object) error
 }
@@ -64,7 +76,8 @@ func (ori *objectRepositoryImpl) UploadFromReader(db *gorm.DB, o *models.Object,
 	return exception.Wrap(response.ExceptionDatabase, db.Create(o).Error)
 }
 
-func (ori *objectRepositoryImpl) Download(db *gorm.DB, id string) (*models.Object, exception.Exception) {
+// Get 文件信息, 不读取内容
+func (ori *objectRepositoryImpl) Get(db *gorm.DB, id string) (*models.Object, exception.Exception) {
 	var obj models.Object
 	err := db.Where(models.Object{
 		ID: id,
@@ -75,28 +88,151 @@ func (ori *objectRepositoryImpl) Download(db *gorm.DB, id string) (*models.Objec
 	if err != nil {
 		return nil, exception.Wrap(response.ExceptionDatabase, err)
 	}
+	return &obj, nil
+}
+
+// Visible 文件是否被 user 创建的项目引用: 项目CAD文件、无拆迁照片及项目附件, 政府投资项目的进度照片及合同附件
+func (ori *objectRepositoryImpl) Visible(db *gorm.DB, id string, user string) (bool, exception.Exception) {
+	sqlStr := fmt.Sprintf(`SELECT EXISTS (
+SELECT 1 FROM %s WHERE create_by = ? AND (upload_cad_id = ? OR site_photo = ?)
+UNION ALL SELECT 1 FROM %s WHERE create_by = ? AND (upload_cad_id = ? OR site_photo = ?)
+UNION ALL SELECT 1 FROM %s WHERE create_by = ? AND (upload_cad_id = ? OR site_photo = ?)
+UNION ALL SELECT 1 FROM %s p JOIN %s g ON g.id = p.project_id
+WHERE g.create_by = ? AND (p.object_id = ? OR p.thumbnail_id = ?)
+UNION ALL SELECT 1 FROM %s c JOIN %s g ON g.id = c.project_id
+WHERE g.create_by = ? AND c.attachments @> jsonb_build_array(?::text)
+UNION ALL SELECT 1 FROM %s a
+LEFT JOIN %s r ON a.source = ? AND r.id = a.project_id
+LEFT JOIN %s g ON a.source = ? AND g.id = a.project_id
+LEFT JOIN %s i ON a.source = ? AND i.id = a.project_id
+WHERE a.object_id = ? AND ? IN (r.create_by, g.create_by, i.create_by))`,
+		tables.Reserve, tables.ImplementGov, tables.ImplementIndustry,
+		tables.ProgressPhoto, tables.ImplementGov, tables.Contract, tables.ImplementGov,
+		tables.ProjectAttachment, tables.Reserve, tables.ImplementGov, tables.ImplementIndustry)
+	var visible bool
+	err := db.Raw(sqlStr,
+		user, id, id,
+		user, id, id,
+		user, id, id,
+		user, id, id,
+		user, id,
+		constant.AttachReserve, constant.AttachGov, constant.AttachIndustry, id, user).Scan(&visible).Error
+	return visible, exception.Wrap(response.ExceptionDatabase, err)
+}
 
-	content, err := ori.minio.DownloadObject(ori.bucket, obj.Path)
+// Open 打开文件内容, 使用后须关闭
+func (ori *objectRepositoryImpl) Open(o *models.Object) (*minio_sdk.ObjectContent, exception.Exception) {
+	content, err := ori.minio.OpenObject(ori.bucket, o.Path)
+	if errors.Is(err, minio_sdk.ErrObjectNotFound) {
+		return nil, exception.Wrap(response.ExceptionRecordNotFound, err)
+	}
 	if err != nil {
 		return nil, exception.Wrap(response.ExceptionDownloadObject, err)
 	}
+	return content, nil
+}
 
-	obj.Buff = content
+// Stat 已存储文件的属性, 未存储时返回记录不存在
+func (ori *objectRepositoryImpl) Stat(o *models.Object) (*minio_sdk.Object, exception.Exception) {
+	info, err := ori.minio.StatObject(ori.bucket, o.Path)
+	if errors.Is(err, minio_sdk.ErrObjectNotFound) {
+		return nil, exception.Wrap(response.ExceptionRecordNotFound, err)
+	}
+	if err != nil {
+		return nil, exception.Wrap(response.ExceptionDownloadObject, err)
+	}
+	return info, nil
+}
 
-	return &obj, nil
+// PresignPut 直传存储的地址, 存储不支持预签名地址时返回未配置
+func (ori *objectRepositoryImpl) PresignPut(o *models.Object, expires time.Duration) (string, exception.Exception) {
+	u, err := ori.minio.PresignPutObject(ori.bucket, o.Path, expires)
+	if errors.Is(err, minio_sdk.ErrPresignUnsupported) {
+		return "", exception.New(response.ExceptionNotConfigure, "当前存储不支持直传, 请通过服务端上传")
+	}
+	if err != nil {
+		return "", exception.Wrap(response.ExceptionUploadObject, err)
+	}
+	return u, nil
 }
 
-func (ori *objectRepositoryImpl) Delete(db *gorm.DB, id string) exception.Exception {
-	var obj models.Object
-	db.Where(models.Object{
-		ID: id,
-	}).Find(&obj)
-	if ex := exception.Wrap(response.ExceptionDeleteObject, ori.minio.DeleteObject(ori.bucket, obj.Path)); ex != nil {
-		return ex
+// PresignGet 直接从存储下载的地址, 存储不支持预签名地址时返回未配置
+func (ori *objectRepositoryImpl) PresignGet(o *models.Object, expires time.Duration, params url.Values) (string,
+	exception.Exception) {
+	u, err := ori.minio.PresignGetObject(ori.bucket, o.Path, expires, params)
+	if errors.Is(err, minio_sdk.ErrPresignUnsupported) {
+		return "", exception.New(response.ExceptionNotConfigure, "当前存储不支持预签名下载地址")
+	}
+	if err != nil {
+		return "", exception.Wrap(response.ExceptionDownloadObject, err)
+	}
+	return u, nil
+}
+
+// Create 仅写入文件记录, 用于内容已直传存储的文件
+func (ori *objectRepositoryImpl) Create(db *gorm.DB, o *models.Object) exception.Exception {
+	return exception.Wrap(response.ExceptionDatabase, db.Create(o).Error)
+}
+
+// DeleteContent 仅删除存储中的文件内容
+func (ori *objectRepositoryImpl) DeleteContent(o *models.Object) exception.Exception {
+	return exception.Wrap(response.ExceptionDeleteObject, ori.minio.DeleteObject(ori.bucket, o.Path))
+}
+
+// Quarantine 将文件内容存入隔离存储, 不写入文件记录
+func (ori *objectRepositoryImpl) Quarantine(o *models.Object, reader io.Reader) exception.Exception {
+	return exception.Wrap(response.ExceptionUploadObject,
+		ori.minio.UploadObjectFromReader(constant.QuarantineBucketName, o.Path, reader, o.Size))
+}
+
+// ListAll 全部文件记录, 仅包含 id、路径及创建时间
+func (ori *objectRepositoryImpl) ListAll(db *gorm.DB) ([]models.Object, exception.Exception) {
+	objs := make([]models.Object, 0)
+	err := db.Model(&models.Object{}).Select("id", "path", "create_at").Order("id").Find(&objs).Error
+	return objs, exception.Wrap(response.ExceptionDatabase, err)
+}
+
+// unreferenced 文件 o 未被任何项目引用的条件, 引用范围同 Visible
+func unreferenced() string {
+	return fmt.Sprintf(`NOT EXISTS (SELECT 1 FROM %s WHERE upload_cad_id = o.id OR site_photo = o.id)
+AND NOT EXISTS (SELECT 1 FROM %s WHERE upload_cad_id = o.id OR site_photo = o.id)
+AND NOT EXISTS (SELECT 1 FROM %s WHERE upload_cad_id = o.id OR site_photo = o.id)
+AND NOT EXISTS (SELECT 1 FROM %s WHERE object_id = o.id OR thumbnail_id = o.id)
+AND NOT EXISTS (SELECT 1 FROM %s WHERE attachments @> jsonb_build_array(o.id::text))
+AND NOT EXISTS (SELECT 1 FROM %s WHERE object_id = o.id)`,
+		tables.Reserve, tables.ImplementGov, tables.ImplementIndustry,
+		tables.ProgressPhoto, tables.Contract, tables.ProjectAttachment)
+}
+
+// Unreferenced before 之前创建且未被任何项目引用的文件记录
+func (ori *objectRepositoryImpl) Unreferenced(db *gorm.DB, before time.Time) ([]models.Object, exception.Exception) {
+	sqlStr := fmt.Sprintf(`SELECT o.* FROM %s o WHERE o.create_at < ? AND %s ORDER BY o.id`, tables.Object, unreferenced())
+	objs := make([]models.Object, 0)
+	err := db.Raw(sqlStr, before).Scan(&objs).Error
+	return objs, exception.Wrap(response.ExceptionDatabase, err)
+}
+
+// ListContents 存储中的全部文件内容
+func (ori *objectRepositoryImpl) ListContents() ([]minio_sdk.Object, exception.Exception) {
+	objs, err := ori.minio.ListObjects(ori.bucket)
+	if err != nil {
+		return nil, exception.Wrap(response.ExceptionDownloadObject, err)
+	}
+	return objs, nil
+}
+
+// Delete 删除未被任何项目引用的文件记录, 返回删除的记录, 记录不存在或仍被引用时返回 nil.
+// 不删除存储内容, 调用方在事务提交后以 DeleteContent 删除; 未能删除的内容由对账任务清理
+func (ori *objectRepositoryImpl) Delete(db *gorm.DB, id string) (*models.Object, exception.Exception) {
+	sqlStr := fmt.Sprintf(`DELETE FROM %s o WHERE o.id = ? AND %s RETURNING o.*`, tables.Object, unreferenced())
+	objs := make([]models.Object, 0, 1)
+	if err := db.Raw(sqlStr, id).Scan(&objs).Error; err != nil {
+		return nil, exception.Wrap(response.ExceptionDatabase, err)
+	}
+	if len(objs) == 0 {
+		return nil, nil
 	}
-	return exception.Wrap(response.ExceptionDatabase, db.Where(models.Object{
-		ID: obj.ID,
-	}).Delete(models.Object{}).Error)
+	return &objs[0], nil
 }
 
 func (ori *objectRepositoryImpl) Upsert(db *gorm.DB, id string, o *models.Object) error {
@@ -0,0 +1,227 @@
+package repositories
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"lpms/app/response"
+	"lpms/app/vo"
+	"lpms/exception"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	"gorm.io/gorm"
+)
+
+// totalCountColumn 窗口函数统计的总数列
+const totalCountColumn = "lpms_total_count"
+
+// listCursor 游标内容: 排序签名及上一页最后一行的排序列值, 值均以字符串传给数据库
+type listCursor struct {
+	Order  string    `json:"o"`
+	Values []*string `json:"v"`
+}
+
+func encodeCursor(c *listCursor) string {
+	buf, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func decodeCursor(s string, order *listOrdering) (*listCursor, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	c := &listCursor{}
+	if err := json.Unmarshal(buf, c); err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	if c.Order != order.signature() || len(c.Values) != len(order.columns) {
+		return nil, errors.New("cursor does not match current sort")
+	}
+	return c, nil
+}
+
+// paginate 按排序分页查询 tx 到 dest(*[]T), 返回总数并回填 pageInfo.NextCursor.
+// 页码分页时总数由窗口函数与数据一次查出, 仅页码超出数据范围时另行统计;
+// 游标分页按排序列及 id 取游标之后的数据, 不按相关度排序, 仅 pageInfo.WithCount 时统计总数, 否则总数为 -1
+func paginate(tx *gorm.DB, pageInfo *vo.PageInfo, order *listOrdering, dest interface{}) (int64,
+	exception.Exception) {
+	base := tx.Session(&gorm.Session{})
+	keyset := pageInfo.Cursor != ""
+	count := int64(-1)
+	query := base.Clauses(order.clause(keyset))
+	if keyset {
+		cursor, err := decodeCursor(pageInfo.Cursor, order)
+		if err != nil {
+			return 0, exception.Wrap(response.ExceptionInvalidRequestParameters, err)
+		}
+		if pageInfo.WithCount {
+			if err := base.Count(&count).Error; err != nil {
+				return 0, exception.Wrap(response.ExceptionDatabase, err)
+			}
+		}
+		cond, vars := keysetCondition(order.columns, cursor.Values)
+		query = query.Where(cond, vars...)
+	} else {
+		query = query.Offset(pageInfo.Offset())
+	}
+	if pageInfo.PageSize > 0 {
+		// 多取一条判断是否有下一页
+		query = query.Limit(pageInfo.PageSize + 1)
+	}
+	query = query.Select(listSelects(base.Statement.Selects, order, !keyset))
+
+	total, err := scanWithTotal(query, dest, !keyset)
+	if err != nil {
+		return 0, exception.Wrap(response.ExceptionDatabase, err)
+	}
+	rows := reflect.ValueOf(dest).Elem()
+	if !keyset {
+		count = total
+		if rows.Len() == 0 && pageInfo.Offset() > 0 {
+			if err := base.Count(&count).Error; err != nil {
+				return 0, exception.Wrap(response.ExceptionDatabase, err)
+			}
+		}
+	}
+	pageInfo.NextCursor = ""
+	if pageInfo.PageSize > 0 && rows.Len() > pageInfo.PageSize {
+		rows.Set(rows.Slice(0, pageInfo.PageSize))
+		// 按相关度排序的结果无法用游标续查
+		if keyset || order.rank == "" {
+			cursor, err := newCursor(base, order, rows.Index(pageInfo.PageSize-1))
+			if err != nil {
+				return 0, exception.Wrap(response.ExceptionDatabase, err)
+			}
+			pageInfo.NextCursor = encodeCursor(cursor)
+		}
+	}
+	return count, nil
+}
+
+// listSelects 补齐查询列中缺少的排序列, withTotal 时追加窗口函数总数列
+func listSelects(selects []string, order *listOrdering, withTotal bool) []string {
+	result := append(make([]string, 0, len(selects)+len(order.columns)+1), selects...)
+	if len(selects) == 0 {
+		result = append(result, "*")
+	}
+	selected := make(map[string]bool)
+	for _, item := range result {
+		for _, column := range strings.Split(item, ",") {
+			selected[strings.ToLower(strings.TrimSpace(column))] = true
+		}
+	}
+	if !selected["*"] {
+		for _, column := range order.columns {
+			if !selected[column.name] {
+				result = append(result, column.name)
+				selected[column.name] = true
+			}
+		}
+	}
+	if withTotal {
+		result = append(result, "count(*) OVER() AS "+totalCountColumn)
+	}
+	return result
+}
+
+// keysetCondition 游标之后的数据: 前 i-1 列相等且第 i 列在游标值之后.
+// 空值排最后, 游标值为空时该列之后只有空值, 不再单独成为条件
+func keysetCondition(columns []orderColumn, values []*string) (string, []interface{}) {
+	ors := make([]string, 0, len(columns))
+	vars := make([]interface{}, 0)
+	equals := make([]string, 0, len(columns))
+	var equalVars []interface{}
+	for i, column := range columns {
+		if value := values[i]; value != nil {
+			op := " > ?"
+			if column.desc {
+				op = " < ?"
+			}
+			and := append(append([]string{}, equals...), fmt.Sprintf("(%s%s OR %s IS NULL)", column.name, op, column.name))
+			ors = append(ors, "("+strings.Join(and, " AND ")+")")
+			vars = append(append(vars, equalVars...), *value)
+			equals = append(equals, column.name+" = ?")
+			equalVars = append(equalVars, *value)
+		} else {
+			equals = append(equals, column.name+" IS NULL")
+		}
+	}
+	if len(ors) == 0 {
+		return "1 = 0", nil
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", vars
+}
+
+// scanWithTotal 查询到 dest, withTotal 时同时读取窗口函数总数列
+func scanWithTotal(tx *gorm.DB, dest interface{}, withTotal bool) (int64, error) {
+	if !withTotal {
+		return 0, tx.Scan(dest).Error
+	}
+	slice := reflect.ValueOf(dest).Elem()
+	rowType := reflect.StructOf([]reflect.StructField{
+		{Name: "Row", Type: slice.Type().Elem(), Tag: `gorm:"embedded"`},
+		{Name: "Total", Type: reflect.TypeOf(int64(0)), Tag: reflect.StructTag(`gorm:"column:` + totalCountColumn + `"`)},
+	})
+	rows := reflect.New(reflect.SliceOf(rowType))
+	if err := tx.Scan(rows.Interface()).Error; err != nil {
+		return 0, err
+	}
+	rows = rows.Elem()
+	total := int64(0)
+	data := reflect.MakeSlice(slice.Type(), 0, rows.Len())
+	for i := 0; i < rows.Len(); i++ {
+		data = reflect.Append(data, rows.Index(i).Field(0))
+		total = rows.Index(i).Field(1).Int()
+	}
+	slice.Set(data)
+	return total, nil
+}
+
+// newCursor 以 row 的排序列值生成游标
+func newCursor(db *gorm.DB, order *listOrdering, row reflect.Value) (*listCursor, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(row.Addr().Interface()); err != nil {
+		return nil, err
+	}
+	cursor := &listCursor{Order: order.signature(), Values: make([]*string, 0, len(order.columns))}
+	for _, column := range order.columns {
+		field := stmt.Schema.LookUpField(column.name)
+		if field == nil {
+			return nil, fmt.Errorf("sort column %s not found in %s", column.name, stmt.Schema.Name)
+		}
+		value, _ := field.ValueOf(context.Background(), row)
+		cursor.Values = append(cursor.Values, cursorValue(value))
+	}
+	return cursor, nil
+}
+
+// cursorValue 排序列值转为数据库可解析的字符串, 空值返回 nil
+func cursorValue(value interface{}) *string {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return nil
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	var s string
+	switch val := v.Interface().(type) {
+	case time.Time:
+		// 时间列均为不带时区的 timestamp, 按原值传回
+		s = val.Format("2006-01-02 15:04:05.999999")
+	case float32, float64:
+		s = strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	default:
+		s = fmt.Sprint(val)
+	}
+	return &s
+}
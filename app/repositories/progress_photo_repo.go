@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"lpms/app/models"
+	"lpms/app/models/tables"
+	"lpms/app/response"
+	"lpms/exception"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+var (
+	progressPhotoRepoInstance ProgressPhotoRepo
+	progressPhotoOnce         sync.Once
+)
+
+type ProgressPhotoRepoImpl struct{}
+
+func GetProgressPhotoRepo() ProgressPhotoRepo {
+	progressPhotoOnce.Do(func() {
+		progressPhotoRepoInstance = &ProgressPhotoRepoImpl{}
+	})
+	return progressPhotoRepoInstance
+}
+
+type ProgressPhotoRepo interface {
+	Create(db *gorm.DB, photo *models.ProgressPhoto) exception.Exception
+	Get(db *gorm.DB, id int64) (*models.ProgressPhoto, exception.Exception)
+	ListByProgressID(db *gorm.DB, progressID int64) ([]models.ProgressPhoto, exception.Exception)
+	ListByProjectID(db *gorm.DB, projectID int64, year int) ([]models.ProgressPhoto, exception.Exception)
+	Delete(db *gorm.DB, id int64) exception.Exception
+	DeleteByProjectID(db *gorm.DB, projectID ...int64) exception.Exception
+}
+
+func (pri *ProgressPhotoRepoImpl) Create(db *gorm.DB, photo *models.ProgressPhoto) exception.Exception {
+	return exception.Wrap(response.ExceptionDatabase, db.Create(photo).Error)
+}
+
+func (pri *ProgressPhotoRepoImpl) Get(db *gorm.DB, id int64) (*models.ProgressPhoto, exception.Exception) {
+	photo := models.ProgressPhoto{}
+	res := db.Where(&models.ProgressPhoto{ID: id}).Find(&photo)
+	if res.RowsAffected == 0 {
+		return nil, exception.New(response.ExceptionRecordNotFound, "recode not found")
+	}
+	if res.Error != nil {
+		return nil, exception.Wrap(response.ExceptionDatabase, res.Error)
+	}
+	return &photo, nil
+}
+
+func (pri *ProgressPhotoRepoImpl) ListByProgressID(db *gorm.DB, progressID int64) ([]models.ProgressPhoto, exception.Exception) {
+	data := make([]models.ProgressPhoto, 0)
+	tx := db.Table(tables.ProgressPhoto).Where("progress_id = ?", progressID).
+		Order("capture_at NULLS LAST").Order("id").Find(&data)
+	return data, exception.Wrap(response.ExceptionDatabase, tx.Error)
+}
+
+// 项目照片时间轴, 按期间倒序, 同月内按拍摄时间排序; year为0时不限年份
+func (pri *ProgressPhotoRepoImpl) ListByProjectID(db *gorm.DB, projectID int64, year int) ([]models.ProgressPhoto,
+	exception.Exception) {
+	data := make([]models.ProgressPhoto, 0)
+	tx := db.Table(tables.ProgressPhoto).Where("project_id = ?", projectID)
+	if year != 0 {
+		tx = tx.Where("year = ?", year)
+	}
+	tx = tx.Order("period DESC").Order("capture_at NULLS LAST").Order("id").Find(&data)
+	return data, exception.Wrap(response.ExceptionDatabase, tx.Error)
+}
+
+func (pri *ProgressPhotoRepoImpl) Delete(db *gorm.DB, id int64) exception.Exception {
+	return exception.Wrap(response.ExceptionDatabase, db.Delete(&models.ProgressPhoto{}, id).Error)
+}
+
+func (pri *ProgressPhotoRepoImpl) DeleteByProjectID(db *gorm.DB, projectID ...int64) exception.Exception {
+	return exception.Wrap(response.ExceptionDatabase, db.Where("project_id in (?)", projectID).Delete(&models.ProgressPhoto{}).Error)
+}
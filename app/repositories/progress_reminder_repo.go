@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"fmt"
+	"lpms/app/models"
+	"lpms/app/models/tables"
+	"lpms/app/response"
+	"lpms/app/vo"
+	"lpms/constant"
+	"lpms/exception"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	progressReminderRepoInstance ProgressReminderRepo
+	progressReminderOnce         sync.Once
+)
+
+type ProgressReminderRepoImpl struct{}
+
+func GetProgressReminderRepo() ProgressReminderRepo {
+	progressReminderOnce.Do(func() {
+		progressReminderRepoInstance = &ProgressReminderRepoImpl{}
+	})
+	return progressReminderRepoInstance
+}
+
+type ProgressReminderRepo interface {
+	Generate(db *gorm.DB, openID string, year, month int) (int64, exception.Exception)
+	Resolve(db *gorm.DB) (int64, exception.Exception)
+	List(db *gorm.DB, pageInfo *vo.PageInfo, params *vo.ProgressReminderFilterParam, isAdmin bool, user string) (int64,
+		[]models.ProgressReminder, exception.Exception)
+	ComplianceStat(db *gorm.DB, groupBy string, year, month int) ([]models.ComplianceStat, exception.Exception)
+	DeleteByProjectID(db *gorm.DB, projectID ...int64) exception.Exception
+}
+
+// 当月应填报项目: 当月底前已入实施库, 且当月初尚未竣工
+const complianceEligible = `g.create_at < @next AND (g.status <> @finished OR g.finish_time >= @begin)`
+
+// 当月已提交进度
+const complianceSubmitted = `EXISTS (SELECT 1 FROM ` + tables.GovProgress + ` p
+WHERE p.project_id = g.id AND p.period = @period AND p.status = 1)`
+
+func complianceArgs(year, month int) map[string]interface{} {
+	begin := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+	return map[string]interface{}{
+		"begin":    begin,
+		"next":     begin.AddDate(0, 1, 0),
+		"finished": constant.Finished,
+		"period":   models.Period(year, month),
+		"year":     year,
+		"month":    month,
+	}
+}
+
+// 为当月应填报而未提交进度的项目生成提醒, 已存在的提醒不重复生成
+func (pri *ProgressReminderRepoImpl) Generate(db *gorm.DB, openID string, year, month int) (int64, exception.Exception) {
+	args := complianceArgs(year, month)
+	args["user"] = openID
+	sqlStr := fmt.Sprintf(`INSERT INTO %s (create_at, update_at, create_by, update_by, project_id, year, month, period,
+project_name, project_code, duty_unit, township, receiver, status)
+SELECT now(), now(), @user, @user, g.id, @year, @month, @period, g.name, g.project_code, g.duty_unit, g.township, g.create_by, 0
+FROM %s g WHERE %s AND NOT %s
+ON CONFLICT (project_id, period) DO NOTHING`, tables.ProgressReminder, tables.ImplementGov, complianceEligible, complianceSubmitted)
+	res := db.Exec(sqlStr, args)
+	return res.RowsAffected, exception.Wrap(response.ExceptionDatabase, res.Error)
+}
+
+// 已补报的提醒标记为已处理
+func (pri *ProgressReminderRepoImpl) Resolve(db *gorm.DB) (int64, exception.Exception) {
+	sqlStr := fmt.Sprintf(`UPDATE %s r SET status = 1, resolved_at = p.update_at, update_at = now()
+FROM %s p WHERE p.project_id = r.project_id AND p.period = r.period AND p.status = 1 AND r.status = 0`,
+		tables.ProgressReminder, tables.GovProgress)
+	res := db.Exec(sqlStr)
+	return res.RowsAffected, exception.Wrap(response.ExceptionDatabase, res.Error)
+}
+
+func (pri *ProgressReminderRepoImpl) List(db *gorm.DB, pageInfo *vo.PageInfo, params *vo.ProgressReminderFilterParam,
+	isAdmin bool, user string) (int64, []models.ProgressReminder, exception.Exception) {
+	data := make([]models.ProgressReminder, 0)
+	tx := db.Table(tables.ProgressReminder)
+	if !isAdmin {
+		tx = tx.Where("receiver = ?", user)
+	}
+	if params.Year != nil {
+		tx = tx.Where("year = ?", params.Year)
+	}
+	if params.Month != nil {
+		tx = tx.Where("month = ?", params.Month)
+	}
+	if params.Status != nil {
+		tx = tx.Where("status = ?", params.Status)
+	}
+	if params.DutyUnit != "" {
+		tx = tx.Where("duty_unit = ?", params.DutyUnit)
+	}
+	if params.Township != "" {
+		tx = tx.Where("township = ?", params.Township)
+	}
+	count := int64(0)
+	tx = tx.Limit(pageInfo.PageSize).Offset(pageInfo.Offset()).Order("period DESC").Order("status ASC").Order("id ASC").
+		Scan(&data).Limit(-1).Offset(-1).Count(&count)
+	return count, data, exception.Wrap(response.ExceptionDatabase, tx.Error)
+}
+
+// 按责任单位或街镇统计当月填报率, groupBy 只允许 duty_unit / township
+func (pri *ProgressReminderRepoImpl) ComplianceStat(db *gorm.DB, groupBy string, year, month int) ([]models.ComplianceStat,
+	exception.Exception) {
+	if groupBy != "duty_unit" && groupBy != "township" {
+		return nil, exception.New(response.ExceptionInvalidRequestParameters, "invalid group by "+groupBy)
+	}
+	res := make([]models.ComplianceStat, 0)
+	sqlStr := fmt.Sprintf(`SELECT coalesce(nullif(g.%[1]s, ''), '未填写') AS name, count(*) AS total,
+count(*) FILTER (WHERE %[2]s) AS submitted
+FROM %[3]s g WHERE %[4]s GROUP BY 1 ORDER BY 1`, groupBy, complianceSubmitted, tables.ImplementGov, complianceEligible)
+	tx := db.Raw(sqlStr, complianceArgs(year, month)).Scan(&res)
+	return res, exception.Wrap(response.ExceptionDatabase, tx.Error)
+}
+
+func (pri *ProgressReminderRepoImpl) DeleteByProjectID(db *gorm.DB, projectID ...int64) exception.Exception {
+	return exception.Wrap(response.ExceptionDatabase, db.Where("project_id in (?)", projectID).Delete(&models.ProgressReminder{}).Error)
+}
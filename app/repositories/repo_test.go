@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder 记录 DryRun 生成的 SQL, 参数已内联
+type sqlRecorder struct {
+	logger.Interface
+	sqls []string
+}
+
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface { return r }
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	r.sqls = append(r.sqls, sql)
+}
+
+// dryRunDB 不连接数据库, 只生成 SQL 的 postgres 连接
+func dryRunDB(t *testing.T) (*gorm.DB, *sqlRecorder) {
+	t.Helper()
+	rec := &sqlRecorder{Interface: logger.Discard}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 user=lpms dbname=lpms"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 rec,
+	})
+	if err != nil {
+		t.Fatalf("open dry run db: %v", err)
+	}
+	return db, rec
+}
+
+// lastSQL 最后一条 SQL, 合并空白便于比较
+func (r *sqlRecorder) lastSQL(t *testing.T) string {
+	t.Helper()
+	if len(r.sqls) == 0 {
+		t.Fatal("no sql generated")
+	}
+	return strings.Join(strings.Fields(r.sqls[len(r.sqls)-1]), " ")
+}
+
+func expectSQL(t *testing.T, sql string, contains ...string) {
+	t.Helper()
+	for _, c := range contains {
+		if !strings.Contains(sql, c) {
+			t.Errorf("expected sql to contain %q\nsql: %s", c, sql)
+		}
+	}
+}
+
+func expectNoSQL(t *testing.T, sql string, excludes ...string) {
+	t.Helper()
+	for _, c := range excludes {
+		if strings.Contains(sql, c) {
+			t.Errorf("expected sql not to contain %q\nsql: %s", c, sql)
+		}
+	}
+}
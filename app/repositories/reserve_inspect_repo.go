@@ -40,25 +40,16 @@ func (rir *ReserveInspectRepoImpl) EarlyPlanList(db *gorm.DB, pageInfo *vo.PageI
 	data := make([]models.ReservePro, 0)
 	tx := db.Table(tables.Reserve).Select("id, name, level, project_type, construct_subject, create_at, status").
 		Where("status = ?", constant.EarlyPlan)
-	if params.Name != "" {
-		tx = tx.Where("name = ?", params.Name)
+	tx, ex := applyFilter(tx, "", params)
+	if ex != nil {
+		return 0, nil, ex
 	}
-	if params.Level != nil {
-		tx = tx.Where("level = ?", params.Level)
+	order, ex := listOrder(pageInfo, reserveSortFields, "")
+	if ex != nil {
+		return 0, nil, ex
 	}
-	if params.ProjectType != nil {
-		tx = tx.Where("project_type = ?", params.ProjectType)
-	}
-	if params.ConstructSubject != "" {
-		tx = tx.Where("construct_subject = ?", params.ConstructSubject)
-	}
-	if params.PlanBegin != "" && params.PlanEnd != "" {
-		tx = tx.Where("create_at <= ? and create_at >= ?", params.PlanEnd, params.PlanBegin)
-	}
-	count := int64(0)
-	tx = tx.Limit(pageInfo.PageSize).Offset(pageInfo.Offset()).
-		Scan(&data).Limit(-1).Offset(-1).Count(&count)
-	return count, data, exception.Wrap(response.ExceptionDatabase, tx.Error)
+	count, ex := paginate(tx, pageInfo, order, &data)
+	return count, data, ex
 }
 
 func (rir *ReserveInspectRepoImpl) OutStorageInspList(db *gorm.DB, pageInfo *vo.PageInfo,
@@ -66,25 +57,16 @@ func (rir *ReserveInspectRepoImpl) OutStorageInspList(db *gorm.DB, pageInfo *vo.
 	data := make([]models.ReservePro, 0)
 	tx := db.Table(tables.Reserve).Select("id, name, level, project_type, construct_subject, create_at, status").
 		Where("status = ?", constant.OutStorageInspect)
-	if params.Name != "" {
-		tx = tx.Where("name = ?", params.Name)
-	}
-	if params.Level != nil {
-		tx = tx.Where("level = ?", params.Level)
-	}
-	if params.ProjectType != nil {
-		tx = tx.Where("project_type = ?", params.ProjectType)
-	}
-	if params.ConstructSubject != "" {
-		tx = tx.Where("construct_subject = ?", params.ConstructSubject)
+	tx, ex := applyFilter(tx, "", params)
+	if ex != nil {
+		return 0, nil, ex
 	}
-	if params.PlanBegin != "" && params.PlanEnd != "" {
-		tx = tx.Where("create_at <= ? and create_at >= ?", params.PlanEnd, params.PlanBegin)
+	order, ex := listOrder(pageInfo, reserveSortFields, "")
+	if ex != nil {
+		return 0, nil, ex
 	}
-	count := int64(0)
-	tx = tx.Limit(pageInfo.PageSize).Offset(pageInfo.Offset()).
-		Scan(&data).Limit(-1).Offset(-1).Count(&count)
-	return count, data, exception.Wrap(response.ExceptionDatabase, tx.Error)
+	count, ex := paginate(tx, pageInfo, order, &data)
+	return count, data, ex
 }
 
 func (rir *ReserveInspectRepoImpl) Pass(db *gorm.DB, id int64, param map[string]interface{}) exception.Exception {
@@ -28,6 +28,8 @@ func GetReserveRepo() ReserveRepo {
 
 type ReserveRepo interface {
 	Create(db *gorm.DB, reserve *models.ReservePro) exception.Exception
+	MultiCreate(db *gorm.DB, reserves []*models.ReservePro) exception.Exception
+	ExistNames(db *gorm.DB, names []string) ([]string, exception.Exception)
 	Get(db *gorm.DB, id int64) (*models.ReservePro, exception.Exception)
 	List(db *gorm.DB, pageInfo *vo.PageInfo, params *vo.ReserveFilterParam, isAdmin bool, user string) (int64, []models.ReservePro, exception.Exception)
 	GetInvestDetail(db *gorm.DB, id int64) ([]models.InvestDetail, exception.Exception)
@@ -45,6 +47,26 @@ func (rri *ReserveRepoImpl) Create(db *gorm.DB, reserve *models.ReservePro) exce
 	return exception.Wrap(response.ExceptionDatabase, db.Create(reserve).Error)
 }
 
+func (rri *ReserveRepoImpl) MultiCreate(db *gorm.DB, reserves []*models.ReservePro) exception.Exception {
+	if len(reserves) == 0 {
+		return nil
+	}
+	return exception.Wrap(response.ExceptionDatabase, db.CreateInBatches(reserves, 100).Error)
+}
+
+// ExistNames 返回已存在的项目名称
+func (rri *ReserveRepoImpl) ExistNames(db *gorm.DB, names []string) ([]string, exception.Exception) {
+	res := make([]string, 0)
+	if len(names) == 0 {
+		return res, nil
+	}
+	err := db.Model(&models.ReservePro{}).Where("name IN ?", names).Distinct().Pluck("name", &res).Error
+	if err != nil {
+		return nil, exception.Wrap(response.ExceptionDatabase, err)
+	}
+	return res, nil
+}
+
 func (rri *ReserveRepoImpl) Get(db *gorm.DB, id int64) (*models.ReservePro, exception.Exception) {
 	reserve := models.ReservePro{}
 	res := db.Where(&models.ReservePro{ID: id}).Find(&reserve)
@@ -64,31 +86,17 @@ func (rri *ReserveRepoImpl) List(db *gorm.DB, pageInfo *vo.PageInfo, params *vo.
 	if !isAdmin {
 		tx = tx.Where("create_by = ?", user)
 	}
-	if params.Name != "" {
-		tx = tx.Where("name = ?", params.Name)
-	}
-	if params.Level != nil {
-		tx = tx.Where("level = ?", params.Level)
-	}
-	if params.ProjectType != nil {
-		tx = tx.Where("project_type = ?", params.ProjectType)
-	}
-	if params.ConstructSubject != "" {
-		tx = tx.Where("construct_subject = ?", params.ConstructSubject)
-	}
-	if params.PointType != nil {
-		tx = tx.Where("point_type = ?", params.PointType)
-	}
-	if params.PlanBegin != "" && params.PlanEnd != "" {
-		tx = tx.Where("create_at <= ? and create_at >= ?", params.PlanEnd, params.PlanBegin)
+	tx, ex := applyFilter(tx, "", params)
+	if ex != nil {
+		return 0, nil, ex
 	}
-	if params.Status != nil {
-		tx = tx.Where("status = ?", params.Status)
+	tx = keywordSearch(tx, tables.ReserveSearchDoc, pageInfo.Keywords)
+	order, ex := listOrder(pageInfo, reserveSortFields, tables.ReserveSearchDoc)
+	if ex != nil {
+		return 0, nil, ex
 	}
-	count := int64(0)
-	tx = tx.Limit(pageInfo.PageSize).Offset(pageInfo.Offset()).Order("id").
-		Scan(&data).Limit(-1).Offset(-1).Count(&count)
-	return count, data, exception.Wrap(response.ExceptionDatabase, tx.Error)
+	count, ex := paginate(tx, pageInfo, order, &data)
+	return count, data, ex
 }
 
 func (rri *ReserveRepoImpl) GetInvestDetail(db *gorm.DB, id int64) ([]models.InvestDetail, exception.Exception) {
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"lpms/app/models"
+	"lpms/app/response"
+	"lpms/exception"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+var (
+	savedViewRepoInstance SavedViewRepo
+	savedViewOnce         sync.Once
+)
+
+type SavedViewRepoImpl struct{}
+
+func GetSavedViewRepo() SavedViewRepo {
+	savedViewOnce.Do(func() {
+		savedViewRepoInstance = &SavedViewRepoImpl{}
+	})
+	return savedViewRepoInstance
+}
+
+type SavedViewRepo interface {
+	Create(db *gorm.DB, view *models.SavedView) exception.Exception
+	Get(db *gorm.DB, id int64) (*models.SavedView, exception.Exception)
+	List(db *gorm.DB, listType, user, unit string) ([]models.SavedView, exception.Exception)
+	ExistName(db *gorm.DB, user, listType, name string, excludeID int64) (bool, exception.Exception)
+	Update(db *gorm.DB, id int64, param map[string]interface{}) exception.Exception
+	Delete(db *gorm.DB, id int64) exception.Exception
+	ClearDefault(db *gorm.DB, user, listType string) exception.Exception
+}
+
+func (svr *SavedViewRepoImpl) Create(db *gorm.DB, view *models.SavedView) exception.Exception {
+	return exception.Wrap(response.ExceptionDatabase, db.Create(view).Error)
+}
+
+func (svr *SavedViewRepoImpl) Get(db *gorm.DB, id int64) (*models.SavedView, exception.Exception) {
+	view := models.SavedView{}
+	res := db.Where(&models.SavedView{ID: id}).Find(&view)
+	if res.RowsAffected == 0 {
+		return nil, exception.New(response.ExceptionRecordNotFound, "recode not found")
+	}
+	if res.Error != nil {
+		return nil, exception.Wrap(response.ExceptionDatabase, res.Error)
+	}
+	return &view, nil
+}
+
+// List 本人创建及本单位共享的视图, listType 为空时返回全部列表类型
+func (svr *SavedViewRepoImpl) List(db *gorm.DB, listType, user, unit string) ([]models.SavedView, exception.Exception) {
+	res := make([]models.SavedView, 0)
+	tx := db.Model(&models.SavedView{})
+	if unit != "" {
+		tx = tx.Where("create_by = ? or (shared = ? and unit = ?)", user, true, unit)
+	} else {
+		tx = tx.Where("create_by = ?", user)
+	}
+	if listType != "" {
+		tx = tx.Where("list_type = ?", listType)
+	}
+	tx = tx.Order("list_type ASC").Order("name ASC").Order("id ASC").Find(&res)
+	return res, exception.Wrap(response.ExceptionDatabase, tx.Error)
+}
+
+// ExistName 同一用户同一列表的视图名称不可重复
+func (svr *SavedViewRepoImpl) ExistName(db *gorm.DB, user, listType, name string, excludeID int64) (bool, exception.Exception) {
+	count := int64(0)
+	err := db.Model(&models.SavedView{}).Where("create_by = ? and list_type = ? and name = ? and id <> ?",
+		user, listType, name, excludeID).Count(&count).Error
+	if err != nil {
+		return false, exception.Wrap(response.ExceptionDatabase, err)
+	}
+	return count > 0, nil
+}
+
+func (svr *SavedViewRepoImpl) Update(db *gorm.DB, id int64, param map[string]interface{}) exception.Exception {
+	return exception.Wrap(response.ExceptionDatabase,
+		db.Model(&models.SavedView{}).Where(&models.SavedView{ID: id}).Updates(param).Error)
+}
+
+func (svr *SavedViewRepoImpl) Delete(db *gorm.DB, id int64) exception.Exception {
+	return exception.Wrap(response.ExceptionDatabase, db.Delete(&models.SavedView{}, id).Error)
+}
+
+// ClearDefault 取消用户该列表的默认视图
+func (svr *SavedViewRepoImpl) ClearDefault(db *gorm.DB, user, listType string) exception.Exception {
+	return exception.Wrap(response.ExceptionDatabase, db.Model(&models.SavedView{}).
+		Where("create_by = ? and list_type = ? and is_default = ?", user, listType, true).
+		Updates(map[string]interface{}{"is_default": false}).Error)
+}
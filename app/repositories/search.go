@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// maxSearchTerms 关键词最多拆分词数
+const maxSearchTerms = 5
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// searchTerms 关键词按空白拆分, 去重后最多保留 maxSearchTerms 个
+func searchTerms(keywords string) []string {
+	terms := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, term := range strings.Fields(keywords) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+		if len(terms) == maxSearchTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// keywordSearch 关键词检索, 每个词需在检索文本中出现(子串匹配)或与其中某段文字近似(pg_trgm 词相似度, 容错错别字),
+// 多个词之间为且的关系. 中文按字切分三元组, 无需分词; 数据库需使用 UTF-8 区域设置, 否则仅子串匹配生效
+func keywordSearch(tx *gorm.DB, doc, keywords string) *gorm.DB {
+	for _, term := range searchTerms(keywords) {
+		tx = tx.Where("(("+doc+") ILIKE ? OR ? <% ("+doc+"))", "%"+likeEscaper.Replace(term)+"%", term)
+	}
+	return tx
+}
+
+// searchRank 关键词相关度排序表达式: 名称包含的关键词越多越靠前, 其次按与检索文本的词相似度; 无关键词时为空
+func searchRank(doc, keywords string) (string, []interface{}) {
+	terms := searchTerms(keywords)
+	if doc == "" || len(terms) == 0 {
+		return "", nil
+	}
+	nameHits := make([]string, 0, len(terms))
+	vars := make([]interface{}, 0, len(terms)+1)
+	for _, term := range terms {
+		nameHits = append(nameHits, "(name ILIKE ?)::int")
+		vars = append(vars, "%"+likeEscaper.Replace(term)+"%")
+	}
+	vars = append(vars, strings.Join(terms, " "))
+	return strings.Join(nameHits, " + ") + " DESC, word_similarity(?, " + doc + ") DESC", vars
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"lpms/app/models"
+	"lpms/app/response"
+	"lpms/exception"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+var (
+	snapshotRepoInstance SnapshotRepo
+	snapshotOnce         sync.Once
+)
+
+type SnapshotRepoImpl struct{}
+
+func GetSnapshotRepo() SnapshotRepo {
+	snapshotOnce.Do(func() {
+		snapshotRepoInstance = &SnapshotRepoImpl{}
+	})
+	return snapshotRepoInstance
+}
+
+type SnapshotRepo interface {
+	Create(db *gorm.DB, snapshot *models.ReportSnapshot, projects []models.SnapshotProject) exception.Exception
+	Get(db *gorm.DB, id int64) (*models.ReportSnapshot, exception.Exception)
+	GetByPeriod(db *gorm.DB, year, month int) (*models.ReportSnapshot, exception.Exception)
+	List(db *gorm.DB) ([]models.ReportSnapshot, exception.Exception)
+	ListProjects(db *gorm.DB, snapshotID int64, isAdmin bool, user string) ([]models.SnapshotProject, exception.Exception)
+}
+
+// Create 写入快照及项目数据, 调用方负责事务
+func (sri *SnapshotRepoImpl) Create(db *gorm.DB, snapshot *models.ReportSnapshot, projects []models.SnapshotProject) exception.Exception {
+	if err := db.Create(snapshot).Error; err != nil {
+		return exception.Wrap(response.ExceptionDatabase, err)
+	}
+	if len(projects) == 0 {
+		return nil
+	}
+	for i := range projects {
+		projects[i].SnapshotID = snapshot.ID
+	}
+	return exception.Wrap(response.ExceptionDatabase, db.CreateInBatches(projects, 200).Error)
+}
+
+func (sri *SnapshotRepoImpl) Get(db *gorm.DB, id int64) (*models.ReportSnapshot, exception.Exception) {
+	snapshot := models.ReportSnapshot{}
+	res := db.Where(&models.ReportSnapshot{ID: id}).Find(&snapshot)
+	if res.RowsAffected == 0 {
+		return nil, exception.New(response.ExceptionRecordNotFound, "recode not found")
+	}
+	if res.Error != nil {
+		return nil, exception.Wrap(response.ExceptionDatabase, res.Error)
+	}
+	return &snapshot, nil
+}
+
+func (sri *SnapshotRepoImpl) GetByPeriod(db *gorm.DB, year, month int) (*models.ReportSnapshot, exception.Exception) {
+	snapshot := models.ReportSnapshot{}
+	res := db.Where("period = ?", models.Period(year, month)).Find(&snapshot)
+	if res.RowsAffected == 0 {
+		return nil, exception.New(response.ExceptionRecordNotFound, "recode not found")
+	}
+	if res.Error != nil {
+		return nil, exception.Wrap(response.ExceptionDatabase, res.Error)
+	}
+	return &snapshot, nil
+}
+
+func (sri *SnapshotRepoImpl) List(db *gorm.DB) ([]models.ReportSnapshot, exception.Exception) {
+	res := make([]models.ReportSnapshot, 0)
+	tx := db.Order("period DESC").Find(&res)
+	return res, exception.Wrap(response.ExceptionDatabase, tx.Error)
+}
+
+func (sri *SnapshotRepoImpl) ListProjects(db *gorm.DB, snapshotID int64, isAdmin bool, user string) ([]models.SnapshotProject,
+	exception.Exception) {
+	res := make([]models.SnapshotProject, 0)
+	tx := db.Where("snapshot_id = ?", snapshotID)
+	if !isAdmin {
+		tx = tx.Where("create_by = ?", user)
+	}
+	tx = tx.Order("source, project_code, project_id").Find(&res)
+	return res, exception.Wrap(response.ExceptionDatabase, tx.Error)
+}
@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"fmt"
+	"lpms/app/response"
+	"lpms/app/vo"
+	"lpms/constant"
+	"lpms/exception"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// sortAllowlist 可排序字段, 请求字段 -> 数据库列
+type sortAllowlist map[string]string
+
+var (
+	reserveSortFields = sortAllowlist{
+		"id":               "id",
+		"name":             "name",
+		"level":            "level",
+		"project_type":     "project_type",
+		"total_investment": "total_investment",
+		"plan_begin":       "plan_begin",
+		"create_at":        "create_at",
+		"status":           "status",
+	}
+	implementGovSortFields = sortAllowlist{
+		"id":               "id",
+		"name":             "name",
+		"type":             "type",
+		"project_code":     "project_code",
+		"level":            "level",
+		"project_type":     "project_type",
+		"total_investment": "total_investment",
+		"plan_begin":       "plan_begin",
+		"start_time":       "start_time",
+		"finish_time":      "finish_time",
+		"create_at":        "create_at",
+		"status":           "status",
+	}
+	implementIndustrySortFields = sortAllowlist{
+		"id":               "id",
+		"name":             "name",
+		"level":            "level",
+		"project_type":     "project_type",
+		"total_investment": "total_investment",
+		"plan_begin":       "plan_begin",
+		"start_time":       "start_time",
+		"finish_time":      "finish_time",
+		"create_at":        "create_at",
+		"status":           "status",
+	}
+)
+
+// listSortFields 保存视图的列表类型对应的可排序字段
+var listSortFields = map[string]sortAllowlist{
+	constant.ViewReserve:           reserveSortFields,
+	constant.ViewEarlyPlan:         reserveSortFields,
+	constant.ViewOutStorageInspect: reserveSortFields,
+	constant.ViewImplementGov:      implementGovSortFields,
+	constant.ViewImplementIndustry: implementIndustrySortFields,
+}
+
+// CheckListSort 校验列表类型的排序字段
+func CheckListSort(listType string, sorts []vo.SortField) exception.Exception {
+	allow := listSortFields[listType]
+	for _, sort := range sorts {
+		if _, ok := allow[sort.Field]; !ok {
+			return exception.New(response.ExceptionInvalidRequestParameters,
+				fmt.Sprintf("unsupported sort field %q", sort.Field))
+		}
+	}
+	return nil
+}
+
+// orderColumn 排序列, 空值均排最后
+type orderColumn struct {
+	name string
+	desc bool
+}
+
+func (c orderColumn) String() string {
+	if c.desc {
+		return c.name + " DESC NULLS LAST"
+	}
+	return c.name + " ASC NULLS LAST"
+}
+
+// listOrdering 列表排序: rank 为关键词相关度排序, 不为空时排在 columns 之前
+type listOrdering struct {
+	columns  []orderColumn
+	rank     string
+	rankVars []interface{}
+}
+
+// clause 排序子句, keyset 为 true 时不按相关度排序
+func (o *listOrdering) clause(keyset bool) clause.OrderBy {
+	orders := make([]string, 0, len(o.columns)+1)
+	var vars []interface{}
+	if o.rank != "" && !keyset {
+		orders = append(orders, o.rank)
+		vars = o.rankVars
+	}
+	for _, column := range o.columns {
+		orders = append(orders, column.String())
+	}
+	return clause.OrderBy{Expression: clause.Expr{SQL: strings.Join(orders, ", "), Vars: vars}}
+}
+
+// signature 排序签名, 用于校验游标与当前排序一致
+func (o *listOrdering) signature() string {
+	items := make([]string, 0, len(o.columns))
+	for _, column := range o.columns {
+		items = append(items, column.String())
+	}
+	return strings.Join(items, ",")
+}
+
+// listOrder 列表排序: 指定排序字段时按字段排序; 否则有关键词时按相关度, 再按 defaults 列升序排序.
+// 最后均以 id 排序, 保证分页结果稳定
+func listOrder(pageInfo *vo.PageInfo, allow sortAllowlist, doc string, defaults ...string) (*listOrdering,
+	exception.Exception) {
+	order := &listOrdering{columns: make([]orderColumn, 0, len(pageInfo.Sorts)+len(defaults)+1)}
+	hasID := false
+	if len(pageInfo.Sorts) > 0 {
+		for _, sort := range pageInfo.Sorts {
+			column, ok := allow[sort.Field]
+			if !ok {
+				return nil, exception.New(response.ExceptionInvalidRequestParameters,
+					fmt.Sprintf("unsupported sort field %q", sort.Field))
+			}
+			hasID = hasID || column == "id"
+			order.columns = append(order.columns, orderColumn{name: column, desc: sort.Desc})
+		}
+	} else {
+		order.rank, order.rankVars = searchRank(doc, pageInfo.Keywords)
+		for _, column := range defaults {
+			hasID = hasID || column == "id"
+			order.columns = append(order.columns, orderColumn{name: column})
+		}
+	}
+	if !hasID {
+		order.columns = append(order.columns, orderColumn{name: "id"})
+	}
+	return order, nil
+}
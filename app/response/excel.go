@@ -0,0 +1,33 @@
+package response
+
+import (
+	"log"
+	"lpms/commom/xlsx"
+
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/mvc"
+)
+
+const ContentXlsxHeaderValue = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// Excel 以附件形式输出 .xlsx, 文件名可为中文; 工作簿直接写入响应, StreamRows 的行在写出时生成
+func Excel(filename string, file *xlsx.File) mvc.Result {
+	return &excel{
+		filename: filename,
+		file:     file,
+	}
+}
+
+type excel struct {
+	filename string
+	file     *xlsx.File
+}
+
+func (e *excel) Dispatch(ctx *context.Context) {
+	ctx.ContentType(ContentXlsxHeaderValue)
+	ctx.Header(context.ContentDispositionHeaderKey, ContentDisposition(e.filename))
+	if err := e.file.Write(ctx.ResponseWriter()); err != nil {
+		// 响应已开始输出, 只能记录错误
+		log.Printf("write xlsx %s error: %s", e.filename, err.Error())
+	}
+}
@@ -57,4 +57,5 @@ var (
 	ExceptionParseStringToInt64Error  exception.Type = &Exception{code: 500025, statusCode: iris.StatusInternalServerError}
 	ExceptionHttpRequestError         exception.Type = &Exception{code: 500026, statusCode: iris.StatusInternalServerError}
 	ExceptionPraseIPLocationError     exception.Type = &Exception{code: 500027, statusCode: iris.StatusInternalServerError}
+	ExceptionScanFile                 exception.Type = &Exception{code: 500028, statusCode: iris.StatusInternalServerError}
 )
@@ -0,0 +1,58 @@
+package response
+
+import (
+	"fmt"
+	"lpms/app/vo"
+	"strings"
+
+	"github.com/kataras/iris/v12/context"
+	"github.com/kataras/iris/v12/mvc"
+)
+
+// Stream 以附件形式流式输出文件, 支持 Range 及 If-None-Match/If-Modified-Since 条件请求, 写出后关闭文件内容
+func Stream(obj *vo.ObjectResp) mvc.Result {
+	return &stream{obj: obj}
+}
+
+type stream struct {
+	obj *vo.ObjectResp
+}
+
+func (s *stream) Dispatch(ctx *context.Context) {
+	defer s.obj.Content.Close()
+	ctx.Header(context.ContentTypeHeaderKey, s.obj.ContentType)
+	ctx.Header(context.ContentDispositionHeaderKey, ContentDisposition(s.obj.Filename))
+	if s.obj.ETag != "" {
+		ctx.Header(context.ETagHeaderKey, fmt.Sprintf("%q", strings.Trim(s.obj.ETag, `"`)))
+	}
+	// 由 http.ServeContent 处理 Content-Length、Last-Modified、Range 及条件请求
+	ctx.ServeContent(s.obj.Content, s.obj.Filename, s.obj.ModTime)
+}
+
+// ContentDisposition 附件文件名按 RFC 5987 编码, 并提供仅含 ASCII 字符的 filename 兼容旧客户端
+func ContentDisposition(filename string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, filename)
+	var encoded strings.Builder
+	for _, b := range []byte(filename) {
+		if isAttrChar(b) {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return fmt.Sprintf("attachment; filename=\"%s\"; filename*=UTF-8''%s", fallback, encoded.String())
+}
+
+// isAttrChar RFC 5987 中无需编码的字符
+func isAttrChar(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+}
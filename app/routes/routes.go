@@ -23,9 +23,8 @@ func RegisterRoutes(app *iris.Application) {
 	authApp := app.Party("/auth")
 	mvc.New(authApp).Handle(auth.NewLoginHandler())
 
-	// objectApp := app.Party("/object")
-	// mvc.New(objectApp).Handle(v1.NewObjectHandler())
-	app.Get("/object/file/{id:string}", v1.NewObjectHandler().Get)
+	// 签名链接下载, 签名由 /api/v1/object/file/{id}/url 签发
+	app.Get("/object/file/{id:string}", v1.NewObjectHandler().SignedGet)
 
 	party := app.Party("/api/v1")
 	party.Use(middlewares.Auth().Serve)
@@ -34,18 +33,30 @@ func RegisterRoutes(app *iris.Application) {
 	reserveApp := mvc.New(reserveParty)
 	reserveApp.Handle(v1.NewReserveHandler())
 
-	// objectParty := party.Party("/object")
-	// objectApp := mvc.New(objectParty)
-	// objectApp.Handle(v1.NewObjectHandler())
+	objectParty := party.Party("/object")
+	objectApp := mvc.New(objectParty)
+	objectApp.Handle(v1.NewObjectHandler())
 
 	implementParty := party.Party("/implement")
 	implementApp := mvc.New(implementParty)
 	implementApp.Handle(v1.NewImplementGovHandler())
 	implementApp.Handle(v1.NewImpleIndustryHandler())
 	implementApp.Handle(v1.NewGovProgressHandler())
+	implementApp.Handle(v1.NewContractHandler())
+	implementApp.Handle(v1.NewProgressPhotoHandler())
+	implementApp.Handle(v1.NewProgressReminderHandler())
+	implementApp.Handle(v1.NewSnapshotHandler())
 
 	inspectParty := party.Party("/inspect")
 	inspectApp := mvc.New(inspectParty)
 	inspectApp.Handle(v1.NewReserveInspectHandler())
 	inspectApp.Handle(v1.NewWindowHandler())
+
+	viewParty := party.Party("/views")
+	viewApp := mvc.New(viewParty)
+	viewApp.Handle(v1.NewSavedViewHandler())
+
+	attachmentParty := party.Party("/attachments")
+	attachmentApp := mvc.New(attachmentParty)
+	attachmentApp.Handle(v1.NewAttachmentHandler())
 }
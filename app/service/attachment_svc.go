@@ -0,0 +1,208 @@
+package service
+
+import (
+	"lpms/app/repositories"
+	"lpms/app/response"
+	"lpms/app/vo"
+	"lpms/commom/drivers/database"
+	"lpms/constant"
+	"lpms/exception"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+var (
+	attachmentServiceInstance AttachmentService
+	attachmentOnce            sync.Once
+)
+
+type attachmentServiceImpl struct {
+	db       *gorm.DB
+	repo     repositories.AttachmentRepo
+	objRepo  repositories.ObjectRepo
+	userRepo repositories.UserRepo
+}
+
+func GetAttachmentService() AttachmentService {
+	attachmentOnce.Do(func() {
+		attachmentServiceInstance = &attachmentServiceImpl{
+			db:       database.GetDriver(),
+			repo:     repositories.GetAttachmentRepo(),
+			objRepo:  repositories.GetObjectRepo(),
+			userRepo: repositories.GetUserRepo(),
+		}
+	})
+	return attachmentServiceInstance
+}
+
+type AttachmentService interface {
+	List(user string, project *vo.AttachmentProject, category *int) ([]vo.AttachmentResp, exception.Exception)
+	Attach(openID string, param *vo.AttachmentReq) ([]vo.AttachmentResp, exception.Exception)
+	Detach(user string, id int64) exception.Exception
+	Sort(user string, param *vo.AttachmentSortReq) exception.Exception
+}
+
+// checkProject 项目创建者及管理员可查看、维护项目附件, 返回当前用户是否为管理员
+func (asi *attachmentServiceImpl) checkProject(user string, project *vo.AttachmentProject) (bool, exception.Exception) {
+	if err := project.Validate(); err != nil {
+		return false, exception.Wrap(response.ExceptionInvalidRequestParameters, err)
+	}
+	owner, ex := asi.repo.ProjectOwner(asi.db, project.Source, project.ProjectID)
+	if ex != nil {
+		return false, ex
+	}
+	userInfo, ex := asi.userRepo.Get(asi.db, user)
+	if ex != nil {
+		return false, ex
+	}
+	if owner != user && !userInfo.IsAdmin {
+		return false, exception.New(response.ExceptionForbidden, "当前操作无权限")
+	}
+	return userInfo.IsAdmin, nil
+}
+
+func (asi *attachmentServiceImpl) list(project *vo.AttachmentProject, category *int) ([]vo.AttachmentResp,
+	exception.Exception) {
+	attachments, ex := asi.repo.List(asi.db, project.Source, project.ProjectID, category)
+	if ex != nil {
+		return nil, ex
+	}
+	resp := make([]vo.AttachmentResp, 0, len(attachments))
+	for i := range attachments {
+		resp = append(resp, vo.NewAttachmentResponse(&attachments[i]))
+	}
+	return resp, nil
+}
+
+func (asi *attachmentServiceImpl) List(user string, project *vo.AttachmentProject, category *int) ([]vo.AttachmentResp,
+	exception.Exception) {
+	if _, ex := asi.checkProject(user, project); ex != nil {
+		return nil, ex
+	}
+	return asi.list(project, category)
+}
+
+// Attach 添加附件, 仅可添加本人上传的文件(管理员不限), 返回项目全部附件
+func (asi *attachmentServiceImpl) Attach(openID string, param *vo.AttachmentReq) ([]vo.AttachmentResp,
+	exception.Exception) {
+	if err := param.Validate(); err != nil {
+		return nil, exception.Wrap(response.ExceptionInvalidRequestParameters, err)
+	}
+	isAdmin, ex := asi.checkProject(openID, &param.AttachmentProject)
+	if ex != nil {
+		return nil, ex
+	}
+	existing, ex := asi.repo.List(asi.db, param.Source, param.ProjectID, nil)
+	if ex != nil {
+		return nil, ex
+	}
+	attached := make(map[string]bool, len(existing))
+	for _, a := range existing {
+		attached[a.ObjectID] = true
+	}
+	for _, id := range param.ObjectIDs {
+		if attached[id] {
+			return nil, exception.New(response.ExceptionInvalidRequestParameters, "文件已是该项目附件: "+id)
+		}
+		obj, ex := asi.objRepo.Get(asi.db, id)
+		if ex != nil {
+			if ex.Type() == response.ExceptionRecordNotFound {
+				return nil, exception.New(response.ExceptionInvalidRequestParameters, "文件不存在: "+id)
+			}
+			return nil, ex
+		}
+		if obj.CreateBy != openID && !isAdmin {
+			return nil, exception.New(response.ExceptionForbidden, "当前操作无权限")
+		}
+	}
+	tx := asi.db.Begin()
+	if tx.Error != nil {
+		return nil, exception.Wrap(response.ExceptionDatabase, tx.Error)
+	}
+	defer tx.Rollback()
+	maxSort, ex := asi.repo.MaxSort(tx, param.Source, param.ProjectID)
+	if ex != nil {
+		return nil, ex
+	}
+	if ex := asi.repo.Create(tx, param.ToModels(openID, maxSort+1)); ex != nil {
+		return nil, ex
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, exception.Wrap(response.ExceptionDatabase, err)
+	}
+	return asi.list(&param.AttachmentProject, nil)
+}
+
+// Detach 移除附件关联, 文件本身不删除
+func (asi *attachmentServiceImpl) Detach(user string, id int64) exception.Exception {
+	attachment, ex := asi.repo.Get(asi.db, id)
+	if ex != nil {
+		return ex
+	}
+	if _, ex := asi.checkProject(user, &vo.AttachmentProject{
+		Source:    attachment.Source,
+		ProjectID: attachment.ProjectID,
+	}); ex != nil {
+		return ex
+	}
+	return asi.repo.Delete(asi.db, id)
+}
+
+// Sort 按 ids 顺序重排, ids 须为项目的全部附件
+func (asi *attachmentServiceImpl) Sort(user string, param *vo.AttachmentSortReq) exception.Exception {
+	if _, ex := asi.checkProject(user, &param.AttachmentProject); ex != nil {
+		return ex
+	}
+	existing, ex := asi.repo.List(asi.db, param.Source, param.ProjectID, nil)
+	if ex != nil {
+		return ex
+	}
+	ids := make(map[int64]bool, len(existing))
+	for _, a := range existing {
+		ids[a.ID] = true
+	}
+	if len(param.IDs) != len(ids) {
+		return exception.New(response.ExceptionInvalidRequestParameters, "须包含该项目的全部附件")
+	}
+	for _, id := range param.IDs {
+		if !ids[id] {
+			return exception.New(response.ExceptionInvalidRequestParameters, "须包含该项目的全部附件且不能重复")
+		}
+		delete(ids, id)
+	}
+	tx := asi.db.Begin()
+	if tx.Error != nil {
+		return exception.Wrap(response.ExceptionDatabase, tx.Error)
+	}
+	defer tx.Rollback()
+	for i, id := range param.IDs {
+		if ex := asi.repo.UpdateSort(tx, id, i, user); ex != nil {
+			return ex
+		}
+	}
+	return exception.Wrap(response.ExceptionDatabase, tx.Commit().Error)
+}
+
+// syncLegacyAttachments 项目兼容字段 upload_cad_id、site_photo 写入的文件同步为附件(CAD图纸、现场照片),
+// 原引用的文件被替换或清空时移除其附件关联; old 为写入前的 cad、photo, 新建项目时为空
+func syncLegacyAttachments(db *gorm.DB, attachRepo repositories.AttachmentRepo, source string, projectID int64,
+	user string, old, cur [2]string) exception.Exception {
+	categories := [2]int{constant.AttachCad, constant.AttachSitePhoto}
+	for i, category := range categories {
+		if old[i] == cur[i] {
+			continue
+		}
+		if old[i] != "" {
+			if ex := attachRepo.DeleteLegacy(db, source, projectID, old[i], category); ex != nil {
+				return ex
+			}
+		}
+		if cur[i] != "" {
+			if ex := attachRepo.AddLegacy(db, source, projectID, cur[i], category, user); ex != nil {
+				return ex
+			}
+		}
+	}
+	return nil
+}
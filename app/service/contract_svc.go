@@ -0,0 +1,201 @@
+package service
+
+import (
+	"lpms/app/models"
+	"lpms/app/repositories"
+	"lpms/app/response"
+	"lpms/app/vo"
+	"lpms/commom/drivers/database"
+	"lpms/exception"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"gorm.io/gorm"
+)
+
+var (
+	contractServiceInstance ContractService
+	contractOnce            sync.Once
+)
+
+type contractServiceImpl struct {
+	db          *gorm.DB
+	repo        repositories.ContractRepo
+	projectRepo repositories.ImplementGovRepo
+	objRepo     repositories.ObjectRepo
+	userRepo    repositories.UserRepo
+}
+
+func GetContractService() ContractService {
+	contractOnce.Do(func() {
+		contractServiceInstance = &contractServiceImpl{
+			db:          database.GetDriver(),
+			repo:        repositories.GetContractRepo(),
+			projectRepo: repositories.GetImplementGovRepo(),
+			objRepo:     repositories.GetObjectRepo(),
+			userRepo:    repositories.GetUserRepo(),
+		}
+	})
+	return contractServiceInstance
+}
+
+type ContractService interface {
+	Create(openID string, param *vo.ContractReq) exception.Exception
+	Get(id int64) (*vo.ContractResp, exception.Exception)
+	List(params *vo.ContractFilterParam, pageInfo *vo.PageInfo) (*vo.DataPagination, exception.Exception)
+	Update(openID string, id int64, param *vo.ContractReq) exception.Exception
+	Delete(openID string, id int64) exception.Exception
+	ProjectTotal(projectID int64) (*vo.ContractTotalResp, exception.Exception)
+	CounterpartyStat(params *vo.ContractFilterParam) ([]vo.ContractCounterpartyResp, exception.Exception)
+}
+
+func checkContract(param *vo.ContractReq) exception.Exception {
+	if param.ContractNo == "" {
+		return exception.New(response.ExceptionMissingParameters, "缺少合同编号")
+	}
+	if param.Counterparty == "" {
+		return exception.New(response.ExceptionMissingParameters, "缺少合同相对方")
+	}
+	if param.Amount != nil && *param.Amount < 0 {
+		return exception.New(response.ExceptionInvalidRequestParameters, "合同金额不能为负数")
+	}
+	return nil
+}
+
+// contractAttachments 合同已引用的附件文件id
+func contractAttachments(contracts ...models.Contract) (map[string]bool, error) {
+	ids := make(map[string]bool)
+	for i := range contracts {
+		if len(contracts[i].Attachments) == 0 {
+			continue
+		}
+		var attachments []string
+		if err := json.Unmarshal(contracts[i].Attachments, &attachments); err != nil {
+			return nil, err
+		}
+		for _, id := range attachments {
+			ids[id] = true
+		}
+	}
+	return ids, nil
+}
+
+func (csi *contractServiceImpl) Create(openID string, param *vo.ContractReq) exception.Exception {
+	if ex := checkContract(param); ex != nil {
+		return ex
+	}
+	if _, ex := csi.projectRepo.Get(csi.db, param.ProjectID); ex != nil {
+		return ex
+	}
+	if ex := checkObjectRefs(csi.db, csi.objRepo, csi.userRepo, openID, nil, param.Attachments...); ex != nil {
+		return ex
+	}
+	contract, err := param.ToModel(openID)
+	if err != nil {
+		return exception.Wrap(response.ExceptionVo2Model, err)
+	}
+	if contract.SignDate != nil {
+		contract.Year = contract.SignDate.Year()
+		contract.Month = int(contract.SignDate.Month())
+	}
+	return csi.repo.Create(csi.db, []models.Contract{*contract})
+}
+
+func (csi *contractServiceImpl) Get(id int64) (*vo.ContractResp, exception.Exception) {
+	contract, ex := csi.repo.Get(csi.db, id)
+	if ex != nil {
+		return nil, ex
+	}
+	resp, err := vo.NewContractResponse(contract)
+	if err != nil {
+		return nil, exception.Wrap(response.ExceptionUnmarshalJSON, err)
+	}
+	return resp, nil
+}
+
+func (csi *contractServiceImpl) List(params *vo.ContractFilterParam, pageInfo *vo.PageInfo) (*vo.DataPagination,
+	exception.Exception) {
+	count, contracts, ex := csi.repo.List(csi.db, pageInfo, params)
+	if ex != nil {
+		return nil, ex
+	}
+	resp, err := vo.NewContractResponses(contracts)
+	if err != nil {
+		return nil, exception.Wrap(response.ExceptionUnmarshalJSON, err)
+	}
+	return vo.NewDataPagination(count, resp, pageInfo), nil
+}
+
+func (csi *contractServiceImpl) Update(openID string, id int64, param *vo.ContractReq) exception.Exception {
+	if ex := checkContract(param); ex != nil {
+		return ex
+	}
+	contract, ex := csi.repo.Get(csi.db, id)
+	if ex != nil {
+		return ex
+	}
+	if ex := checkGovProjectOwner(csi.db, csi.projectRepo, csi.userRepo, openID, contract.CreateBy,
+		contract.ProjectID); ex != nil {
+		return ex
+	}
+	kept, err := contractAttachments(*contract)
+	if err != nil {
+		return exception.Wrap(response.ExceptionUnmarshalJSON, err)
+	}
+	if ex := checkObjectRefs(csi.db, csi.objRepo, csi.userRepo, openID, kept, param.Attachments...); ex != nil {
+		return ex
+	}
+	values, err := param.ToMap(openID)
+	if err != nil {
+		return exception.Wrap(response.ExceptionVo2Map, err)
+	}
+	// 年月随签订日期重新计算, 清空签订日期时一并清空
+	values["year"], values["month"] = 0, 0
+	if param.SignDate != nil {
+		values["year"] = param.SignDate.Year()
+		values["month"] = int(param.SignDate.Month())
+	}
+	return csi.repo.Update(csi.db, id, values)
+}
+
+func (csi *contractServiceImpl) Delete(openID string, id int64) exception.Exception {
+	contract, ex := csi.repo.Get(csi.db, id)
+	if ex != nil {
+		return ex
+	}
+	if ex := checkGovProjectOwner(csi.db, csi.projectRepo, csi.userRepo, openID, contract.CreateBy,
+		contract.ProjectID); ex != nil {
+		return ex
+	}
+	return csi.repo.Delete(csi.db, id)
+}
+
+func (csi *contractServiceImpl) ProjectTotal(projectID int64) (*vo.ContractTotalResp, exception.Exception) {
+	res, ex := csi.repo.ProjectTotal(csi.db, projectID)
+	if ex != nil {
+		return nil, ex
+	}
+	resp := &vo.ContractTotalResp{ProjectID: projectID}
+	if len(res) != 0 {
+		resp.Count = res[0].Count
+		resp.Amount = res[0].Amount
+	}
+	return resp, nil
+}
+
+func (csi *contractServiceImpl) CounterpartyStat(params *vo.ContractFilterParam) ([]vo.ContractCounterpartyResp, exception.Exception) {
+	res, ex := csi.repo.CounterpartyStat(csi.db, params)
+	if ex != nil {
+		return nil, ex
+	}
+	resp := make([]vo.ContractCounterpartyResp, 0, len(res))
+	for i := range res {
+		resp = append(resp, vo.ContractCounterpartyResp{
+			Counterparty: res[i].Counterparty,
+			Count:        res[i].Count,
+			Amount:       res[i].Amount,
+			ProjectCount: res[i].ProjectCount,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"fmt"
+	"lpms/app/models"
+	"lpms/app/vo"
+	"lpms/commom/xlsx"
+	"lpms/constant"
+	"lpms/exception"
+	"time"
+)
+
+// exportBatchSize 导出时每批查询的行数
+const exportBatchSize = 500
+
+type exportColumn struct {
+	title string
+	width float64
+}
+
+// ExportFilename 导出文件名, eg: 储备库项目_20220101.xlsx
+func ExportFilename(name string) string {
+	return fmt.Sprintf("%s_%s.xlsx", name, time.Now().Format("20060102"))
+}
+
+func newExportSheet(file *xlsx.File, name string, columns []exportColumn) *xlsx.Sheet {
+	sheet := file.AddSheet(name)
+	sheet.FreezeRows = 1
+	header := make([]interface{}, 0, len(columns))
+	for i := range columns {
+		header = append(header, columns[i].title)
+		sheet.SetColWidth(i, columns[i].width)
+	}
+	sheet.AddStyledRow(xlsx.StyleHeader, header...)
+	return sheet
+}
+
+// exportFetch 查询 pageInfo 对应的一批导出行, seq 为该批首行序号
+type exportFetch func(pageInfo *vo.PageInfo, seq int) ([][]interface{}, exception.Exception)
+
+// streamExportRows 按游标分批查询导出行, 首批在调用时查询以便在响应前返回错误,
+// 其余批次在写出工作表时查询, 整表数据不驻留内存
+func streamExportRows(sheet *xlsx.Sheet, fetch exportFetch) exception.Exception {
+	pageInfo := &vo.PageInfo{Page: 1, PageSize: exportBatchSize}
+	rows, ex := fetch(pageInfo, 1)
+	if ex != nil {
+		return ex
+	}
+	sheet.StreamRows(func(add func(values ...interface{}) error) error {
+		seq := 1
+		for {
+			for _, r := range rows {
+				if err := add(r...); err != nil {
+					return err
+				}
+				seq++
+			}
+			if pageInfo.NextCursor == "" {
+				return nil
+			}
+			pageInfo = &vo.PageInfo{PageSize: exportBatchSize, Cursor: pageInfo.NextCursor}
+			if rows, ex = fetch(pageInfo, seq); ex != nil {
+				return ex
+			}
+		}
+	})
+	return nil
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(constant.DateFormat)
+}
+
+var reserveExportColumns = []exportColumn{
+	{"序号", 6}, {"项目名称", 30}, {"项目级别", 10}, {"项目类型", 12}, {"建设主体", 20}, {"建设地点", 24},
+	{"重点类型", 16}, {"实施类型", 10}, {"入库类别", 10}, {"计划开工时间", 14}, {"建设周期", 10},
+	{"总投资(万)", 14}, {"工程费用(万)", 14}, {"征迁/土地费用(万)", 16}, {"建设内容及规模", 40},
+	{"是否有用地情况", 10}, {"总用亩", 10}, {"新增建设用地", 12}, {"选址红线", 10},
+	{"前期工作联系人", 14}, {"联系人手机号", 14}, {"方案是否完成", 10}, {"可研编制", 10},
+	{"项目状态", 12}, {"创建时间", 20},
+}
+
+// exportReserve 导出储备库项目, list 按 pageInfo 分批查询
+func exportReserve(list func(pageInfo *vo.PageInfo) ([]models.ReservePro, exception.Exception)) (*xlsx.File,
+	exception.Exception) {
+	file := xlsx.NewFile()
+	sheet := newExportSheet(file, "储备库项目", reserveExportColumns)
+	ex := streamExportRows(sheet, func(pageInfo *vo.PageInfo, seq int) ([][]interface{}, exception.Exception) {
+		projects, ex := list(pageInfo)
+		if ex != nil {
+			return nil, ex
+		}
+		rows := make([][]interface{}, 0, len(projects))
+		for i := range projects {
+			p := &projects[i]
+			rows = append(rows, []interface{}{
+				seq + i, p.Name, vo.Label(vo.LevelNames, p.Level), vo.Label(vo.ProjectTypeNames, p.ProjectType),
+				p.ConstructSubject, p.ConstructSite, vo.Label(vo.PointTypeNames, p.PointType),
+				vo.Label(vo.ImplementTypeNames, p.ImplementType), vo.Label(vo.EnterDBTypeNames, p.EnterDBType),
+				formatDate(p.PlanBegin), p.Period, p.TotalInvestment, p.ProjectComsumption, p.MoveLandComsumption,
+				p.ConstructContentScope, p.IsLandUse, p.Total, p.Add, vo.Label(vo.SiteRedNames, p.SiteRed),
+				p.Contract, p.Phone, p.IsCaseFinish, vo.Label(vo.ResearchNames, p.IsResearch),
+				vo.Label(vo.ReserveStatusNames, &p.Status), p.CreateAt,
+			})
+		}
+		return rows, nil
+	})
+	if ex != nil {
+		return nil, ex
+	}
+	return file, nil
+}
+
+var implementGovExportColumns = []exportColumn{
+	{"序号", 6}, {"项目编码", 16}, {"项目名称", 30}, {"项目级别", 10}, {"项目类型", 12}, {"建设主体", 20},
+	{"建设地点", 24}, {"责任单位", 20}, {"所属街镇", 12}, {"重点类型", 16}, {"实施类型", 10}, {"入库类别", 10},
+	{"计划开工时间", 14}, {"实际开工时间", 14}, {"竣工时间", 14}, {"建设周期", 10},
+	{"总投资(万)", 14}, {"工程费用(万)", 14}, {"征迁/土地费用(万)", 16}, {"建设内容及规模", 40},
+	{"前期工作联系人", 14}, {"联系人手机号", 14}, {"项目状态", 12},
+}
+
+// exportImplementGov 导出政府投资项目, list 按 pageInfo 分批查询
+func exportImplementGov(list func(pageInfo *vo.PageInfo) ([]models.ImplementGov, exception.Exception)) (*xlsx.File,
+	exception.Exception) {
+	file := xlsx.NewFile()
+	sheet := newExportSheet(file, "政府投资项目", implementGovExportColumns)
+	ex := streamExportRows(sheet, func(pageInfo *vo.PageInfo, seq int) ([][]interface{}, exception.Exception) {
+		projects, ex := list(pageInfo)
+		if ex != nil {
+			return nil, ex
+		}
+		rows := make([][]interface{}, 0, len(projects))
+		for i := range projects {
+			p := &projects[i]
+			rows = append(rows, []interface{}{
+				seq + i, p.ProjectCode, p.Name, vo.Label(vo.LevelNames, p.Level), vo.Label(vo.ProjectTypeNames, p.ProjectType),
+				p.ConstructSubject, p.ConstructSite, p.DutyUint, p.Township, vo.Label(vo.PointTypeNames, p.PointType),
+				vo.Label(vo.ImplementTypeNames, p.ImplementType), vo.Label(vo.EnterDBTypeNames, p.EnterDBType),
+				formatDate(p.PlanBegin), formatDate(p.StartTime), formatDate(p.FinishTime), p.Period,
+				p.TotalInvestment, p.ProjectComsumption, p.MoveLandComsumption, p.ConstructContentScope,
+				p.Contract, p.Phone, vo.Label(vo.ImplementStatusNames, &p.Status),
+			})
+		}
+		return rows, nil
+	})
+	if ex != nil {
+		return nil, ex
+	}
+	return file, nil
+}
+
+var impleIndustryExportColumns = []exportColumn{
+	{"序号", 6}, {"项目名称", 30}, {"项目级别", 10}, {"项目类型", 12}, {"建设主体", 20}, {"建设地点", 24},
+	{"重点类型", 16}, {"实施类型", 10}, {"入库类别", 10}, {"计划开工时间", 14}, {"实际开工时间", 14},
+	{"竣工时间", 14}, {"建设周期", 10}, {"总投资(万)", 14}, {"工程费用(万)", 14}, {"征迁/土地费用(万)", 16},
+	{"建设内容及规模", 40}, {"前期工作联系人", 14}, {"联系人手机号", 14}, {"项目状态", 12},
+}
+
+// exportImpleIndustry 导出产业项目, list 按 pageInfo 分批查询
+func exportImpleIndustry(list func(pageInfo *vo.PageInfo) ([]models.ImpleIndustry, exception.Exception)) (*xlsx.File,
+	exception.Exception) {
+	file := xlsx.NewFile()
+	sheet := newExportSheet(file, "产业项目", impleIndustryExportColumns)
+	ex := streamExportRows(sheet, func(pageInfo *vo.PageInfo, seq int) ([][]interface{}, exception.Exception) {
+		projects, ex := list(pageInfo)
+		if ex != nil {
+			return nil, ex
+		}
+		rows := make([][]interface{}, 0, len(projects))
+		for i := range projects {
+			p := &projects[i]
+			rows = append(rows, []interface{}{
+				seq + i, p.Name, vo.Label(vo.LevelNames, p.Level), vo.Label(vo.ProjectTypeNames, p.ProjectType),
+				p.ConstructSubject, p.ConstructSite, vo.Label(vo.PointTypeNames, p.PointType),
+				vo.Label(vo.ImplementTypeNames, p.ImplementType), vo.Label(vo.EnterDBTypeNames, p.EnterDBType),
+				formatDate(p.PlanBegin), formatDate(p.StartTime), formatDate(p.FinishTime), p.Period,
+				p.TotalInvestment, p.ProjectComsumption, p.MoveLandComsumption, p.ConstructContentScope,
+				p.Contract, p.Phone, vo.Label(vo.ImplementStatusNames, &p.Status),
+			})
+		}
+		return rows, nil
+	})
+	if ex != nil {
+		return nil, ex
+	}
+	return file, nil
+}
@@ -1,12 +1,15 @@
 package service
 
 import (
+	"lpms/app/models"
 	"lpms/app/repositories"
 	"lpms/app/response"
 	"lpms/app/vo"
 	"lpms/commom/drivers/database"
+	"lpms/commom/xlsx"
 	"lpms/exception"
 	"sync"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -17,17 +20,27 @@ var (
 )
 
 type govProgressServiceImpl struct {
-	db          *gorm.DB
-	repo        repositories.GovProgressRepo
-	projectRepo repositories.ImplementGovRepo
+	db           *gorm.DB
+	repo         repositories.GovProgressRepo
+	projectRepo  repositories.ImplementGovRepo
+	contractRepo repositories.ContractRepo
+	photoRepo    repositories.ProgressPhotoRepo
+	objRepo      repositories.ObjectRepo
+	userRepo     repositories.UserRepo
+	snapshotRepo repositories.SnapshotRepo
 }
 
 func GetGovProgressService() GovProgressService {
 	govProgressOnce.Do(func() {
 		govProgressServiceInstance = &govProgressServiceImpl{
-			db:          database.GetDriver(),
-			repo:        repositories.GetGovProgressRepo(),
-			projectRepo: repositories.GetImplementGovRepo(),
+			db:           database.GetDriver(),
+			repo:         repositories.GetGovProgressRepo(),
+			projectRepo:  repositories.GetImplementGovRepo(),
+			contractRepo: repositories.GetContractRepo(),
+			photoRepo:    repositories.GetProgressPhotoRepo(),
+			objRepo:      repositories.GetObjectRepo(),
+			userRepo:     repositories.GetUserRepo(),
+			snapshotRepo: repositories.GetSnapshotRepo(),
 		}
 	})
 	return govProgressServiceInstance
@@ -39,6 +52,7 @@ type GovProgressService interface {
 	Update(openID string, id int64, param *vo.GovProgressUpdateReq) exception.Exception
 	ListPlan(projectID int64, year int) ([]vo.ListGovProgressPlan, exception.Exception)
 	ListGovProgressCompare(projectID int64, year int) ([]vo.GovProgressCompare, exception.Exception)
+	Report(user string, year, month int, snapshot bool) (*xlsx.File, exception.Exception)
 }
 
 func (gsi *govProgressServiceImpl) Create(openID string, param *vo.GovProgressReq) exception.Exception {
@@ -55,57 +69,86 @@ func (gsi *govProgressServiceImpl) Get(id int64, year, month int) (*vo.GovProgre
 			return nil, ex
 		}
 	}
-	govProject, ex := gsi.projectRepo.Get(gsi.db, govProgress.ProjectID)
+	sums, ex := gsi.repo.InvestmentSum(gsi.db, year, month, govProgress.ProjectID)
 	if ex != nil {
 		return nil, ex
 	}
-	resp := &vo.GovProgressResp{}
-	var err error
-	if govProgress == nil {
-		return resp, nil
+	sum := models.InvestmentSum{ProjectID: govProgress.ProjectID}
+	if len(sums) != 0 {
+		sum = sums[0]
 	}
-	info, ex := gsi.repo.ListInvested(gsi.db, id, year)
+	// 当年累计为全年合计, 与一月至本月累计分开查询
+	yearSums, ex := gsi.repo.InvestmentSum(gsi.db, year, 12, govProgress.ProjectID)
 	if ex != nil {
 		return nil, ex
 	}
-	investd := float64(0)
-	fixInvested := float64(0)
-	for i := range info {
-		if info[i].PlanInvested != nil {
-			investd += *info[i].PlanInvested
-		}
-		if info[i].LastMonthFixedInvested != nil {
-			fixInvested += *info[i].LastMonthFixedInvested
-		}
+	yearSum := models.InvestmentSum{ProjectID: govProgress.ProjectID}
+	if len(yearSums) != 0 {
+		yearSum = yearSums[0]
 	}
-	govProgress.YearSumFixedInvested = &fixInvested
-	govProgress.YearSumInvested = &investd
-	total_plan_invested, ex := gsi.repo.FormNowInvested(gsi.db, govProgress.ProjectID, year, month)
+	govProgress.YearSumInvested = &yearSum.YearInvested
+	govProgress.YearSumFixedInvested = &yearSum.YearFixedInvested
+	contracts, ex := gsi.contractRepo.ListByProgressID(gsi.db, govProgress.ID)
 	if ex != nil {
 		return nil, ex
 	}
-	fromNowInvested := float64(0)
-	fromNowFixedSum := float64(0)
-	startYear := int(0)
-	startMonth := int(0)
-	if govProject.StartTime != nil {
-		startYear = govProject.StartTime.Year()
-		startMonth = int(govProject.StartTime.Month())
-		var exx exception.Exception
-		fromNowInvested, fromNowFixedSum, exx = gsi.repo.StartFormNowInvestedAndFixed(gsi.db, govProgress.ProjectID, startYear, startMonth, year, month)
-		if exx != nil {
-			return nil, ex
-		}
+	photos, ex := gsi.photoRepo.ListByProgressID(gsi.db, govProgress.ID)
+	if ex != nil {
+		return nil, ex
 	}
-	resp, err = vo.NewGovProgressResponse(govProgress, total_plan_invested, fromNowInvested, fromNowFixedSum)
+	resp, err := vo.NewGovProgressResponse(govProgress, contracts, sum.YearInvested, sum.StartInvested, sum.StartFixedInvested)
 	if err != nil {
 		return nil, exception.Wrap(response.ExceptionUnmarshalJSON, err)
 	}
+	resp.Photos = vo.NewProgressPhotoResponses(photos)
 	return resp, nil
 }
 
 func (gsi *govProgressServiceImpl) Update(openID string, id int64, param *vo.GovProgressUpdateReq) exception.Exception {
-	return gsi.repo.Update(gsi.db, id, param.ToMap(openID))
+	progress, ex := gsi.repo.GetByID(gsi.db, id)
+	if ex != nil {
+		return ex
+	}
+	existing, ex := gsi.contractRepo.ListByProgressID(gsi.db, id)
+	if ex != nil {
+		return ex
+	}
+	kept, err := contractAttachments(existing...)
+	if err != nil {
+		return exception.Wrap(response.ExceptionUnmarshalJSON, err)
+	}
+	for i := range param.Contracts {
+		if ex := checkContract(&param.Contracts[i]); ex != nil {
+			return ex
+		}
+		if ex := checkObjectRefs(gsi.db, gsi.objRepo, gsi.userRepo, openID, kept,
+			param.Contracts[i].Attachments...); ex != nil {
+			return ex
+		}
+	}
+	contracts, err := param.ToContracts(openID, progress)
+	if err != nil {
+		return exception.Wrap(response.ExceptionVo2Model, err)
+	}
+	tx := gsi.db.Begin()
+	if tx.Error != nil {
+		return exception.Wrap(response.ExceptionDatabase, tx.Error)
+	}
+	defer tx.Rollback()
+	if ex := gsi.repo.Update(tx, id, param.ToMap(openID)); ex != nil {
+		return ex
+	}
+	// 本月合同以最新一次填报为准
+	if ex := gsi.contractRepo.DeleteByProgressID(tx, id); ex != nil {
+		return ex
+	}
+	if ex := gsi.contractRepo.Create(tx, contracts); ex != nil {
+		return ex
+	}
+	if err := tx.Commit().Error; err != nil {
+		return exception.Wrap(response.ExceptionDatabase, err)
+	}
+	return nil
 }
 
 func (gsi *govProgressServiceImpl) ListPlan(projectID int64, year int) ([]vo.ListGovProgressPlan, exception.Exception) {
@@ -148,3 +191,123 @@ func (gsi *govProgressServiceImpl) ListGovProgressCompare(projectID int64, year
 	}
 	return resp, nil
 }
+
+// Report 月度进展情况表, 包含当月在建(当月及以前创建且未在当月之前竣工)的全部政府投资项目
+// snapshot 为 true 时使用该月月末快照数据
+func (gsi *govProgressServiceImpl) Report(user string, year, month int, snapshot bool) (*xlsx.File, exception.Exception) {
+	if year <= 0 || month < 1 || month > 12 {
+		return nil, exception.New(response.ExceptionInvalidRequestParameters, "invalid year or month")
+	}
+	userInfo, ex := gsi.userRepo.Get(gsi.db, user)
+	if ex != nil {
+		return nil, ex
+	}
+	if snapshot {
+		return gsi.snapshotReport(user, userInfo.IsAdmin, year, month)
+	}
+	projects, ex := gsi.projectRepo.ListReconcile(gsi.db, &vo.ReconcileFilterParam{Year: year}, userInfo.IsAdmin, user)
+	if ex != nil {
+		return nil, ex
+	}
+	monthBegin := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+	monthEnd := monthBegin.AddDate(0, 1, 0)
+	rows := make([]progressReportRow, 0, len(projects))
+	ids := make([]int64, 0, len(projects))
+	for i := range projects {
+		p := &projects[i]
+		if !activeInMonth(p.CreateAt, p.FinishTime, monthBegin, monthEnd) {
+			continue
+		}
+		details, _ := vo.ParseInvestmentDetail(p.InvestmentDetail)
+		yearPlan := float64(0)
+		for _, plan := range vo.PlanBySource(details, year) {
+			yearPlan += plan
+		}
+		rows = append(rows, progressReportRow{project: p, sum: models.InvestmentSum{ProjectID: p.ID}, yearPlan: yearPlan})
+		ids = append(ids, p.ID)
+	}
+	sums, ex := gsi.repo.InvestmentSum(gsi.db, year, month, ids...)
+	if ex != nil {
+		return nil, ex
+	}
+	progresses, ex := gsi.repo.ListByMonth(gsi.db, year, month, ids...)
+	if ex != nil {
+		return nil, ex
+	}
+	sumMap := make(map[int64]models.InvestmentSum, len(sums))
+	for i := range sums {
+		sumMap[sums[i].ProjectID] = sums[i]
+	}
+	progressMap := make(map[int64]*models.GovProgress, len(progresses))
+	for i := range progresses {
+		progressMap[progresses[i].ProjectID] = &progresses[i]
+	}
+	for i := range rows {
+		id := rows[i].project.ID
+		if sum, ok := sumMap[id]; ok {
+			rows[i].sum = sum
+		}
+		rows[i].progress = progressMap[id]
+	}
+	return exportProgressReport(year, month, rows), nil
+}
+
+func (gsi *govProgressServiceImpl) snapshotReport(user string, isAdmin bool, year, month int) (*xlsx.File,
+	exception.Exception) {
+	snapshot, ex := gsi.snapshotRepo.GetByPeriod(gsi.db, year, month)
+	if ex != nil {
+		return nil, ex
+	}
+	projects, ex := gsi.snapshotRepo.ListProjects(gsi.db, snapshot.ID, isAdmin, user)
+	if ex != nil {
+		return nil, ex
+	}
+	monthBegin := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+	monthEnd := monthBegin.AddDate(0, 1, 0)
+	rows := make([]progressReportRow, 0, len(projects))
+	for i := range projects {
+		p := &projects[i]
+		if p.Source != models.SnapshotSourceGov || !activeInMonth(p.ProjectCreateAt, p.FinishTime, monthBegin, monthEnd) {
+			continue
+		}
+		rows = append(rows, snapshotReportRow(p))
+	}
+	return exportProgressReport(year, month, rows), nil
+}
+
+// activeInMonth 当月及以前创建且未在当月之前竣工
+func activeInMonth(createAt time.Time, finishTime *time.Time, monthBegin, monthEnd time.Time) bool {
+	return createAt.Before(monthEnd) && (finishTime == nil || !finishTime.Before(monthBegin))
+}
+
+func snapshotReportRow(p *models.SnapshotProject) progressReportRow {
+	row := progressReportRow{
+		project: &models.ImplementGov{
+			ID:              p.ProjectID,
+			Name:            p.Name,
+			ProjectCode:     p.ProjectCode,
+			Township:        p.Township,
+			DutyUint:        p.DutyUnit,
+			ProjectType:     p.ProjectType,
+			TotalInvestment: p.TotalInvestment,
+		},
+		sum: models.InvestmentSum{
+			ProjectID:     p.ProjectID,
+			YearInvested:  p.YearInvested,
+			StartInvested: p.StartInvested,
+		},
+		yearPlan: p.YearPlan,
+	}
+	if p.ProgressStatus != nil {
+		row.progress = &models.GovProgress{
+			ProjectID:      p.ProjectID,
+			Status:         *p.ProgressStatus,
+			PlanInvest:     p.MonthPlan,
+			PlanInvested:   p.MonthInvested,
+			PlanProgress:   p.PlanProgress,
+			ActualProgress: p.ActualProgress,
+			ProblemDetail:  p.ProblemDetail,
+		}
+	}
+	return row
+}
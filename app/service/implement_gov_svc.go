@@ -1,10 +1,13 @@
 package service
 
 import (
+	"lpms/app/models"
 	"lpms/app/repositories"
 	"lpms/app/response"
 	"lpms/app/vo"
 	"lpms/commom/drivers/database"
+	"lpms/commom/xlsx"
+	"lpms/constant"
 	"lpms/exception"
 	"strconv"
 	"strings"
@@ -25,6 +28,11 @@ type implementGovServiceImpl struct {
 	objRepo        repositories.ObjectRepo
 	GovProcessRepo repositories.GovProgressRepo
 	userRepo       repositories.UserRepo
+	contractRepo   repositories.ContractRepo
+	photoRepo      repositories.ProgressPhotoRepo
+	reminderRepo   repositories.ProgressReminderRepo
+	snapshotRepo   repositories.SnapshotRepo
+	attachRepo     repositories.AttachmentRepo
 }
 
 func GetImplementGovService() ImplementGovService {
@@ -35,6 +43,11 @@ func GetImplementGovService() ImplementGovService {
 			objRepo:        repositories.GetObjectRepo(),
 			GovProcessRepo: repositories.GetGovProgressRepo(),
 			userRepo:       repositories.GetUserRepo(),
+			contractRepo:   repositories.GetContractRepo(),
+			photoRepo:      repositories.GetProgressPhotoRepo(),
+			reminderRepo:   repositories.GetProgressReminderRepo(),
+			snapshotRepo:   repositories.GetSnapshotRepo(),
+			attachRepo:     repositories.GetAttachmentRepo(),
 		}
 	})
 	return implementGovServiceInstance
@@ -44,12 +57,25 @@ type ImplementGovService interface {
 	Create(openID string, param *vo.ImplementGovReq) exception.Exception
 	Get(id int64) (*vo.ImplementGovResp, exception.Exception)
 	List(user string, params *vo.ImplementGovFilterParam, pageInfo *vo.PageInfo) (*vo.DataPagination, exception.Exception)
+	Export(user string, params *vo.ImplementGovFilterParam) (*xlsx.File, exception.Exception)
 	Delete(id int64) exception.Exception
 	MultiDelete(ids string) exception.Exception
 	ListStatusCount(user string, params *vo.ImplementGovCountFilter) ([]vo.StatusCountResp, exception.Exception)
+	PortfolioStat(user string, params *vo.PortfolioStatFilter) (*vo.PortfolioStatResp, exception.Exception)
+	DataAnalysis(user string, params *vo.ImplementAnalysisFilter) ([]vo.ImplementAnalysisResp, exception.Exception)
+	Reconcile(user string, params *vo.ReconcileFilterParam) ([]vo.ReconcileResp, exception.Exception)
+	MapProjects(user string, params *vo.MapFilterParam) (*vo.GeoFeatureCollection, exception.Exception)
+	MapTownships(user string, params *vo.MapFilterParam) (*vo.GeoFeatureCollection, exception.Exception)
 }
 
 func (isi *implementGovServiceImpl) Create(openID string, param *vo.ImplementGovReq) exception.Exception {
+	if errs := param.ProjectGeo.Validate(); len(errs) > 0 {
+		return exception.Wrap(response.ExceptionInvalidRequestParameters, errs[0])
+	}
+	if ex := checkObjectRefs(isi.db, isi.objRepo, isi.userRepo, openID, nil,
+		param.UploadCadID, param.SitePhoto); ex != nil {
+		return ex
+	}
 	tx := isi.db.Begin()
 	if tx.Error != nil {
 		return exception.Wrap(response.ExceptionDatabase, tx.Error)
@@ -63,6 +89,10 @@ func (isi *implementGovServiceImpl) Create(openID string, param *vo.ImplementGov
 	if ex := isi.GovProcessRepo.BetchCreate(tx, progress); ex != nil {
 		return ex
 	}
+	if ex := syncLegacyAttachments(tx, isi.attachRepo, constant.AttachGov, res.ID, openID,
+		[2]string{}, [2]string{res.UploadCadID, res.SitePhoto}); ex != nil {
+		return ex
+	}
 	if ex := tx.Commit().Error; ex != nil {
 		return exception.Wrap(response.ExceptionDatabase, ex)
 	}
@@ -114,6 +144,7 @@ func (isi *implementGovServiceImpl) List(user string, params *vo.ImplementGovFil
 			StartTime:        projects[i].StartTime,
 			ProjectCode:      projects[i].ProjectCode,
 			DutyUnit:         projects[i].DutyUint,
+			Township:         projects[i].Township,
 			Type:             projects[i].Type,
 			TotalInvestment:  projects[i].TotalInvestment,
 			Progress:         light,
@@ -133,26 +164,37 @@ func (isi *implementGovServiceImpl) Delete(id int64) exception.Exception {
 	}
 
 	defer tx.Rollback()
-	if pro.SitePhoto != "" {
-		if exx := isi.objRepo.Delete(tx, pro.SitePhoto); exx != nil {
-			return exx
-		}
+	photos, ex := isi.photoRepo.ListByProjectID(tx, id, 0)
+	if ex != nil {
+		return ex
 	}
-	if pro.UploadCadID != "" {
-		if exx := isi.objRepo.Delete(tx, pro.UploadCadID); exx != nil {
-			return exx
-		}
+	if ex := isi.photoRepo.DeleteByProjectID(tx, id); ex != nil {
+		return ex
+	}
+	if ex := isi.reminderRepo.DeleteByProjectID(tx, id); ex != nil {
+		return ex
+	}
+	if ex := isi.contractRepo.DeleteByProjectID(tx, id); ex != nil {
+		return ex
 	}
-
 	if ex := isi.GovProcessRepo.DeleteByProjectID(tx, id); ex != nil {
 		return ex
 	}
-	if ex != isi.repo.Delete(tx, id) {
+	if ex := isi.attachRepo.DeleteByProject(tx, constant.AttachGov, id); ex != nil {
+		return ex
+	}
+	if ex := isi.repo.Delete(tx, id); ex != nil {
+		return ex
+	}
+	// 出库时文件ID随储备库项目复制, 仍被引用的文件不删除
+	objs, ex := deleteObjects(tx, isi.objRepo, append(progressPhotoObjectIDs(photos...), pro.SitePhoto, pro.UploadCadID)...)
+	if ex != nil {
 		return ex
 	}
 	if err := tx.Commit(); err.Error != nil {
 		return exception.Wrap(response.ExceptionDatabase, err.Error)
 	}
+	deleteObjectContents(isi.objRepo, objs)
 	return nil
 }
 
@@ -174,31 +216,45 @@ func (isi *implementGovServiceImpl) MultiDelete(ids string) exception.Exception
 		return exception.Wrap(response.ExceptionDatabase, tx.Error)
 	}
 
+	defer tx.Rollback()
+	objIDs := make([]string, 0)
 	for i := range did {
 		pro, ex := isi.repo.Get(tx, did[i])
 		if ex != nil {
 			return ex
 		}
-		if pro.SitePhoto != "" {
-			if exx := isi.objRepo.Delete(tx, pro.SitePhoto); exx != nil {
-				return exx
-			}
-		}
-		if pro.UploadCadID != "" {
-			if exx := isi.objRepo.Delete(tx, pro.UploadCadID); exx != nil {
-				return exx
-			}
+		photos, ex := isi.photoRepo.ListByProjectID(tx, did[i], 0)
+		if ex != nil {
+			return ex
 		}
+		objIDs = append(append(objIDs, pro.SitePhoto, pro.UploadCadID), progressPhotoObjectIDs(photos...)...)
+	}
+	if ex := isi.photoRepo.DeleteByProjectID(tx, did...); ex != nil {
+		return ex
+	}
+	if ex := isi.reminderRepo.DeleteByProjectID(tx, did...); ex != nil {
+		return ex
+	}
+	if ex := isi.contractRepo.DeleteByProjectID(tx, did...); ex != nil {
+		return ex
 	}
 	if ex := isi.GovProcessRepo.DeleteByProjectID(tx, did...); ex != nil {
 		return ex
 	}
-	if ex := isi.repo.MultiDelete(isi.db, did); ex != nil {
+	if ex := isi.attachRepo.DeleteByProject(tx, constant.AttachGov, did...); ex != nil {
+		return ex
+	}
+	if ex := isi.repo.MultiDelete(tx, did); ex != nil {
+		return ex
+	}
+	objs, ex := deleteObjects(tx, isi.objRepo, objIDs...)
+	if ex != nil {
 		return ex
 	}
 	if err := tx.Commit(); err.Error != nil {
 		return exception.Wrap(response.ExceptionDatabase, err.Error)
 	}
+	deleteObjectContents(isi.objRepo, objs)
 	return nil
 }
 
@@ -220,3 +276,319 @@ func (isi *implementGovServiceImpl) ListStatusCount(user string, params *vo.Impl
 	}
 	return resp, nil
 }
+
+// 默认滞后阈值: 时间进度超过资金完成率30%
+const defaultLagThreshold = 0.3
+
+// timeProgress 某年已过月份占比
+// checkGovProjectOwner 记录的创建者 creator、所属政府投资项目的创建者及管理员可修改记录, 与项目可见范围一致
+func checkGovProjectOwner(db *gorm.DB, govRepo repositories.ImplementGovRepo, userRepo repositories.UserRepo, user,
+	creator string, projectID int64) exception.Exception {
+	if creator == user {
+		return nil
+	}
+	project, ex := govRepo.Get(db, projectID)
+	if ex != nil && ex.Type() != response.ExceptionRecordNotFound {
+		return ex
+	}
+	if project != nil && project.CreateBy == user {
+		return nil
+	}
+	userInfo, ex := userRepo.Get(db, user)
+	if ex != nil {
+		return ex
+	}
+	if !userInfo.IsAdmin {
+		return exception.New(response.ExceptionForbidden, "当前操作无权限")
+	}
+	return nil
+}
+
+func timeProgress(year int, now time.Time) float64 {
+	switch {
+	case year < now.Year():
+		return 1
+	case year > now.Year():
+		return 0
+	default:
+		return float64(now.Month()) / 12
+	}
+}
+
+func (isi *implementGovServiceImpl) Reconcile(user string, params *vo.ReconcileFilterParam) ([]vo.ReconcileResp, exception.Exception) {
+	if params.Year == 0 {
+		return nil, exception.New(response.ExceptionMissingParameters, "缺少年份")
+	}
+	lagThreshold := defaultLagThreshold
+	if params.LagThreshold != nil {
+		lagThreshold = *params.LagThreshold
+	}
+	userInfo, ex := isi.userRepo.Get(isi.db, user)
+	if ex != nil {
+		return nil, ex
+	}
+	projects, ex := isi.repo.ListReconcile(isi.db, params, userInfo.IsAdmin, user)
+	if ex != nil {
+		return nil, ex
+	}
+	resp := make([]vo.ReconcileResp, 0, len(projects))
+	if len(projects) == 0 {
+		return resp, nil
+	}
+	ids := make([]int64, 0, len(projects))
+	for i := range projects {
+		ids = append(ids, projects[i].ID)
+	}
+	sums, ex := isi.GovProcessRepo.InvestmentSum(isi.db, params.Year, 12, ids...)
+	if ex != nil {
+		return nil, ex
+	}
+	invested := make(map[int64]float64, len(sums))
+	for i := range sums {
+		invested[sums[i].ProjectID] = sums[i].YearInvested
+	}
+	progress := timeProgress(params.Year, time.Now())
+	for i := range projects {
+		item := vo.ReconcileResp{
+			ProjectID:    projects[i].ID,
+			Name:         projects[i].Name,
+			ProjectCode:  projects[i].ProjectCode,
+			DutyUnit:     projects[i].DutyUint,
+			Year:         params.Year,
+			Sources:      make([]vo.SourcePlan, 0, len(vo.FundSourceNames)),
+			Invested:     invested[projects[i].ID],
+			TimeProgress: progress,
+		}
+		details, err := vo.ParseInvestmentDetail(projects[i].InvestmentDetail)
+		if err != nil {
+			item.InvalidDetail = true
+		}
+		plans := vo.PlanBySource(details, params.Year)
+		for source := vo.FundSourceFinance; source <= vo.FundSourceOther; source++ {
+			item.Sources = append(item.Sources, vo.SourcePlan{
+				Type: source,
+				Name: vo.FundSourceNames[source],
+				Plan: plans[source],
+			})
+			item.PlanTotal += plans[source]
+		}
+		if item.PlanTotal > 0 {
+			item.Completeness = item.Invested / item.PlanTotal
+		}
+		// 无计划资金却已填报完成投资, 同样视为超计划
+		item.Overrun = item.Invested > item.PlanTotal
+		item.Lagging = item.PlanTotal > 0 && progress-item.Completeness > lagThreshold
+		if params.OnlyAbnormal && !item.Overrun && !item.Lagging && !item.InvalidDetail {
+			continue
+		}
+		resp = append(resp, item)
+	}
+	return resp, nil
+}
+
+func (isi *implementGovServiceImpl) Export(user string, params *vo.ImplementGovFilterParam) (*xlsx.File, exception.Exception) {
+	userInfo, ex := isi.userRepo.Get(isi.db, user)
+	if ex != nil {
+		return nil, ex
+	}
+	return exportImplementGov(func(pageInfo *vo.PageInfo) ([]models.ImplementGov, exception.Exception) {
+		_, projects, ex := isi.repo.List(isi.db, pageInfo, params, userInfo.IsAdmin, user)
+		return projects, ex
+	})
+}
+
+func (isi *implementGovServiceImpl) PortfolioStat(user string, params *vo.PortfolioStatFilter) (*vo.PortfolioStatResp,
+	exception.Exception) {
+	now := time.Now()
+	if params.Year == 0 {
+		params.Year = now.Year()
+	}
+	if params.Month == 0 {
+		params.Month = 12
+		if params.Year == now.Year() {
+			params.Month = int(now.Month())
+		}
+	}
+	if params.Month < 1 || params.Month > 12 {
+		return nil, exception.New(response.ExceptionInvalidRequestParameters, "invalid month")
+	}
+	userInfo, ex := isi.userRepo.Get(isi.db, user)
+	if ex != nil {
+		return nil, ex
+	}
+	var snapshotID int64
+	if params.Snapshot {
+		snapshot, ex := isi.snapshotRepo.GetByPeriod(isi.db, params.Year, params.Month)
+		if ex != nil {
+			return nil, ex
+		}
+		snapshotID = snapshot.ID
+	}
+	stats, ex := isi.repo.PortfolioStat(isi.db, params, snapshotID, userInfo.IsAdmin, user)
+	if ex != nil {
+		return nil, ex
+	}
+	return vo.NewPortfolioStatResponse(params.Year, params.Month, stats), nil
+}
+
+// MapProjects 项目点位图层, 按状态及当月进度灯着色; with_boundary 时有红线范围的项目另返回红线范围要素
+func (isi *implementGovServiceImpl) MapProjects(user string, params *vo.MapFilterParam) (*vo.GeoFeatureCollection,
+	exception.Exception) {
+	if err := params.ValidateBBox(); err != nil {
+		return nil, exception.Wrap(response.ExceptionInvalidRequestParameters, err)
+	}
+	userInfo, ex := isi.userRepo.Get(isi.db, user)
+	if ex != nil {
+		return nil, ex
+	}
+	now := time.Now()
+	projects, ex := isi.repo.MapProjects(isi.db, params, now.Year(), int(now.Month()), userInfo.IsAdmin, user)
+	if ex != nil {
+		return nil, ex
+	}
+	features := make([]vo.GeoFeature, 0, len(projects))
+	for i := range projects {
+		p := &projects[i]
+		props := vo.MapProjectProps{
+			ID:              p.ID,
+			Source:          p.Source,
+			Layer:           vo.MapLayerLocation,
+			Name:            p.Name,
+			Type:            p.Type,
+			Level:           p.Level,
+			ProjectType:     p.ProjectType,
+			Status:          p.Status,
+			Township:        p.Township,
+			TotalInvestment: p.TotalInvestment,
+			Progress:        p.Progress,
+		}
+		features = append(features, vo.NewGeoFeature(p.Location, props))
+		if len(p.Boundary) > 0 {
+			props.Layer = vo.MapLayerBoundary
+			features = append(features, vo.NewGeoFeature(p.Boundary, props))
+		}
+	}
+	return vo.NewGeoFeatureCollection(features), nil
+}
+
+// MapTownships 街镇汇总图层, 要素位置为街镇内有位置项目的平均位置, 均无位置时几何为空
+func (isi *implementGovServiceImpl) MapTownships(user string, params *vo.MapFilterParam) (*vo.GeoFeatureCollection,
+	exception.Exception) {
+	if err := params.ValidateBBox(); err != nil {
+		return nil, exception.Wrap(response.ExceptionInvalidRequestParameters, err)
+	}
+	userInfo, ex := isi.userRepo.Get(isi.db, user)
+	if ex != nil {
+		return nil, ex
+	}
+	now := time.Now()
+	townships, ex := isi.repo.MapTownships(isi.db, params, now.Year(), int(now.Month()), userInfo.IsAdmin, user)
+	if ex != nil {
+		return nil, ex
+	}
+	features := make([]vo.GeoFeature, 0, len(townships))
+	for _, t := range townships {
+		feature := vo.NewGeoFeature(nil, vo.MapTownshipProps{
+			Township:        t.Township,
+			Count:           t.Count,
+			TotalInvestment: t.TotalInvestment,
+			UnStart:         t.UnStart,
+			Started:         t.Started,
+			Finished:        t.Finished,
+			Red:             t.Red,
+		})
+		if t.Lng != nil && t.Lat != nil {
+			feature.Geometry = vo.NewGeoPoint(*t.Lng, *t.Lat).JSON()
+		}
+		features = append(features, feature)
+	}
+	return vo.NewGeoFeatureCollection(features), nil
+}
+
+// maxAnalysisBuckets 按月统计最多跨度
+const maxAnalysisBuckets = 120
+
+func (isi *implementGovServiceImpl) DataAnalysis(user string, params *vo.ImplementAnalysisFilter) ([]vo.ImplementAnalysisResp,
+	exception.Exception) {
+	begin, err := parseAnalysisTime(params.Begin)
+	if err != nil {
+		return nil, exception.Wrap(response.ExceptionParseDate, err)
+	}
+	end, err := parseAnalysisTime(params.End)
+	if err != nil {
+		return nil, exception.Wrap(response.ExceptionParseDate, err)
+	}
+	buckets := analysisBuckets(params.QueryType, begin, end)
+	if len(buckets) == 0 || len(buckets) > maxAnalysisBuckets {
+		return nil, exception.New(response.ExceptionInvalidRequestParameters, "invalid time range")
+	}
+	userInfo, ex := isi.userRepo.Get(isi.db, user)
+	if ex != nil {
+		return nil, ex
+	}
+	cur, ex := isi.analysisByBucket(params, begin, end, userInfo.IsAdmin, user)
+	if ex != nil {
+		return nil, ex
+	}
+	var last map[string]*models.ImplementAnalysis
+	if params.Compare {
+		last, ex = isi.analysisByBucket(params, begin.AddDate(-1, 0, 0), end.AddDate(-1, 0, 0), userInfo.IsAdmin, user)
+		if ex != nil {
+			return nil, ex
+		}
+	}
+	resp := make([]vo.ImplementAnalysisResp, 0, len(buckets))
+	for _, b := range buckets {
+		bucket := analysisBucket(params.QueryType, b)
+		item := vo.ImplementAnalysisResp{ImplementAnalysisData: vo.NewImplementAnalysisData(bucket, cur[bucket])}
+		if params.Compare {
+			lastBucket := analysisBucket(params.QueryType, b.AddDate(-1, 0, 0))
+			item.SetLastYear(vo.NewImplementAnalysisData(lastBucket, last[lastBucket]))
+		}
+		resp = append(resp, item)
+	}
+	return resp, nil
+}
+
+func (isi *implementGovServiceImpl) analysisByBucket(params *vo.ImplementAnalysisFilter, begin, end time.Time, isAdmin bool,
+	user string) (map[string]*models.ImplementAnalysis, exception.Exception) {
+	res, ex := isi.repo.DataAnalysis(isi.db, params, begin, end, isAdmin, user)
+	if ex != nil {
+		return nil, ex
+	}
+	m := make(map[string]*models.ImplementAnalysis, len(res))
+	for i := range res {
+		m[res[i].Bucket] = &res[i]
+	}
+	return m, nil
+}
+
+func parseAnalysisTime(s string) (time.Time, error) {
+	t, err := time.ParseInLocation(constant.DateTimeFormat, s, time.Local)
+	if err != nil {
+		return time.ParseInLocation(constant.DateFormat, s, time.Local)
+	}
+	return t, nil
+}
+
+// analysisBuckets [begin, end) 内各统计时间段的起始时间, 无数据的时间段同样返回
+func analysisBuckets(queryType int, begin, end time.Time) []time.Time {
+	res := make([]time.Time, 0)
+	cur := time.Date(begin.Year(), begin.Month(), 1, 0, 0, 0, 0, time.Local)
+	step := func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	if queryType == 1 {
+		cur = time.Date(begin.Year(), 1, 1, 0, 0, 0, 0, time.Local)
+		step = func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }
+	}
+	for ; cur.Before(end) && len(res) <= maxAnalysisBuckets; cur = step(cur) {
+		res = append(res, cur)
+	}
+	return res
+}
+
+func analysisBucket(queryType int, t time.Time) string {
+	if queryType == 1 {
+		return t.Format("2006")
+	}
+	return t.Format("2006-01")
+}
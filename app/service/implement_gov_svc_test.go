@@ -0,0 +1,76 @@
+package service
+
+import (
+	"lpms/app/models"
+	"lpms/app/repositories"
+	"lpms/app/response"
+	"lpms/exception"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// testGovRepo 项目保存在内存中, 仅实现 Get
+type testGovRepo struct {
+	repositories.ImplementGovRepo
+	projects map[int64]*models.ImplementGov
+}
+
+func (r *testGovRepo) Get(db *gorm.DB, id int64) (*models.ImplementGov, exception.Exception) {
+	p, ok := r.projects[id]
+	if !ok {
+		return nil, exception.New(response.ExceptionRecordNotFound, "record not found")
+	}
+	return p, nil
+}
+
+// testUserRepo 用户保存在内存中, 仅实现 Get
+type testUserRepo struct {
+	repositories.UserRepo
+	users map[string]*models.User
+}
+
+func (r *testUserRepo) Get(db *gorm.DB, username string) (*models.User, exception.Exception) {
+	u, ok := r.users[username]
+	if !ok {
+		return nil, exception.New(response.ExceptionRecordNotFound, "record not found")
+	}
+	return u, nil
+}
+
+func TestCheckGovProjectOwner(t *testing.T) {
+	project := &models.ImplementGov{ID: 1}
+	project.CreateBy = "owner"
+	govRepo := &testGovRepo{projects: map[int64]*models.ImplementGov{1: project}}
+	userRepo := &testUserRepo{users: map[string]*models.User{
+		"owner":   {},
+		"creator": {},
+		"admin":   {IsAdmin: true},
+		"other":   {},
+	}}
+	cases := []struct {
+		name      string
+		user      string
+		projectID int64
+		allowed   bool
+	}{
+		{"record creator", "creator", 1, true},
+		{"project creator", "owner", 1, true},
+		{"admin", "admin", 1, true},
+		{"other user", "other", 1, false},
+		{"admin of deleted project", "admin", 2, true},
+		{"other user of deleted project", "other", 2, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ex := checkGovProjectOwner(nil, govRepo, userRepo, c.user, "creator", c.projectID)
+			if c.allowed {
+				if ex != nil {
+					t.Fatalf("expected allowed, got %v", ex)
+				}
+				return
+			}
+			expectException(t, ex, response.ExceptionForbidden, "无权限")
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package service
 
 import (
+	"lpms/app/models"
 	"lpms/app/repositories"
 	"lpms/app/response"
 	"lpms/app/vo"
 	"lpms/commom/drivers/database"
+	"lpms/commom/xlsx"
+	"lpms/constant"
 	"lpms/exception"
 	"strconv"
 	"strings"
@@ -19,17 +22,21 @@ var (
 )
 
 type ImpleIndustryServiceImpl struct {
-	db      *gorm.DB
-	repo    repositories.ImpleIndustryRepo
-	objRepo repositories.ObjectRepo
+	db         *gorm.DB
+	repo       repositories.ImpleIndustryRepo
+	objRepo    repositories.ObjectRepo
+	userRepo   repositories.UserRepo
+	attachRepo repositories.AttachmentRepo
 }
 
 func GetImpleIndustryService() ImpleIndustryService {
 	ImpleIndustryOnce.Do(func() {
 		ImpleIndustryServiceInstance = &ImpleIndustryServiceImpl{
-			db:      database.GetDriver(),
-			repo:    repositories.GetImpleIndustryRepo(),
-			objRepo: repositories.GetObjectRepo(),
+			db:         database.GetDriver(),
+			repo:       repositories.GetImpleIndustryRepo(),
+			objRepo:    repositories.GetObjectRepo(),
+			userRepo:   repositories.GetUserRepo(),
+			attachRepo: repositories.GetAttachmentRepo(),
 		}
 	})
 	return ImpleIndustryServiceInstance
@@ -39,13 +46,36 @@ type ImpleIndustryService interface {
 	Create(openID string, param *vo.ImpleIndustryReq) exception.Exception
 	Get(id int64) (*vo.ImpleIndustryResp, exception.Exception)
 	List(user string, params *vo.ImpleIndustryFilterParam, pageInfo *vo.PageInfo) (*vo.DataPagination, exception.Exception)
+	Export(user string, params *vo.ImpleIndustryFilterParam) (*xlsx.File, exception.Exception)
 	Delete(id int64) exception.Exception
 	MultiDelete(ids string) exception.Exception
 }
 
 func (isi *ImpleIndustryServiceImpl) Create(openID string, param *vo.ImpleIndustryReq) exception.Exception {
+	if errs := param.ProjectGeo.Validate(); len(errs) > 0 {
+		return exception.Wrap(response.ExceptionInvalidRequestParameters, errs[0])
+	}
+	if ex := checkObjectRefs(isi.db, isi.objRepo, isi.userRepo, openID, nil,
+		param.UploadCadID, param.SitePhoto); ex != nil {
+		return ex
+	}
+	tx := isi.db.Begin()
+	if tx.Error != nil {
+		return exception.Wrap(response.ExceptionDatabase, tx.Error)
+	}
+	defer tx.Rollback()
 	res := param.ToModel(openID)
-	return isi.repo.Create(isi.db, res)
+	if ex := isi.repo.Create(tx, res); ex != nil {
+		return ex
+	}
+	if ex := syncLegacyAttachments(tx, isi.attachRepo, constant.AttachIndustry, res.ID, openID,
+		[2]string{}, [2]string{res.UploadCadID, res.SitePhoto}); ex != nil {
+		return ex
+	}
+	if err := tx.Commit().Error; err != nil {
+		return exception.Wrap(response.ExceptionDatabase, err)
+	}
+	return nil
 }
 
 func (isi *ImpleIndustryServiceImpl) Get(id int64) (*vo.ImpleIndustryResp, exception.Exception) {
@@ -89,17 +119,26 @@ func (isi *ImpleIndustryServiceImpl) Delete(id int64) exception.Exception {
 	if ex != nil {
 		return ex
 	}
-	if pro.SitePhoto != "" {
-		if exx := isi.objRepo.Delete(isi.db, pro.SitePhoto); exx != nil {
-			return exx
-		}
+	tx := isi.db.Begin()
+	if tx.Error != nil {
+		return exception.Wrap(response.ExceptionDatabase, tx.Error)
 	}
-	if pro.UploadCadID != "" {
-		if exx := isi.objRepo.Delete(isi.db, pro.UploadCadID); exx != nil {
-			return exx
-		}
+	defer tx.Rollback()
+	if ex := isi.attachRepo.DeleteByProject(tx, constant.AttachIndustry, id); ex != nil {
+		return ex
+	}
+	if ex := isi.repo.Delete(tx, id); ex != nil {
+		return ex
+	}
+	objs, ex := deleteObjects(tx, isi.objRepo, pro.SitePhoto, pro.UploadCadID)
+	if ex != nil {
+		return ex
+	}
+	if err := tx.Commit().Error; err != nil {
+		return exception.Wrap(response.ExceptionDatabase, err)
 	}
-	return isi.repo.Delete(isi.db, id)
+	deleteObjectContents(isi.objRepo, objs)
+	return nil
 }
 
 func (isi *ImpleIndustryServiceImpl) MultiDelete(ids string) exception.Exception {
@@ -115,21 +154,39 @@ func (isi *ImpleIndustryServiceImpl) MultiDelete(ids string) exception.Exception
 		}
 		did = append(did, int64(id))
 	}
+	tx := isi.db.Begin()
+	if tx.Error != nil {
+		return exception.Wrap(response.ExceptionDatabase, tx.Error)
+	}
+	defer tx.Rollback()
+	objIDs := make([]string, 0, len(did)*2)
 	for i := range did {
-		pro, ex := isi.repo.Get(isi.db, did[i])
+		pro, ex := isi.repo.Get(tx, did[i])
 		if ex != nil {
 			return ex
 		}
-		if pro.SitePhoto != "" {
-			if exx := isi.objRepo.Delete(isi.db, pro.SitePhoto); exx != nil {
-				return exx
-			}
-		}
-		if pro.UploadCadID != "" {
-			if exx := isi.objRepo.Delete(isi.db, pro.UploadCadID); exx != nil {
-				return exx
-			}
-		}
+		objIDs = append(objIDs, pro.SitePhoto, pro.UploadCadID)
+	}
+	if ex := isi.attachRepo.DeleteByProject(tx, constant.AttachIndustry, did...); ex != nil {
+		return ex
+	}
+	if ex := isi.repo.MultiDelete(tx, did); ex != nil {
+		return ex
+	}
+	objs, ex := deleteObjects(tx, isi.objRepo, objIDs...)
+	if ex != nil {
+		return ex
 	}
-	return isi.repo.MultiDelete(isi.db, did)
+	if err := tx.Commit().Error; err != nil {
+		return exception.Wrap(response.ExceptionDatabase, err)
+	}
+	deleteObjectContents(isi.objRepo, objs)
+	return nil
+}
+
+func (isi *ImpleIndustryServiceImpl) Export(user string, params *vo.ImpleIndustryFilterParam) (*xlsx.File, exception.Exception) {
+	return exportImpleIndustry(func(pageInfo *vo.PageInfo) ([]models.ImpleIndustry, exception.Exception) {
+		_, projects, ex := isi.repo.List(isi.db, pageInfo, params, user)
+		return projects, ex
+	})
 }
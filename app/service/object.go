@@ -1,31 +1,58 @@
 package service
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"lpms/app/models"
 	"lpms/app/repositories"
 	"lpms/app/response"
 	"lpms/app/vo"
 	"lpms/commom/drivers/database"
+	"lpms/commom/drivers/scanner"
+	"lpms/commom/scan"
+	"lpms/config"
+	"lpms/constant"
 	"lpms/exception"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/goccy/go-json"
 	"github.com/google/uuid"
 
 	"gorm.io/gorm"
 )
 
 type ObjectService interface {
-	UploadFromReader(openID string, filename string, filesize int64, reader io.Reader) (string, exception.Exception)
-	Download(id string) (*vo.ObjectResp, exception.Exception)
+	UploadFromReader(openID, category, filename string, reader io.Reader) (*vo.ObjectMeta, exception.Exception)
+	Upload(openID, category string, files []*multipart.FileHeader) ([]vo.ObjectMeta, exception.Exception)
+	Download(user, id string) (*vo.ObjectResp, exception.Exception)
+	SignURL(user, id string) (*vo.ObjectURL, exception.Exception)
+	DownloadSigned(id, expires, signature string) (*vo.ObjectResp, exception.Exception)
+	PresignUpload(openID string, param *vo.ObjectUploadReq) (*vo.ObjectUploadURL, exception.Exception)
+	FinalizeUpload(openID string, param *vo.ObjectFinalizeReq) (*vo.ObjectMeta, exception.Exception)
+	PresignDownload(user, id string) (*vo.ObjectURL, exception.Exception)
 	Delete(id string) exception.Exception
 }
 
 type objectServiceImpl struct {
-	db   *gorm.DB
-	repo repositories.ObjectRepo
+	db       *gorm.DB
+	repo     repositories.ObjectRepo
+	userRepo repositories.UserRepo
+	scanner  scan.Scanner
 }
 
 var (
@@ -36,56 +63,522 @@ var (
 func GetObjectService() ObjectService {
 	objectOnce.Do(func() {
 		objectInstance = &objectServiceImpl{
-			db:   database.GetDriver(),
-			repo: repositories.GetObjectRepo(),
+			db:       database.GetDriver(),
+			repo:     repositories.GetObjectRepo(),
+			userRepo: repositories.GetUserRepo(),
+			scanner:  scanner.GetDriver(),
 		}
 	})
 	return objectInstance
 }
 
-func (osi *objectServiceImpl) UploadFromReader(
-	openID string, filename string, filesize int64, reader io.Reader,
-) (string, exception.Exception) {
-	uid, err := uuid.NewUUID()
-	if err != nil {
-		return "", exception.Wrap(response.ExceptionGenerateID, err)
+// UploadFromReader 校验并存储文件, 文件类型按文件头识别
+func (osi *objectServiceImpl) UploadFromReader(openID, category, filename string, reader io.Reader) (*vo.ObjectMeta,
+	exception.Exception) {
+	obj, content, ex := osi.prepare(openID, category, filename, reader)
+	if ex != nil {
+		return nil, ex
 	}
+	defer content.Close()
+	if ex := osi.repo.UploadFromReader(osi.db, obj, content); ex != nil {
+		return nil, ex
+	}
+	return newObjectMeta(obj), nil
+}
 
-	id := uid.String()
-
+func newObject(openID, id, filename, contentType string, size int64) *models.Object {
 	now := time.Now().UTC()
-
-	if ex := osi.repo.UploadFromReader(osi.db, &models.Object{
-		ID:       id,
-		Filename: filename,
-		Path:     fmt.Sprintf("%s/%s", id, filename),
-		Size:     filesize,
-		Buff:     nil,
+	return &models.Object{
+		ID:          id,
+		Filename:    filename,
+		Path:        fmt.Sprintf("%s/%s", id, filename),
+		Size:        size,
+		ContentType: contentType,
 		Base: models.Base{
 			CreateBy: openID,
 			CreateAt: now,
 			UpdateBy: openID,
 			UpdateAt: now,
 		},
-	}, reader); ex != nil {
-		return "", ex
 	}
-	return id, nil
 }
 
+func newObjectMeta(obj *models.Object) *vo.ObjectMeta {
+	return &vo.ObjectMeta{
+		ID:          obj.ID,
+		Filename:    obj.Filename,
+		Size:        obj.Size,
+		ContentType: obj.ContentType,
+	}
+}
+
+// prepare 按上传类别校验文件名称、大小及内容并安全扫描, 返回待存储的文件记录及内容
+func (osi *objectServiceImpl) prepare(openID, category, filename string, reader io.Reader) (*models.Object,
+	*uploadContent, exception.Exception) {
+	rule, ex := uploadRule(category)
+	if ex != nil {
+		return nil, nil, ex
+	}
+	filename = cleanFilename(filename)
+	if ex := checkFileName(rule, filename, 0); ex != nil {
+		return nil, nil, ex
+	}
+	content, err := spoolUpload(reader, rule.MaxSize<<20)
+	if err != nil {
+		return nil, nil, exception.Wrap(response.ExceptionInvalidRequestBody, err)
+	}
+	uid, err := uuid.NewRandom()
+	if err != nil {
+		content.Close()
+		return nil, nil, exception.Wrap(response.ExceptionGenerateID, err)
+	}
+	obj := newObject(openID, uid.String(), filename, "", content.size)
+	if ex := checkFileName(rule, filename, content.size); ex != nil {
+		content.Close()
+		return nil, nil, ex
+	}
+	if ex := osi.inspect(rule, obj, content); ex != nil {
+		content.Close()
+		return nil, nil, ex
+	}
+	return obj, content, nil
+}
+
+// inspect 按文件头校验内容并记录文件类型, 再安全扫描; 发现威胁的文件存入隔离存储后拒绝上传
+func (osi *objectServiceImpl) inspect(rule *config.UploadRule, obj *models.Object, content io.ReadSeeker) exception.Exception {
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(content, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return exception.Wrap(response.ExceptionInvalidFile, err)
+	}
+	contentType, ex := checkContent(rule, obj.Filename, head[:n])
+	if ex != nil {
+		return ex
+	}
+	obj.ContentType = contentType
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return exception.Wrap(response.ExceptionInvalidFile, err)
+	}
+	result, err := osi.scanner.Scan(content)
+	if err != nil {
+		return exception.Wrap(response.ExceptionScanFile, err)
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return exception.Wrap(response.ExceptionInvalidFile, err)
+	}
+	if result.Infected {
+		if ex := osi.repo.Quarantine(obj, content); ex != nil {
+			log.Printf("quarantine object %s error: %v", obj.ID, ex)
+		}
+		log.Printf("object %s (%s) uploaded by %s quarantined: %s", obj.ID, obj.Filename, obj.CreateBy,
+			result.Signature)
+		return exception.New(response.ExceptionInvalidFile, fmt.Sprintf("%s 未通过安全扫描", obj.Filename))
+	}
+	return nil
+}
+
+// Upload 先校验全部文件再依次存储, 任一文件失败时删除本次已存储的文件
+func (osi *objectServiceImpl) Upload(openID, category string, files []*multipart.FileHeader) ([]vo.ObjectMeta,
+	exception.Exception) {
+	if len(files) == 0 {
+		return nil, exception.New(response.ExceptionMissingParameters, "缺少上传文件")
+	}
+	objs := make([]*models.Object, 0, len(files))
+	contents := make([]*uploadContent, 0, len(files))
+	defer func() {
+		for _, content := range contents {
+			content.Close()
+		}
+	}()
+	for _, header := range files {
+		file, err := header.Open()
+		if err != nil {
+			return nil, exception.Wrap(response.ExceptionInvalidRequestBody, err)
+		}
+		defer file.Close()
+		obj, content, ex := osi.prepare(openID, category, header.Filename, file)
+		if ex != nil {
+			return nil, ex
+		}
+		objs = append(objs, obj)
+		contents = append(contents, content)
+	}
+	result := make([]vo.ObjectMeta, 0, len(files))
+	for i, obj := range objs {
+		if ex := osi.repo.UploadFromReader(osi.db, obj, contents[i]); ex != nil {
+			ids := make([]string, 0, len(result))
+			for _, uploaded := range result {
+				ids = append(ids, uploaded.ID)
+			}
+			if objs, exx := deleteObjects(osi.db, osi.repo, ids...); exx == nil {
+				deleteObjectContents(osi.repo, objs)
+			}
+			return nil, ex
+		}
+		result = append(result, *newObjectMeta(obj))
+	}
+	return result, nil
+}
+
+// Delete 删除未被项目引用的文件, 文件不存在时视为已删除
 func (osi *objectServiceImpl) Delete(id string) exception.Exception {
-	return osi.repo.Delete(osi.db, id)
+	obj, ex := osi.repo.Delete(osi.db, id)
+	if ex != nil {
+		return ex
+	}
+	if obj == nil {
+		if _, ex := osi.repo.Get(osi.db, id); ex == nil {
+			return exception.New(response.ExceptionInvalidRequestParameters, "文件仍被项目引用, 不能删除")
+		} else if ex.Type() != response.ExceptionRecordNotFound {
+			return ex
+		}
+		return nil
+	}
+	deleteObjectContents(osi.repo, []models.Object{*obj})
+	return nil
+}
+
+// checkAccess 上传者、管理员及可查看引用该文件项目的用户可下载
+func (osi *objectServiceImpl) checkAccess(user, id string) (*models.Object, exception.Exception) {
+	obj, ex := osi.repo.Get(osi.db, id)
+	if ex != nil {
+		return nil, ex
+	}
+	if obj.CreateBy == user {
+		return obj, nil
+	}
+	userInfo, ex := osi.userRepo.Get(osi.db, user)
+	if ex != nil {
+		return nil, ex
+	}
+	if userInfo.IsAdmin {
+		return obj, nil
+	}
+	visible, ex := osi.repo.Visible(osi.db, id, user)
+	if ex != nil {
+		return nil, ex
+	}
+	if !visible {
+		return nil, exception.New(response.ExceptionForbidden, "当前操作无权限")
+	}
+	return obj, nil
+}
+
+func (osi *objectServiceImpl) Download(user, id string) (*vo.ObjectResp, exception.Exception) {
+	obj, ex := osi.checkAccess(user, id)
+	if ex != nil {
+		return nil, ex
+	}
+	return osi.open(obj)
+}
+
+var (
+	objectSignKey     []byte
+	objectSignKeyOnce sync.Once
+)
+
+// signKey 文件签名密钥, 取配置的 object.sign_secret, 未配置时随机生成
+func signKey() []byte {
+	objectSignKeyOnce.Do(func() {
+		if secret := config.GetConfig().Object.SignSecret; secret != "" {
+			objectSignKey = []byte(secret)
+			return
+		}
+		objectSignKey = make([]byte, 32)
+		if _, err := rand.Read(objectSignKey); err != nil {
+			panic(err)
+		}
+		log.Printf("object.sign_secret is not configured, signed object urls are valid until restart")
+	})
+	return objectSignKey
+}
+
+func sign(message string) string {
+	mac := hmac.New(sha256.New, signKey())
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func objectSignature(id string, expires int64) string {
+	return sign(fmt.Sprintf("%s:%d", id, expires))
+}
+
+// SignURL 生成短期有效的下载链接, 供无法携带登录令牌的场景(如图片预览)使用
+func (osi *objectServiceImpl) SignURL(user, id string) (*vo.ObjectURL, exception.Exception) {
+	if _, ex := osi.checkAccess(user, id); ex != nil {
+		return nil, ex
+	}
+	return signedObjectURL(id), nil
+}
+
+func signedObjectURL(id string) *vo.ObjectURL {
+	expireAt := time.Now().Add(constant.ObjectURLExpireSeconds * time.Second)
+	query := url.Values{}
+	query.Set(constant.Expires, strconv.FormatInt(expireAt.Unix(), 10))
+	query.Set(constant.Signature, objectSignature(id, expireAt.Unix()))
+	return &vo.ObjectURL{
+		URL:      fmt.Sprintf("/object/file/%s?%s", url.PathEscape(id), query.Encode()),
+		ExpireAt: expireAt,
+	}
+}
+
+// DownloadSigned 按签名链接下载, 签名无效或已过期时无权限
+func (osi *objectServiceImpl) DownloadSigned(id, expires, signature string) (*vo.ObjectResp, exception.Exception) {
+	if expires == "" || signature == "" {
+		return nil, exception.New(response.ExceptionInvalidAccessToken, "缺少下载签名")
+	}
+	expireAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !hmac.Equal([]byte(signature), []byte(objectSignature(id, expireAt))) {
+		return nil, exception.New(response.ExceptionForbidden, "下载签名无效")
+	}
+	if time.Now().Unix() > expireAt {
+		return nil, exception.New(response.ExceptionForbidden, "下载链接已过期")
+	}
+	obj, ex := osi.repo.Get(osi.db, id)
+	if ex != nil {
+		return nil, ex
+	}
+	return osi.open(obj)
+}
+
+// uploadTicket 直传凭证内容, 签名后交由客户端在完成上传时传回
+type uploadTicket struct {
+	ID          string `json:"id"`
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+	Category    string `json:"category"`
+	User        string `json:"user"`
+}
+
+func encodeTicket(t *uploadTicket) string {
+	buf, _ := json.Marshal(t)
+	payload := base64.RawURLEncoding.EncodeToString(buf)
+	return payload + "." + sign(payload)
+}
+
+func decodeTicket(token string) (*uploadTicket, error) {
+	i := strings.LastIndex(token, ".")
+	if i < 0 || !hmac.Equal([]byte(token[i+1:]), []byte(sign(token[:i]))) {
+		return nil, errors.New("上传凭证无效")
+	}
+	buf, err := base64.RawURLEncoding.DecodeString(token[:i])
+	if err != nil {
+		return nil, errors.New("上传凭证无效")
+	}
+	t := &uploadTicket{}
+	if err := json.Unmarshal(buf, t); err != nil {
+		return nil, errors.New("上传凭证无效")
+	}
+	return t, nil
+}
+
+// PresignUpload 生成直传存储的预签名地址, 文件内容不经过服务端; 上传后须调用 FinalizeUpload 校验内容后才会保存文件记录
+func (osi *objectServiceImpl) PresignUpload(openID string, param *vo.ObjectUploadReq) (*vo.ObjectUploadURL,
+	exception.Exception) {
+	if err := param.Validate(); err != nil {
+		return nil, exception.Wrap(response.ExceptionInvalidRequestParameters, err)
+	}
+	rule, ex := uploadRule(param.Category)
+	if ex != nil {
+		return nil, ex
+	}
+	if ex := checkFileName(rule, param.Filename, param.Size); ex != nil {
+		return nil, ex
+	}
+	uid, err := uuid.NewRandom()
+	if err != nil {
+		return nil, exception.Wrap(response.ExceptionGenerateID, err)
+	}
+	contentType := param.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(param.Filename))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	obj := newObject(openID, uid.String(), param.Filename, contentType, param.Size)
+	expireAt := time.Now().Add(constant.ObjectPresignExpireSeconds * time.Second)
+	u, ex := osi.repo.PresignPut(obj, constant.ObjectPresignExpireSeconds*time.Second)
+	if ex != nil {
+		return nil, ex
+	}
+	return &vo.ObjectUploadURL{
+		ID:     obj.ID,
+		URL:    u,
+		Method: http.MethodPut,
+		UploadToken: encodeTicket(&uploadTicket{
+			ID:          obj.ID,
+			Filename:    obj.Filename,
+			Size:        obj.Size,
+			ContentType: obj.ContentType,
+			Category:    param.Category,
+			User:        openID,
+		}),
+		ExpireAt: expireAt,
+	}, nil
+}
+
+// FinalizeUpload 校验直传的文件已存储、大小与申请时一致且内容符合上传类别并通过安全扫描后保存文件记录,
+// 未通过校验时删除已上传内容
+func (osi *objectServiceImpl) FinalizeUpload(openID string, param *vo.ObjectFinalizeReq) (*vo.ObjectMeta,
+	exception.Exception) {
+	ticket, err := decodeTicket(param.UploadToken)
+	if err != nil {
+		return nil, exception.Wrap(response.ExceptionInvalidRequestParameters, err)
+	}
+	if ticket.User != openID {
+		return nil, exception.New(response.ExceptionForbidden, "当前操作无权限")
+	}
+	rule, ex := uploadRule(ticket.Category)
+	if ex != nil {
+		return nil, ex
+	}
+	obj := newObject(openID, ticket.ID, ticket.Filename, ticket.ContentType, ticket.Size)
+	if _, ex := osi.repo.Get(osi.db, obj.ID); ex == nil {
+		return nil, exception.New(response.ExceptionInvalidRequestParameters, "文件已完成上传")
+	} else if ex.Type() != response.ExceptionRecordNotFound {
+		return nil, ex
+	}
+	info, ex := osi.repo.Stat(obj)
+	if ex != nil {
+		if ex.Type() == response.ExceptionRecordNotFound {
+			return nil, exception.New(response.ExceptionInvalidFile, "文件尚未上传")
+		}
+		return nil, ex
+	}
+	if info.Size != obj.Size {
+		_ = osi.repo.DeleteContent(obj)
+		return nil, exception.New(response.ExceptionInvalidFile,
+			fmt.Sprintf("上传的文件大小%d与申请时的%d不一致", info.Size, obj.Size))
+	}
+	content, ex := osi.repo.Open(obj)
+	if ex != nil {
+		return nil, ex
+	}
+	ex = osi.inspect(rule, obj, content)
+	content.Close()
+	if ex != nil {
+		// 扫描服务异常时保留内容, 可稍后重试
+		if ex.Type() != response.ExceptionScanFile {
+			_ = osi.repo.DeleteContent(obj)
+		}
+		return nil, ex
+	}
+	if ex := osi.repo.Create(osi.db, obj); ex != nil {
+		return nil, ex
+	}
+	return newObjectMeta(obj), nil
 }
 
-func (osi *objectServiceImpl) Download(id string) (*vo.ObjectResp, exception.Exception) {
-	obj, ex := osi.repo.Download(osi.db, id)
+// PresignDownload 生成直接从存储下载的预签名地址, 权限同下载对象; 存储不支持时返回签名下载链接
+func (osi *objectServiceImpl) PresignDownload(user, id string) (*vo.ObjectURL, exception.Exception) {
+	obj, ex := osi.checkAccess(user, id)
 	if ex != nil {
 		return nil, ex
 	}
+	params := url.Values{}
+	params.Set("response-content-disposition", response.ContentDisposition(obj.Filename))
+	if obj.ContentType != "" {
+		params.Set("response-content-type", obj.ContentType)
+	}
+	expireAt := time.Now().Add(constant.ObjectURLExpireSeconds * time.Second)
+	u, ex := osi.repo.PresignGet(obj, constant.ObjectURLExpireSeconds*time.Second, params)
+	if ex != nil {
+		// 存储不支持预签名地址时改为服务端签名的下载链接
+		if ex.Type() == response.ExceptionNotConfigure {
+			return signedObjectURL(obj.ID), nil
+		}
+		return nil, ex
+	}
+	return &vo.ObjectURL{URL: u, ExpireAt: expireAt}, nil
+}
 
+// open 打开文件内容, 文件类型依次取上传时记录的类型、存储中的类型及按扩展名推断
+func (osi *objectServiceImpl) open(obj *models.Object) (*vo.ObjectResp, exception.Exception) {
+	content, ex := osi.repo.Open(obj)
+	if ex != nil {
+		return nil, ex
+	}
+	contentType := obj.ContentType
+	if contentType == "" && content.ContentType != "application/octet-stream" {
+		contentType = content.ContentType
+	}
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(obj.Filename))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
 	return &vo.ObjectResp{
-		ID:       obj.ID,
-		Filename: obj.Filename,
-		Content:  string(obj.Buff),
+		ID:          obj.ID,
+		Filename:    obj.Filename,
+		ContentType: contentType,
+		Size:        content.Size,
+		ETag:        content.ETag,
+		ModTime:     content.LastModified,
+		Content:     content,
 	}, nil
 }
+
+// checkObjectRefs 项目及合同引用的文件须由当前用户上传, 管理员不限; kept 为记录中已引用的文件, 与空id一样不再校验
+func checkObjectRefs(db *gorm.DB, objRepo repositories.ObjectRepo, userRepo repositories.UserRepo, user string,
+	kept map[string]bool, ids ...string) exception.Exception {
+	isAdmin := (*bool)(nil)
+	for _, id := range ids {
+		if id == "" || kept[id] {
+			continue
+		}
+		obj, ex := objRepo.Get(db, id)
+		if ex != nil {
+			if ex.Type() == response.ExceptionRecordNotFound {
+				return exception.New(response.ExceptionInvalidRequestParameters, "文件不存在: "+id)
+			}
+			return ex
+		}
+		if obj.CreateBy == user {
+			continue
+		}
+		if isAdmin == nil {
+			userInfo, ex := userRepo.Get(db, user)
+			if ex != nil {
+				return ex
+			}
+			isAdmin = &userInfo.IsAdmin
+		}
+		if !*isAdmin {
+			return exception.New(response.ExceptionForbidden, "当前操作无权限")
+		}
+	}
+	return nil
+}
+
+// deleteObjects 删除不再被引用的文件记录, 须在删除引用这些文件的行之后调用, 返回删除的记录.
+// 事务提交后以 deleteObjectContents 删除存储内容
+func deleteObjects(db *gorm.DB, objRepo repositories.ObjectRepo, ids ...string) ([]models.Object, exception.Exception) {
+	objs := make([]models.Object, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		obj, ex := objRepo.Delete(db, id)
+		if ex != nil {
+			return nil, ex
+		}
+		if obj != nil {
+			objs = append(objs, *obj)
+		}
+	}
+	return objs, nil
+}
+
+// deleteObjectContents 删除已删除记录的存储内容, 失败时只记录日志, 残留内容由对账任务清理
+func deleteObjectContents(objRepo repositories.ObjectRepo, objs []models.Object) {
+	for i := range objs {
+		if ex := objRepo.DeleteContent(&objs[i]); ex != nil {
+			log.Printf("delete object %s content error: %v", objs[i].ID, ex)
+		}
+	}
+}
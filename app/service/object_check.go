@@ -0,0 +1,219 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"lpms/app/response"
+	"lpms/config"
+	"lpms/exception"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// defaultUploadCategory 未指定上传类别时使用的规则
+	defaultUploadCategory = "default"
+	// sniffLength 识别文件类型读取的文件头长度
+	sniffLength = 512
+	// executableType 可执行文件, 任何类别均不允许上传
+	executableType = "application/x-executable"
+)
+
+// builtinUploadRule 未配置 default 类别时的规则
+var builtinUploadRule = config.UploadRule{MaxSize: 100}
+
+// genericContentTypes 按文件头无法确定具体格式的类型, 入库时改用按扩展名推断的类型
+var genericContentTypes = map[string]bool{
+	"application/octet-stream":  true,
+	"application/zip":           true,
+	"application/x-ole-storage": true,
+	"text/plain":                true,
+}
+
+// sniffableImageTypes 标准库可按文件头识别的图片类型, 使用这些扩展名的文件内容须与扩展名一致
+var sniffableImageTypes = map[string]bool{
+	"image/bmp":    true,
+	"image/gif":    true,
+	"image/jpeg":   true,
+	"image/png":    true,
+	"image/webp":   true,
+	"image/x-icon": true,
+}
+
+// fileMagics 标准库无法识别的文件头
+var fileMagics = []struct {
+	magic       []byte
+	contentType string
+}{
+	{[]byte("MZ"), executableType},
+	{[]byte("\x7fELF"), executableType},
+	{[]byte("\xcf\xfa\xed\xfe"), executableType},
+	{[]byte("\xce\xfa\xed\xfe"), executableType},
+	{[]byte("AC10"), "image/vnd.dwg"},
+	{[]byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1"), "application/x-ole-storage"},
+}
+
+// uploadRule 上传类别的校验规则, 类别为空时使用 default
+func uploadRule(category string) (*config.UploadRule, exception.Exception) {
+	if category == "" {
+		category = defaultUploadCategory
+	}
+	rule, ok := config.GetConfig().Upload.Categories[category]
+	if !ok {
+		if category != defaultUploadCategory {
+			return nil, exception.New(response.ExceptionInvalidRequestParameters,
+				fmt.Sprintf("上传类别 %s 无效", category))
+		}
+		rule = builtinUploadRule
+	}
+	return &rule, nil
+}
+
+// cleanFilename 去除客户端文件名称中的路径及控制字符
+func cleanFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "." || name == ".." || name == "/" {
+		return ""
+	}
+	return name
+}
+
+// checkFileName 校验文件名称、扩展名及大小, 大小未知时传 0
+func checkFileName(rule *config.UploadRule, filename string, size int64) exception.Exception {
+	if filename == "" || utf8.RuneCountInString(filename) > 200 {
+		return exception.New(response.ExceptionInvalidFile, "文件名称不能为空且不超过200个字符")
+	}
+	if len(rule.Extensions) > 0 {
+		ext := strings.ToLower(filepath.Ext(filename))
+		allowed := false
+		for _, e := range rule.Extensions {
+			if strings.ToLower(e) == ext {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return exception.New(response.ExceptionInvalidFile,
+				fmt.Sprintf("%s 的文件类型不允许上传, 仅支持 %s", filename, strings.Join(rule.Extensions, " ")))
+		}
+	}
+	if rule.MaxSize > 0 && size > rule.MaxSize<<20 {
+		return exception.New(response.ExceptionInvalidFile, fmt.Sprintf("%s 超过 %dM", filename, rule.MaxSize))
+	}
+	return nil
+}
+
+// sniffContentType 按文件头识别文件类型, 不含参数
+func sniffContentType(head []byte) string {
+	for _, m := range fileMagics {
+		if bytes.HasPrefix(head, m.magic) {
+			return m.contentType
+		}
+	}
+	contentType := http.DetectContentType(head)
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return contentType
+}
+
+// extensionContentType 按扩展名推断的文件类型, 不含参数
+func extensionContentType(filename string) string {
+	mediaType, _, err := mime.ParseMediaType(mime.TypeByExtension(filepath.Ext(filename)))
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
+
+func matchContentType(patterns []string, contentType string) bool {
+	for _, p := range patterns {
+		if p == contentType || strings.HasSuffix(p, "/*") && strings.HasPrefix(contentType, p[:len(p)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkContent 按文件头校验文件内容, 返回入库的文件类型:
+// 识别出具体格式时取识别结果, 否则取按扩展名推断的类型
+func checkContent(rule *config.UploadRule, filename string, head []byte) (string, exception.Exception) {
+	sniffed := sniffContentType(head)
+	if sniffed == executableType {
+		return "", exception.New(response.ExceptionInvalidFile, fmt.Sprintf("%s 为可执行文件, 不允许上传", filename))
+	}
+	if len(rule.MimeTypes) > 0 && !matchContentType(rule.MimeTypes, sniffed) {
+		return "", exception.New(response.ExceptionInvalidFile,
+			fmt.Sprintf("%s 的文件内容(%s)不允许上传", filename, sniffed))
+	}
+	extType := extensionContentType(filename)
+	// 图片及网页须与扩展名一致, 避免以图片扩展名上传其他内容或以其他扩展名上传网页
+	if extType != sniffed && (sniffed == "text/html" || sniffableImageTypes[extType]) {
+		return "", exception.New(response.ExceptionInvalidFile,
+			fmt.Sprintf("%s 的文件内容(%s)与扩展名不符", filename, sniffed))
+	}
+	if genericContentTypes[sniffed] && extType != "" {
+		return extType, nil
+	}
+	return sniffed, nil
+}
+
+// uploadContent 可重复读取的上传内容, 使用后须关闭
+type uploadContent struct {
+	io.ReadSeeker
+	size  int64
+	close func() error
+}
+
+func (c *uploadContent) Close() error {
+	if c.close == nil {
+		return nil
+	}
+	return c.close()
+}
+
+// spoolUpload 读取上传内容的大小, 不可定位读取的内容暂存到临时文件, 最多读取 limit+1 字节(limit 为 0 时不限)
+func spoolUpload(reader io.Reader, limit int64) (*uploadContent, error) {
+	if rs, ok := reader.(io.ReadSeeker); ok {
+		size, err := rs.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := rs.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return &uploadContent{ReadSeeker: rs, size: size}, nil
+	}
+	f, err := os.CreateTemp("", "lpms-upload-*")
+	if err != nil {
+		return nil, err
+	}
+	content := &uploadContent{ReadSeeker: f, close: func() error {
+		f.Close()
+		return os.Remove(f.Name())
+	}}
+	if limit > 0 {
+		reader = io.LimitReader(reader, limit+1)
+	}
+	if content.size, err = io.Copy(f, reader); err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		content.Close()
+		return nil, err
+	}
+	return content, nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"bytes"
+	"lpms/app/response"
+	"lpms/app/vo"
+	"lpms/commom/scan"
+	"lpms/constant"
+	"strings"
+	"testing"
+)
+
+var testPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00")
+
+func TestUploadFromReaderQuarantinesInfected(t *testing.T) {
+	svc, repo := newTestObjectService(scan.NewFake())
+	_, ex := svc.UploadFromReader("user-a", "", "eicar.txt", strings.NewReader(scan.EICAR))
+	expectException(t, ex, response.ExceptionInvalidFile, "安全扫描")
+	if paths := repo.stored(t, constant.BucketName); len(paths) != 0 {
+		t.Fatalf("expected nothing stored, got %v", paths)
+	}
+	paths := repo.stored(t, constant.QuarantineBucketName)
+	if len(paths) != 1 || !strings.HasSuffix(paths[0], "/eicar.txt") {
+		t.Fatalf("expected quarantined eicar.txt, got %v", paths)
+	}
+	if len(repo.rows) != 0 {
+		t.Fatalf("expected no object row, got %d", len(repo.rows))
+	}
+}
+
+func TestFinalizeUploadQuarantinesInfected(t *testing.T) {
+	svc, repo := newTestObjectService(scan.NewFake())
+	infected := append(append([]byte{}, testPDF...), scan.EICAR...)
+	id, path, token := presign(t, svc, "user-a", int64(len(infected)))
+	if err := repo.client.UploadObject(constant.BucketName, path, infected); err != nil {
+		t.Fatal(err)
+	}
+	_, ex := svc.FinalizeUpload("user-a", &vo.ObjectFinalizeReq{UploadToken: token})
+	expectException(t, ex, response.ExceptionInvalidFile, "安全扫描")
+	if paths := repo.stored(t, constant.BucketName); len(paths) != 0 {
+		t.Fatalf("expected infected content deleted, got %v", paths)
+	}
+	if paths := repo.stored(t, constant.QuarantineBucketName); len(paths) != 1 || paths[0] != path {
+		t.Fatalf("expected %s quarantined, got %v", path, paths)
+	}
+	if _, ok := repo.rows[id]; ok {
+		t.Fatal("expected no object row")
+	}
+}
+
+func TestUploadFromReaderRules(t *testing.T) {
+	cases := []struct {
+		name     string
+		category string
+		filename string
+		content  []byte
+		want     string
+		// 期望入库的文件类型, 为空时期望拒绝
+		contentType string
+	}{
+		{"image png", "image", "site.png", testPNG, "", "image/png"},
+		{"pdf document", "document", "plan.pdf", testPDF, "", "application/pdf"},
+		{"default text", "", "readme.txt", []byte("hello"), "", "text/plain"},
+		{"extension not allowed", "image", "site.gif", []byte("GIF89a\x01\x00\x01\x00"), "不允许上传, 仅支持", ""},
+		{"extension case", "image", "SITE.PNG", testPNG, "", "image/png"},
+		{"mime not allowed", "document", "plan.pdf", testPNG, "文件内容(image/png)不允许上传", ""},
+		{"text as image", "", "site.png", []byte("just some text"), "与扩展名不符", ""},
+		{"html as pdf", "", "plan.pdf", []byte("<html><script>alert(1)</script></html>"), "与扩展名不符", ""},
+		{"size limit", "", "big.txt", bytes.Repeat([]byte("a"), 1<<20+1), "超过 1M", ""},
+		{"size at limit", "", "big.txt", bytes.Repeat([]byte("a"), 1<<20), "", "text/plain"},
+		{"windows executable", "", "setup.pdf", append([]byte("MZ"), make([]byte, 64)...), "可执行文件", ""},
+		{"elf executable", "", "tool.txt", []byte("\x7fELF\x02\x01\x01"), "可执行文件", ""},
+		{"unknown category", "video", "a.mp4", []byte("data"), "上传类别 video 无效", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			svc, repo := newTestObjectService(scan.NewFake())
+			meta, ex := svc.UploadFromReader("user-a", c.category, c.filename, bytes.NewReader(c.content))
+			if c.contentType == "" {
+				if ex == nil {
+					t.Fatalf("expected rejected, got %+v", meta)
+				}
+				if !strings.Contains(ex.Error(), c.want) {
+					t.Fatalf("expected message containing %q, got %q", c.want, ex.Error())
+				}
+				if paths := repo.stored(t, constant.BucketName); len(paths) != 0 {
+					t.Fatalf("expected nothing stored, got %v", paths)
+				}
+				return
+			}
+			if ex != nil {
+				t.Fatalf("expected accepted, got %v", ex)
+			}
+			if meta.ContentType != c.contentType {
+				t.Fatalf("expected content type %s, got %s", c.contentType, meta.ContentType)
+			}
+			if _, ok := repo.rows[meta.ID]; !ok {
+				t.Fatal("expected object row")
+			}
+		})
+	}
+}
+
+func TestCleanFilename(t *testing.T) {
+	cases := map[string]string{
+		"plan.pdf":            "plan.pdf",
+		`C:\Users\a\plan.pdf`: "plan.pdf",
+		"../../etc/passwd":    "passwd",
+		" plan\x00\n.pdf ":    "plan.pdf",
+		"..":                  "",
+		"/":                   "",
+		"dir/":                "dir",
+		"项目/规划 图.pdf":         "规划 图.pdf",
+	}
+	for in, want := range cases {
+		if got := cleanFilename(in); got != want {
+			t.Errorf("cleanFilename(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
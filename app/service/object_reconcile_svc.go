@@ -0,0 +1,121 @@
+package service
+
+import (
+	"lpms/app/models"
+	"lpms/app/repositories"
+	"lpms/app/vo"
+	"lpms/commom/drivers/database"
+	"lpms/config"
+	"lpms/exception"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 未配置时默认文件对账保留期(小时)
+const defaultObjectGraceHours = 72
+
+var (
+	objectReconcileServiceInstance ObjectReconcileService
+	objectReconcileOnce            sync.Once
+)
+
+type objectReconcileServiceImpl struct {
+	db   *gorm.DB
+	repo repositories.ObjectRepo
+}
+
+func GetObjectReconcileService() ObjectReconcileService {
+	objectReconcileOnce.Do(func() {
+		objectReconcileServiceInstance = &objectReconcileServiceImpl{
+			db:   database.GetDriver(),
+			repo: repositories.GetObjectRepo(),
+		}
+	})
+	return objectReconcileServiceInstance
+}
+
+type ObjectReconcileService interface {
+	Run(now time.Time) (*vo.ObjectReconcileReport, exception.Exception)
+	Reconcile(before time.Time, remove bool) (*vo.ObjectReconcileReport, exception.Exception)
+}
+
+// Run 定时任务: 每天在配置的时间对账一次, 其余时间返回 nil
+func (ori *objectReconcileServiceImpl) Run(now time.Time) (*vo.ObjectReconcileReport, exception.Exception) {
+	cfg := config.GetConfig().Job
+	if now.Hour() != cfg.ObjectReconcileHour {
+		return nil, nil
+	}
+	grace := cfg.ObjectGraceHours
+	if grace <= 0 {
+		grace = defaultObjectGraceHours
+	}
+	return ori.Reconcile(now.Add(-time.Duration(grace)*time.Hour), cfg.ObjectCleanup)
+}
+
+// Reconcile 对账 before 之前创建的文件记录及存储对象: 未被项目引用的文件、缺少内容的文件及没有记录的存储对象.
+// remove 时删除前者及后者; 缺少内容的文件仍被项目引用时仅报告, 删除记录不能恢复文件
+func (ori *objectReconcileServiceImpl) Reconcile(before time.Time, remove bool) (*vo.ObjectReconcileReport,
+	exception.Exception) {
+	// 先列出存储对象再查询记录, 列出后新上传的文件在记录中可查到, 不会误判为缺少记录
+	contents, ex := ori.repo.ListContents()
+	if ex != nil {
+		return nil, ex
+	}
+	objs, ex := ori.repo.ListAll(ori.db)
+	if ex != nil {
+		return nil, ex
+	}
+	unreferenced, ex := ori.repo.Unreferenced(ori.db, before.UTC())
+	if ex != nil {
+		return nil, ex
+	}
+	report := &vo.ObjectReconcileReport{
+		Unreferenced: make([]string, 0, len(unreferenced)),
+		Missing:      make([]string, 0),
+		Stray:        make([]string, 0),
+		Removed:      remove,
+	}
+	for i := range unreferenced {
+		report.Unreferenced = append(report.Unreferenced, unreferenced[i].ID)
+	}
+	stored := make(map[string]bool, len(contents))
+	for i := range contents {
+		stored[contents[i].Name] = true
+	}
+	recorded := make(map[string]bool, len(objs))
+	for i := range objs {
+		recorded[objs[i].Path] = true
+		// 文件记录的创建时间为不带时区的 UTC 时间
+		if !stored[objs[i].Path] && objs[i].CreateAt.Before(before.UTC()) {
+			report.Missing = append(report.Missing, objs[i].ID)
+		}
+	}
+	for i := range contents {
+		if !recorded[contents[i].Name] && contents[i].LastModified.Before(before) {
+			report.Stray = append(report.Stray, contents[i].Name)
+		}
+	}
+	if !remove {
+		return report, nil
+	}
+	for _, id := range report.Unreferenced {
+		// 列出后被引用的文件不会删除
+		obj, ex := ori.repo.Delete(ori.db, id)
+		if ex != nil {
+			return nil, ex
+		}
+		if obj != nil {
+			if ex := ori.repo.DeleteContent(obj); ex != nil {
+				return nil, ex
+			}
+		}
+	}
+	for _, path := range report.Stray {
+		if ex := ori.repo.DeleteContent(&models.Object{Path: path}); ex != nil {
+			return nil, ex
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"lpms/app/models"
+	"lpms/app/repositories"
+	"lpms/app/response"
+	"lpms/app/vo"
+	"lpms/commom/scan"
+	"lpms/config"
+	"lpms/constant"
+	"lpms/exception"
+	"lpms/minio_sdk"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	var c config.Config
+	c.Object.SignSecret = "test-secret"
+	c.Upload.Categories = map[string]config.UploadRule{
+		"default": {MaxSize: 1},
+		"image": {
+			Extensions: []string{".jpg", ".jpeg", ".png"},
+			MimeTypes:  []string{"image/jpeg", "image/png"},
+			MaxSize:    1,
+		},
+		"document": {
+			Extensions: []string{".pdf"},
+			MimeTypes:  []string{"application/pdf"},
+			MaxSize:    1,
+		},
+	}
+	config.SetConfig(c)
+}
+
+// testObjectRepo 文件记录保存在内存中, 内容存入 minio_sdk.NewFake, 仅实现文件服务用到的方法
+type testObjectRepo struct {
+	repositories.ObjectRepo
+	client minio_sdk.Client
+	rows   map[string]*models.Object
+}
+
+func newTestObjectService(scanner scan.Scanner) (*objectServiceImpl, *testObjectRepo) {
+	repo := &testObjectRepo{client: minio_sdk.NewFake(), rows: make(map[string]*models.Object)}
+	return &objectServiceImpl{repo: repo, scanner: scanner}, repo
+}
+
+func (r *testObjectRepo) UploadFromReader(db *gorm.DB, o *models.Object, reader io.Reader) exception.Exception {
+	if err := r.client.UploadObjectFromReader(constant.BucketName, o.Path, reader, o.Size); err != nil {
+		return exception.Wrap(response.ExceptionUploadObject, err)
+	}
+	r.rows[o.ID] = o
+	return nil
+}
+
+func (r *testObjectRepo) Get(db *gorm.DB, id string) (*models.Object, exception.Exception) {
+	o, ok := r.rows[id]
+	if !ok {
+		return nil, exception.New(response.ExceptionRecordNotFound, "record not found")
+	}
+	return o, nil
+}
+
+func (r *testObjectRepo) Create(db *gorm.DB, o *models.Object) exception.Exception {
+	r.rows[o.ID] = o
+	return nil
+}
+
+func (r *testObjectRepo) Open(o *models.Object) (*minio_sdk.ObjectContent, exception.Exception) {
+	content, err := r.client.OpenObject(constant.BucketName, o.Path)
+	if errors.Is(err, minio_sdk.ErrObjectNotFound) {
+		return nil, exception.Wrap(response.ExceptionRecordNotFound, err)
+	}
+	return content, exception.Wrap(response.ExceptionDownloadObject, err)
+}
+
+func (r *testObjectRepo) Stat(o *models.Object) (*minio_sdk.Object, exception.Exception) {
+	info, err := r.client.StatObject(constant.BucketName, o.Path)
+	if errors.Is(err, minio_sdk.ErrObjectNotFound) {
+		return nil, exception.Wrap(response.ExceptionRecordNotFound, err)
+	}
+	return info, exception.Wrap(response.ExceptionDownloadObject, err)
+}
+
+func (r *testObjectRepo) PresignPut(o *models.Object, expires time.Duration) (string, exception.Exception) {
+	u, err := r.client.PresignPutObject(constant.BucketName, o.Path, expires)
+	return u, exception.Wrap(response.ExceptionUploadObject, err)
+}
+
+func (r *testObjectRepo) DeleteContent(o *models.Object) exception.Exception {
+	return exception.Wrap(response.ExceptionDeleteObject, r.client.DeleteObject(constant.BucketName, o.Path))
+}
+
+func (r *testObjectRepo) Quarantine(o *models.Object, reader io.Reader) exception.Exception {
+	return exception.Wrap(response.ExceptionUploadObject,
+		r.client.UploadObjectFromReader(constant.QuarantineBucketName, o.Path, reader, o.Size))
+}
+
+// stored 存储中的文件路径
+func (r *testObjectRepo) stored(t *testing.T, bucket string) []string {
+	t.Helper()
+	objs, err := r.client.ListObjects(bucket)
+	if err != nil {
+		t.Fatalf("list objects: %v", err)
+	}
+	paths := make([]string, 0, len(objs))
+	for _, o := range objs {
+		paths = append(paths, o.Name)
+	}
+	return paths
+}
+
+func expectException(t *testing.T, ex exception.Exception, want exception.Type, contains string) {
+	t.Helper()
+	if ex == nil {
+		t.Fatalf("expected exception %v, got nil", want)
+	}
+	if ex.Type() != want {
+		t.Fatalf("expected exception %v, got %v: %s", want, ex.Type(), ex.Error())
+	}
+	if !strings.Contains(ex.Error(), contains) {
+		t.Fatalf("expected message containing %q, got %q", contains, ex.Error())
+	}
+}
+
+var testPDF = []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n%%EOF\n")
+
+// presign 申请直传并返回文件id、存储路径及上传凭证
+func presign(t *testing.T, svc *objectServiceImpl, user string, size int64) (string, string, string) {
+	t.Helper()
+	res, ex := svc.PresignUpload(user, &vo.ObjectUploadReq{Filename: "plan.pdf", Size: size, Category: "document"})
+	if ex != nil {
+		t.Fatalf("presign upload: %v", ex)
+	}
+	return res.ID, res.ID + "/plan.pdf", res.UploadToken
+}
+
+func TestFinalizeUpload(t *testing.T) {
+	svc, repo := newTestObjectService(scan.NewFake())
+	id, path, token := presign(t, svc, "user-a", int64(len(testPDF)))
+	if err := repo.client.UploadObject(constant.BucketName, path, testPDF); err != nil {
+		t.Fatal(err)
+	}
+	meta, ex := svc.FinalizeUpload("user-a", &vo.ObjectFinalizeReq{UploadToken: token})
+	if ex != nil {
+		t.Fatalf("finalize upload: %v", ex)
+	}
+	if meta.ID != id || meta.ContentType != "application/pdf" || meta.Size != int64(len(testPDF)) {
+		t.Fatalf("unexpected meta %+v", meta)
+	}
+	if row, ok := repo.rows[id]; !ok || row.CreateBy != "user-a" {
+		t.Fatalf("expected object row created by user-a, got %+v", row)
+	}
+}
+
+func TestFinalizeUploadSizeMismatch(t *testing.T) {
+	svc, repo := newTestObjectService(scan.NewFake())
+	id, path, token := presign(t, svc, "user-a", int64(len(testPDF))+10)
+	if err := repo.client.UploadObject(constant.BucketName, path, testPDF); err != nil {
+		t.Fatal(err)
+	}
+	_, ex := svc.FinalizeUpload("user-a", &vo.ObjectFinalizeReq{UploadToken: token})
+	expectException(t, ex, response.ExceptionInvalidFile, "不一致")
+	if paths := repo.stored(t, constant.BucketName); len(paths) != 0 {
+		t.Fatalf("expected mismatched content deleted, got %v", paths)
+	}
+	if _, ok := repo.rows[id]; ok {
+		t.Fatal("expected no object row")
+	}
+}
+
+func TestFinalizeUploadNotUploaded(t *testing.T) {
+	svc, repo := newTestObjectService(scan.NewFake())
+	id, _, token := presign(t, svc, "user-a", int64(len(testPDF)))
+	_, ex := svc.FinalizeUpload("user-a", &vo.ObjectFinalizeReq{UploadToken: token})
+	expectException(t, ex, response.ExceptionInvalidFile, "尚未上传")
+	if _, ok := repo.rows[id]; ok {
+		t.Fatal("expected no object row")
+	}
+}
+
+func TestFinalizeUploadUserMismatch(t *testing.T) {
+	svc, repo := newTestObjectService(scan.NewFake())
+	id, path, token := presign(t, svc, "user-a", int64(len(testPDF)))
+	if err := repo.client.UploadObject(constant.BucketName, path, testPDF); err != nil {
+		t.Fatal(err)
+	}
+	_, ex := svc.FinalizeUpload("user-b", &vo.ObjectFinalizeReq{UploadToken: token})
+	expectException(t, ex, response.ExceptionForbidden, "")
+	if _, ok := repo.rows[id]; ok {
+		t.Fatal("expected no object row")
+	}
+	// 其他用户的请求不影响申请者完成上传
+	if _, ex := svc.FinalizeUpload("user-a", &vo.ObjectFinalizeReq{UploadToken: token}); ex != nil {
+		t.Fatalf("finalize upload by owner: %v", ex)
+	}
+}
+
+func TestFinalizeUploadTamperedToken(t *testing.T) {
+	svc, _ := newTestObjectService(scan.NewFake())
+	_, _, token := presign(t, svc, "user-a", int64(len(testPDF)))
+	_, ex := svc.FinalizeUpload("user-a", &vo.ObjectFinalizeReq{UploadToken: "x" + token})
+	expectException(t, ex, response.ExceptionInvalidRequestParameters, "上传凭证无效")
+}
+
+func TestFinalizeUploadTwice(t *testing.T) {
+	svc, repo := newTestObjectService(scan.NewFake())
+	id, path, token := presign(t, svc, "user-a", int64(len(testPDF)))
+	if err := repo.client.UploadObject(constant.BucketName, path, testPDF); err != nil {
+		t.Fatal(err)
+	}
+	if _, ex := svc.FinalizeUpload("user-a", &vo.ObjectFinalizeReq{UploadToken: token}); ex != nil {
+		t.Fatalf("finalize upload: %v", ex)
+	}
+	_, ex := svc.FinalizeUpload("user-a", &vo.ObjectFinalizeReq{UploadToken: token})
+	expectException(t, ex, response.ExceptionInvalidRequestParameters, "已完成上传")
+	if _, ok := repo.rows[id]; !ok {
+		t.Fatal("expected object row kept")
+	}
+	content, err := repo.client.DownloadObject(constant.BucketName, path)
+	if err != nil || !bytes.Equal(content, testPDF) {
+		t.Fatalf("expected content kept, got %q, %v", content, err)
+	}
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"lpms/app/models"
+	"lpms/app/repositories"
+	"lpms/app/response"
+	"lpms/app/vo"
+	"lpms/commom/drivers/database"
+	"lpms/commom/tools"
+	"lpms/config"
+	"lpms/exception"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// 照片按该上传类别的规则校验
+	photoCategory = "image"
+	// 缩略图最长边像素
+	thumbnailSize = 320
+)
+
+var (
+	progressPhotoServiceInstance ProgressPhotoService
+	progressPhotoOnce            sync.Once
+)
+
+type progressPhotoServiceImpl struct {
+	db           *gorm.DB
+	repo         repositories.ProgressPhotoRepo
+	progressRepo repositories.GovProgressRepo
+	govRepo      repositories.ImplementGovRepo
+	userRepo     repositories.UserRepo
+	objRepo      repositories.ObjectRepo
+	objectSvc    ObjectService
+}
+
+func GetProgressPhotoService() ProgressPhotoService {
+	progressPhotoOnce.Do(func() {
+		progressPhotoServiceInstance = &progressPhotoServiceImpl{
+			db:           database.GetDriver(),
+			repo:         repositories.GetProgressPhotoRepo(),
+			progressRepo: repositories.GetGovProgressRepo(),
+			govRepo:      repositories.GetImplementGovRepo(),
+			userRepo:     repositories.GetUserRepo(),
+			objRepo:      repositories.GetObjectRepo(),
+			objectSvc:    GetObjectService(),
+		}
+	})
+	return progressPhotoServiceInstance
+}
+
+type ProgressPhotoService interface {
+	Upload(openID string, progressID int64, description string, files []*multipart.FileHeader) ([]vo.ProgressPhotoResp, exception.Exception)
+	ListByProgressID(progressID int64) ([]vo.ProgressPhotoResp, exception.Exception)
+	Timeline(projectID int64, year int) ([]vo.ProgressPhotoMonth, exception.Exception)
+	Delete(openID string, id int64) exception.Exception
+}
+
+type progressPhotoFile struct {
+	filename  string
+	buff      []byte
+	thumbnail []byte
+	exif      *tools.Exif
+	// 存储后的原图及缩略图文件ID
+	objectID    string
+	thumbnailID string
+}
+
+// readProgressPhoto 读取照片并生成缩略图, 文件名称及大小按 image 类别的规则预先校验, 内容在存储时校验
+func readProgressPhoto(rule *config.UploadRule, fh *multipart.FileHeader) (*progressPhotoFile, exception.Exception) {
+	filename := cleanFilename(fh.Filename)
+	if ex := checkFileName(rule, filename, fh.Size); ex != nil {
+		return nil, ex
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return nil, exception.Wrap(response.ExceptionInvalidFile, err)
+	}
+	defer f.Close()
+	var reader io.Reader = f
+	if rule.MaxSize > 0 {
+		reader = io.LimitReader(f, rule.MaxSize<<20+1)
+	}
+	buff, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, exception.Wrap(response.ExceptionInvalidFile, err)
+	}
+	if ex := checkFileName(rule, filename, int64(len(buff))); ex != nil {
+		return nil, ex
+	}
+	thumbnail, err := tools.Thumbnail(buff, thumbnailSize)
+	if errors.Is(err, tools.ErrImageTooLarge) {
+		return nil, exception.New(response.ExceptionInvalidFile, fmt.Sprintf("%s 图片尺寸过大", filename))
+	}
+	if err != nil {
+		return nil, exception.New(response.ExceptionInvalidFile, fmt.Sprintf("%s 不是有效的图片文件", filename))
+	}
+	// 无EXIF信息的照片只保存文件, 拍摄时间与坐标留空
+	exif, err := tools.ParseExif(buff)
+	if err != nil {
+		exif = &tools.Exif{}
+	}
+	return &progressPhotoFile{
+		filename:  filename,
+		buff:      buff,
+		thumbnail: thumbnail,
+		exif:      exif,
+	}, nil
+}
+
+func (psi *progressPhotoServiceImpl) Upload(openID string, progressID int64, description string,
+	files []*multipart.FileHeader) ([]vo.ProgressPhotoResp, exception.Exception) {
+	if len(files) == 0 {
+		return nil, exception.New(response.ExceptionMissingParameters, "缺少照片文件")
+	}
+	progress, ex := psi.progressRepo.GetByID(psi.db, progressID)
+	if ex != nil {
+		return nil, ex
+	}
+	rule, ex := uploadRule(photoCategory)
+	if ex != nil {
+		return nil, ex
+	}
+	// 先全部校验解码, 避免部分上传
+	photos := make([]*progressPhotoFile, 0, len(files))
+	for i := range files {
+		photo, ex := readProgressPhoto(rule, files[i])
+		if ex != nil {
+			return nil, ex
+		}
+		photos = append(photos, photo)
+	}
+	// 原图及缩略图经文件服务校验、扫描后存储, 照片记录未提交时删除已存储的文件
+	uploaded := make([]string, 0, len(photos)*2)
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		if objs, ex := deleteObjects(psi.db, psi.objRepo, uploaded...); ex == nil {
+			deleteObjectContents(psi.objRepo, objs)
+		}
+	}()
+	for _, photo := range photos {
+		obj, ex := psi.objectSvc.UploadFromReader(openID, photoCategory, photo.filename, bytes.NewReader(photo.buff))
+		if ex != nil {
+			return nil, ex
+		}
+		uploaded = append(uploaded, obj.ID)
+		photo.objectID = obj.ID
+		thumbName := strings.TrimSuffix(photo.filename, filepath.Ext(photo.filename)) + "_thumb.jpg"
+		thumb, ex := psi.objectSvc.UploadFromReader(openID, photoCategory, thumbName, bytes.NewReader(photo.thumbnail))
+		if ex != nil {
+			return nil, ex
+		}
+		uploaded = append(uploaded, thumb.ID)
+		photo.thumbnailID = thumb.ID
+	}
+	tx := psi.db.Begin()
+	if tx.Error != nil {
+		return nil, exception.Wrap(response.ExceptionDatabase, tx.Error)
+	}
+	defer tx.Rollback()
+	res := make([]models.ProgressPhoto, 0, len(photos))
+	for _, photo := range photos {
+		record := models.ProgressPhoto{
+			ProjectID:   progress.ProjectID,
+			ProgressID:  progress.ID,
+			Year:        progress.Year,
+			Month:       progress.Month,
+			ObjectID:    photo.objectID,
+			ThumbnailID: photo.thumbnailID,
+			Filename:    photo.filename,
+			CaptureAt:   photo.exif.CaptureAt,
+			Latitude:    photo.exif.Latitude,
+			Longitude:   photo.exif.Longitude,
+			Description: description,
+			Base: models.Base{
+				CreateBy: openID,
+				UpdateBy: openID,
+			},
+		}
+		if ex := psi.repo.Create(tx, &record); ex != nil {
+			return nil, ex
+		}
+		res = append(res, record)
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, exception.Wrap(response.ExceptionDatabase, err)
+	}
+	committed = true
+	return vo.NewProgressPhotoResponses(res), nil
+}
+
+func (psi *progressPhotoServiceImpl) ListByProgressID(progressID int64) ([]vo.ProgressPhotoResp, exception.Exception) {
+	res, ex := psi.repo.ListByProgressID(psi.db, progressID)
+	if ex != nil {
+		return nil, ex
+	}
+	return vo.NewProgressPhotoResponses(res), nil
+}
+
+func (psi *progressPhotoServiceImpl) Timeline(projectID int64, year int) ([]vo.ProgressPhotoMonth, exception.Exception) {
+	res, ex := psi.repo.ListByProjectID(psi.db, projectID, year)
+	if ex != nil {
+		return nil, ex
+	}
+	return vo.NewProgressPhotoTimeline(res), nil
+}
+
+// Delete 删除照片及其文件, 仅上传者、项目创建者及管理员可删除
+func (psi *progressPhotoServiceImpl) Delete(openID string, id int64) exception.Exception {
+	photo, ex := psi.repo.Get(psi.db, id)
+	if ex != nil {
+		return ex
+	}
+	if ex := checkGovProjectOwner(psi.db, psi.govRepo, psi.userRepo, openID, photo.CreateBy,
+		photo.ProjectID); ex != nil {
+		return ex
+	}
+	tx := psi.db.Begin()
+	if tx.Error != nil {
+		return exception.Wrap(response.ExceptionDatabase, tx.Error)
+	}
+	defer tx.Rollback()
+	if ex := psi.repo.Delete(tx, id); ex != nil {
+		return ex
+	}
+	objs, ex := deleteObjects(tx, psi.objRepo, progressPhotoObjectIDs(*photo)...)
+	if ex != nil {
+		return ex
+	}
+	if err := tx.Commit().Error; err != nil {
+		return exception.Wrap(response.ExceptionDatabase, err)
+	}
+	deleteObjectContents(psi.objRepo, objs)
+	return nil
+}
+
+// progressPhotoObjectIDs 照片原图及缩略图文件ID
+func progressPhotoObjectIDs(photos ...models.ProgressPhoto) []string {
+	ids := make([]string, 0, len(photos)*2)
+	for i := range photos {
+		ids = append(ids, photos[i].ObjectID, photos[i].ThumbnailID)
+	}
+	return ids
+}
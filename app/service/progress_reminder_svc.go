@@ -0,0 +1,152 @@
+package service
+
+import (
+	"log"
+	"lpms/app/repositories"
+	"lpms/app/response"
+	"lpms/app/vo"
+	"lpms/commom/drivers/database"
+	"lpms/exception"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// 提醒任务创建者
+	systemUser = "system"
+	// 未设置填报窗口期时默认每月25日截止填报当月进度
+	defaultProgressDeadlineDay = 25
+)
+
+var (
+	progressReminderServiceInstance ProgressReminderService
+	progressReminderOnce            sync.Once
+)
+
+type progressReminderServiceImpl struct {
+	db         *gorm.DB
+	repo       repositories.ProgressReminderRepo
+	userRepo   repositories.UserRepo
+	windowRepo repositories.WindowRepo
+}
+
+func GetProgressReminderService() ProgressReminderService {
+	progressReminderOnce.Do(func() {
+		progressReminderServiceInstance = &progressReminderServiceImpl{
+			db:         database.GetDriver(),
+			repo:       repositories.GetProgressReminderRepo(),
+			userRepo:   repositories.GetUserRepo(),
+			windowRepo: repositories.GetWindowRepo(),
+		}
+	})
+	return progressReminderServiceInstance
+}
+
+type ProgressReminderService interface {
+	Run(now time.Time) exception.Exception
+	Generate(user string, year, month int) (int64, exception.Exception)
+	List(user string, params *vo.ProgressReminderFilterParam, pageInfo *vo.PageInfo) (*vo.DataPagination, exception.Exception)
+	Compliance(year, month int) (*vo.ComplianceResp, exception.Exception)
+}
+
+// progressDeadlineDay 窗口期设置中项目进度填报的截止日, 未设置或设置无效时取默认值
+func (psi *progressReminderServiceImpl) progressDeadlineDay() (int, exception.Exception) {
+	settings, ex := psi.windowRepo.List(psi.db)
+	if ex != nil {
+		return 0, ex
+	}
+	if len(settings) == 0 || len(settings[0].ProgressSetting) == 0 {
+		return defaultProgressDeadlineDay, nil
+	}
+	window, err := vo.ParseProgressWindow(settings[0].ProgressSetting)
+	if err != nil {
+		log.Printf("progress window setting %s invalid, use day %d: %v", settings[0].ProgressSetting,
+			defaultProgressDeadlineDay, err)
+		return defaultProgressDeadlineDay, nil
+	}
+	return window.EndDay, nil
+}
+
+// closedPeriod 每月 day 日截止填报时最近一个已截止填报的月份
+func closedPeriod(now time.Time, day int) (int, int) {
+	if now.Day() <= day {
+		now = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -1, 0)
+	}
+	return now.Year(), int(now.Month())
+}
+
+// Run 定时任务: 为最近截止月份生成逾期提醒, 并将已补报的提醒标记为已处理
+func (psi *progressReminderServiceImpl) Run(now time.Time) exception.Exception {
+	day, ex := psi.progressDeadlineDay()
+	if ex != nil {
+		return ex
+	}
+	year, month := closedPeriod(now, day)
+	if _, ex := psi.repo.Generate(psi.db, systemUser, year, month); ex != nil {
+		return ex
+	}
+	_, ex = psi.repo.Resolve(psi.db)
+	return ex
+}
+
+func (psi *progressReminderServiceImpl) Generate(user string, year, month int) (int64, exception.Exception) {
+	userInfo, ex := psi.userRepo.Get(psi.db, user)
+	if ex != nil {
+		return 0, ex
+	}
+	if !userInfo.IsAdmin {
+		return 0, exception.New(response.ExceptionForbidden, "当前操作无权限")
+	}
+	if month < 1 || month > 12 {
+		return 0, exception.New(response.ExceptionInvalidRequestParameters, "无效月份")
+	}
+	count, ex := psi.repo.Generate(psi.db, user, year, month)
+	if ex != nil {
+		return 0, ex
+	}
+	if _, ex := psi.repo.Resolve(psi.db); ex != nil {
+		return 0, ex
+	}
+	return count, nil
+}
+
+func (psi *progressReminderServiceImpl) List(user string, params *vo.ProgressReminderFilterParam, pageInfo *vo.PageInfo) (
+	*vo.DataPagination, exception.Exception) {
+	userInfo, ex := psi.userRepo.Get(psi.db, user)
+	if ex != nil {
+		return nil, ex
+	}
+	count, reminders, ex := psi.repo.List(psi.db, pageInfo, params, userInfo.IsAdmin, user)
+	if ex != nil {
+		return nil, ex
+	}
+	return vo.NewDataPagination(count, vo.NewProgressReminderResponses(reminders), pageInfo), nil
+}
+
+func (psi *progressReminderServiceImpl) Compliance(year, month int) (*vo.ComplianceResp, exception.Exception) {
+	if month < 1 || month > 12 {
+		return nil, exception.New(response.ExceptionInvalidRequestParameters, "无效月份")
+	}
+	units, ex := psi.repo.ComplianceStat(psi.db, "duty_unit", year, month)
+	if ex != nil {
+		return nil, ex
+	}
+	townships, ex := psi.repo.ComplianceStat(psi.db, "township", year, month)
+	if ex != nil {
+		return nil, ex
+	}
+	total, submitted := int64(0), int64(0)
+	for i := range units {
+		total += units[i].Total
+		submitted += units[i].Submitted
+	}
+	return &vo.ComplianceResp{
+		Year:      year,
+		Month:     month,
+		Summary:   vo.NewComplianceRate("全区", total, submitted),
+		DutyUnits: vo.NewComplianceRates(units),
+		Townships: vo.NewComplianceRates(townships),
+	}, nil
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"fmt"
+	"lpms/app/models"
+	"lpms/app/vo"
+	"lpms/commom/xlsx"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+const (
+	noDutyUnit    = "未明确责任单位"
+	noProjectType = "未分类"
+)
+
+var progressReportColumns = []exportColumn{
+	{"序号", 6}, {"项目名称", 30}, {"项目编码", 16}, {"所属街镇", 12}, {"总投资", 12}, {"当年计划投资", 12},
+	{"本月计划投资", 12}, {"本月完成投资", 12}, {"当年累计完成", 12}, {"当年完成率", 10}, {"开工至今累计完成", 14},
+	{"本月计划形象进度", 30}, {"本月完成形象进度", 30}, {"存在问题", 30}, {"填报状态", 10},
+}
+
+// ProgressReportFilename 月度进展情况表文件名
+func ProgressReportFilename(year, month int) string {
+	return fmt.Sprintf("政府投资项目月度进展情况表_%d年%d月.xlsx", year, month)
+}
+
+type progressReportRow struct {
+	project  *models.ImplementGov
+	progress *models.GovProgress
+	sum      models.InvestmentSum
+	yearPlan float64
+}
+
+// progressReportTotal 小计/合计
+type progressReportTotal struct {
+	count     int
+	total     float64
+	yearPlan  float64
+	monthPlan float64
+	monthDone float64
+	yearDone  float64
+	startDone float64
+}
+
+func (t *progressReportTotal) add(r *progressReportRow) {
+	t.count++
+	t.total += deref(r.project.TotalInvestment)
+	t.yearPlan += r.yearPlan
+	t.yearDone += r.sum.YearInvested
+	t.startDone += r.sum.StartInvested
+	if r.progress != nil {
+		t.monthPlan += deref(r.progress.PlanInvest)
+		t.monthDone += deref(r.progress.PlanInvested)
+	}
+}
+
+func (t *progressReportTotal) merge(o *progressReportTotal) {
+	t.count += o.count
+	t.total += o.total
+	t.yearPlan += o.yearPlan
+	t.monthPlan += o.monthPlan
+	t.monthDone += o.monthDone
+	t.yearDone += o.yearDone
+	t.startDone += o.startDone
+}
+
+func deref(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+func completeRate(done, plan float64) string {
+	if plan <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.1f%%", done/plan*100)
+}
+
+// exportProgressReport 按责任单位、项目类型分组生成月度进展情况表, 含分类小计、单位合计及总计
+func exportProgressReport(year, month int, rows []progressReportRow) *xlsx.File {
+	file := xlsx.NewFile()
+	sheet := file.AddSheet(fmt.Sprintf("%d年%d月", year, month))
+	last := len(progressReportColumns) - 1
+	for i := range progressReportColumns {
+		sheet.SetColWidth(i, progressReportColumns[i].width)
+	}
+	sheet.AddStyledRow(xlsx.StyleTitle, "政府投资项目月度进展情况表")
+	sheet.Merge(0, 0, 0, last)
+	sheet.AddRow(fmt.Sprintf("统计期间: %d年%d月    单位: 万元", year, month))
+	sheet.Merge(1, 0, 1, last)
+	header := make([]interface{}, 0, len(progressReportColumns))
+	for i := range progressReportColumns {
+		header = append(header, progressReportColumns[i].title)
+	}
+	sheet.AddStyledRow(xlsx.StyleHeader, header...)
+	sheet.FreezeRows = sheet.RowCount()
+
+	groups := make(map[string]map[int][]*progressReportRow)
+	for i := range rows {
+		unit := rows[i].project.DutyUint
+		if unit == "" {
+			unit = noDutyUnit
+		}
+		projectType := -1
+		if rows[i].project.ProjectType != nil {
+			projectType = *rows[i].project.ProjectType
+		}
+		if groups[unit] == nil {
+			groups[unit] = make(map[int][]*progressReportRow)
+		}
+		groups[unit][projectType] = append(groups[unit][projectType], &rows[i])
+	}
+	units := make([]string, 0, len(groups))
+	for unit := range groups {
+		units = append(units, unit)
+	}
+	sort.Slice(units, func(i, j int) bool {
+		if (units[i] == noDutyUnit) != (units[j] == noDutyUnit) {
+			return units[j] == noDutyUnit
+		}
+		return units[i] < units[j]
+	})
+
+	addTotal := func(label string, t *progressReportTotal) {
+		sheet.AddStyledRow(xlsx.StyleBold, fmt.Sprintf("%s(%d个)", label, t.count), "", "", "",
+			t.total, t.yearPlan, t.monthPlan, t.monthDone, t.yearDone, completeRate(t.yearDone, t.yearPlan), t.startDone,
+			"", "", "", "")
+		r := sheet.RowCount() - 1
+		sheet.Merge(r, 0, r, 3)
+	}
+
+	seq := 0
+	grand := &progressReportTotal{}
+	for _, unit := range units {
+		sheet.AddStyledRow(xlsx.StyleBold, unit)
+		r := sheet.RowCount() - 1
+		sheet.Merge(r, 0, r, last)
+
+		types := make([]int, 0, len(groups[unit]))
+		for projectType := range groups[unit] {
+			types = append(types, projectType)
+		}
+		// 未分类排最后
+		sort.Slice(types, func(i, j int) bool {
+			if (types[i] < 0) != (types[j] < 0) {
+				return types[j] < 0
+			}
+			return types[i] < types[j]
+		})
+		unitTotal := &progressReportTotal{}
+		for _, projectType := range types {
+			typeTotal := &progressReportTotal{}
+			for _, row := range groups[unit][projectType] {
+				seq++
+				addProgressReportRow(sheet, seq, row)
+				typeTotal.add(row)
+			}
+			name := noProjectType
+			if projectType >= 0 {
+				name = vo.Label(vo.ProjectTypeNames, &projectType)
+			}
+			addTotal(name+"小计", typeTotal)
+			unitTotal.merge(typeTotal)
+		}
+		addTotal(unit+"合计", unitTotal)
+		grand.merge(unitTotal)
+	}
+	addTotal("总计", grand)
+	return file
+}
+
+func addProgressReportRow(sheet *xlsx.Sheet, seq int, r *progressReportRow) {
+	p := r.project
+	var monthPlan, monthDone *float64
+	planProgress, actualProgress, problem, status := "", "", "", "未填报"
+	if r.progress != nil {
+		monthPlan, monthDone = r.progress.PlanInvest, r.progress.PlanInvested
+		planProgress, actualProgress = r.progress.PlanProgress, r.progress.ActualProgress
+		problem = problemText(r.progress.ProblemDetail)
+		status = "未提交"
+		if r.progress.Status == 1 {
+			status = "已提交"
+		}
+	}
+	sheet.AddRow(seq, p.Name, p.ProjectCode, p.Township, p.TotalInvestment, r.yearPlan, monthPlan, monthDone,
+		r.sum.YearInvested, completeRate(r.sum.YearInvested, r.yearPlan), r.sum.StartInvested,
+		planProgress, actualProgress, problem, status)
+}
+
+// problemText 需协调问题详情转为文本, 结构不固定, 按顺序取出全部文本内容
+func problemText(raw json.RawMessage) string {
+	var v interface{}
+	if len(raw) == 0 || json.Unmarshal(raw, &v) != nil {
+		return strings.TrimSpace(string(raw))
+	}
+	if s, ok := v.(string); ok {
+		// 前端以字符串提交时可能二次编码
+		if inner := problemText(json.RawMessage(s)); inner != "" && inner != s {
+			return inner
+		}
+		return strings.TrimSpace(s)
+	}
+	if items, ok := v.([]interface{}); ok {
+		lines := make([]string, 0, len(items))
+		for _, item := range items {
+			if text := strings.Join(textLeaves(item), " "); text != "" {
+				lines = append(lines, fmt.Sprintf("%d. %s", len(lines)+1, text))
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+	return strings.Join(textLeaves(v), " ")
+}
+
+func textLeaves(v interface{}) []string {
+	res := make([]string, 0)
+	switch t := v.(type) {
+	case string:
+		if s := strings.TrimSpace(t); s != "" {
+			res = append(res, s)
+		}
+	case []interface{}:
+		for _, item := range t {
+			res = append(res, textLeaves(item)...)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			res = append(res, textLeaves(t[k])...)
+		}
+	}
+	return res
+}
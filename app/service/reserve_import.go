@@ -0,0 +1,413 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"lpms/app/models"
+	"lpms/app/response"
+	"lpms/app/vo"
+	"lpms/commom/xlsx"
+	"lpms/constant"
+	"lpms/exception"
+	"math"
+	"mime/multipart"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxImportRows 单次导入的最大数据行数
+const maxImportRows = 1000
+
+type importColumn struct {
+	title    string
+	width    float64
+	required bool
+	// field 对应 ReserveReq 的 json 字段, 用于定位校验错误所在列
+	field string
+	hint  string
+	set   func(req *vo.ReserveReq, v string) error
+}
+
+var reserveImportColumns = []importColumn{
+	{"项目名称", 30, true, "name", "不超过60字, 同名项目不可重复导入",
+		func(r *vo.ReserveReq, v string) error { r.Name = v; return nil }},
+	{"项目级别", 10, true, "level", enumHint(vo.LevelNames),
+		func(r *vo.ReserveReq, v string) (err error) { r.Level, err = parseImportEnum(vo.LevelNames, v); return }},
+	{"项目类型", 12, true, "project_type", enumHint(vo.ProjectTypeNames),
+		func(r *vo.ReserveReq, v string) (err error) {
+			r.ProjectType, err = parseImportEnum(vo.ProjectTypeNames, v)
+			return
+		}},
+	{"建设主体", 20, true, "construct_subject", "不超过60字",
+		func(r *vo.ReserveReq, v string) error { r.ConstructSubject = v; return nil }},
+	{"建设地点", 24, false, "construct_site", "不超过200字",
+		func(r *vo.ReserveReq, v string) error { r.ConstructSite = v; return nil }},
+	{"项目位置", 22, false, "location", "经度,纬度 eg: 120.1234,30.5678",
+		func(r *vo.ReserveReq, v string) (err error) { r.Location, err = parseImportPoint(v); return }},
+	{"重点类型", 16, false, "point_type", enumHint(vo.PointTypeNames),
+		func(r *vo.ReserveReq, v string) (err error) {
+			r.PointType, err = parseImportEnum(vo.PointTypeNames, v)
+			return
+		}},
+	{"实施类型", 10, false, "implement_type", enumHint(vo.ImplementTypeNames),
+		func(r *vo.ReserveReq, v string) (err error) {
+			r.ImplementType, err = parseImportEnum(vo.ImplementTypeNames, v)
+			return
+		}},
+	{"入库类别", 10, false, "enter_db_type", enumHint(vo.EnterDBTypeNames),
+		func(r *vo.ReserveReq, v string) (err error) {
+			r.EnterDBType, err = parseImportEnum(vo.EnterDBTypeNames, v)
+			return
+		}},
+	{"计划开工时间", 14, false, "plan_begin", "日期, eg: 2022-03-01",
+		func(r *vo.ReserveReq, v string) (err error) { r.PlanBegin, err = parseImportDate(v); return }},
+	{"建设周期(月)", 12, false, "period", "正整数",
+		func(r *vo.ReserveReq, v string) (err error) { r.Period, err = parseImportInt(v); return }},
+	{"总投资(万)", 14, true, "total_investment", "数字",
+		func(r *vo.ReserveReq, v string) (err error) { r.TotalInvestment, err = parseImportFloat(v); return }},
+	{"工程费用(万)", 14, false, "project_consumption", "数字",
+		func(r *vo.ReserveReq, v string) (err error) { r.ProjectComsumption, err = parseImportFloat(v); return }},
+	{"征迁/土地费用(万)", 16, false, "move_land_comsumption", "数字",
+		func(r *vo.ReserveReq, v string) (err error) { r.MoveLandComsumption, err = parseImportFloat(v); return }},
+	{"建设内容及规模", 40, false, "construct_content_scope", "",
+		func(r *vo.ReserveReq, v string) error { r.ConstructContentScope = v; return nil }},
+	{"建设依据及必要性", 40, false, "construct_basis_necessity", "",
+		func(r *vo.ReserveReq, v string) error { r.ConstructBasisNecessity = v; return nil }},
+	{"是否有用地情况", 12, false, "is_land_use", "是/否",
+		func(r *vo.ReserveReq, v string) (err error) { r.IsLandUse, err = parseImportBool(v); return }},
+	{"总用亩", 10, false, "total", "数字",
+		func(r *vo.ReserveReq, v string) (err error) { r.Total, err = parseImportFloat(v); return }},
+	{"新增建设用地", 12, false, "add", "数字",
+		func(r *vo.ReserveReq, v string) (err error) { r.Add, err = parseImportFloat(v); return }},
+	{"不符合土地利用规划面积", 18, false, "no_conform_use_plan", "数字",
+		func(r *vo.ReserveReq, v string) (err error) { r.NoConformUsePlan, err = parseImportFloat(v); return }},
+	{"选址红线", 10, false, "site_red", enumHint(vo.SiteRedNames),
+		func(r *vo.ReserveReq, v string) (err error) {
+			r.SiteRed, err = parseImportEnum(vo.SiteRedNames, v)
+			return
+		}},
+	{"需征地面积", 12, false, "need_collect", "数字",
+		func(r *vo.ReserveReq, v string) (err error) { r.NeedCollect, err = parseImportFloat(v); return }},
+	{"需拆迁农户/居民数(人)", 18, false, "need_people_move", "整数",
+		func(r *vo.ReserveReq, v string) (err error) { r.NeedPeopleMove, err = parseImportInt(v); return }},
+	{"企/事业单位(家)", 14, false, "company_business", "整数",
+		func(r *vo.ReserveReq, v string) (err error) { r.CompanyBusiness, err = parseImportInt(v); return }},
+	{"前期工作联系人", 14, false, "contract", "不超过20字",
+		func(r *vo.ReserveReq, v string) error { r.Contract = v; return nil }},
+	{"联系人手机号", 14, false, "phone", "手机号或座机号",
+		func(r *vo.ReserveReq, v string) error { r.Phone = v; return nil }},
+}
+
+// reserveImportTemplate 导入模板: 第一个工作表填写数据, 第二个工作表为填写说明
+func reserveImportTemplate() *xlsx.File {
+	file := xlsx.NewFile()
+	columns := make([]exportColumn, 0, len(reserveImportColumns))
+	for _, c := range reserveImportColumns {
+		title := c.title
+		if c.required {
+			title = "*" + title
+		}
+		columns = append(columns, exportColumn{title, c.width})
+	}
+	newExportSheet(file, "储备库项目导入", columns)
+
+	guide := newExportSheet(file, "填写说明", []exportColumn{{"列名", 22}, {"是否必填", 10}, {"填写说明", 100}})
+	for _, c := range reserveImportColumns {
+		guide.AddRow(c.title, c.required, c.hint)
+	}
+	guide.AddRow("", "", "")
+	guide.AddRow("说明", "", fmt.Sprintf("从第2行开始填写, 每行一个项目, 单次最多%d行; 枚举列可填写名称或编号; 导入后为草稿状态, 请补充资金详情及附件后提交", maxImportRows))
+	return file
+}
+
+type importRow struct {
+	line int
+	req  *vo.ReserveReq
+}
+
+// parseReserveImport 解析并校验导入文件, 返回通过校验的行及逐行错误, 数据行数
+func parseReserveImport(fh *multipart.FileHeader) ([]importRow, []vo.ImportRowError, int, exception.Exception) {
+	if !strings.EqualFold(filepath.Ext(fh.Filename), ".xlsx") {
+		return nil, nil, 0, exception.New(response.ExceptionInvalidFile, "only .xlsx is supported")
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return nil, nil, 0, exception.Wrap(response.ExceptionInvalidFile, err)
+	}
+	defer f.Close()
+	// 含表头行
+	rows, err := xlsx.ReadRows(f, fh.Size, maxImportRows+1)
+	if errors.Is(err, xlsx.ErrTooManyRows) {
+		return nil, nil, 0, exception.New(response.ExceptionInvalidFile, fmt.Sprintf("too many rows, max %d", maxImportRows))
+	}
+	if err != nil {
+		return nil, nil, 0, exception.Wrap(response.ExceptionInvalidFile, err)
+	}
+	if len(rows) == 0 {
+		return nil, nil, 0, exception.New(response.ExceptionInvalidFile, "empty sheet")
+	}
+
+	// 按表头名称定位列, 允许调整列顺序或删除非必填列
+	index := make(map[string]int)
+	for i, title := range rows[0] {
+		index[normalizeTitle(title)] = i
+	}
+	errs := make([]vo.ImportRowError, 0)
+	cols := make([]int, len(reserveImportColumns))
+	for i, c := range reserveImportColumns {
+		col, ok := index[normalizeTitle(c.title)]
+		if !ok {
+			col = -1
+			if c.required {
+				errs = append(errs, vo.ImportRowError{Row: 1, Column: c.title, Message: "缺少必填列"})
+			}
+		}
+		cols[i] = col
+	}
+	if len(errs) > 0 {
+		return nil, errs, 0, nil
+	}
+
+	total := 0
+	parsed := make([]importRow, 0)
+	for r := 1; r < len(rows); r++ {
+		if isBlankRow(rows[r]) {
+			continue
+		}
+		total++
+		if total > maxImportRows {
+			return nil, nil, 0, exception.New(response.ExceptionInvalidFile, fmt.Sprintf("too many rows, max %d", maxImportRows))
+		}
+		line := r + 1
+		req := &vo.ReserveReq{}
+		rowErrs := make([]vo.ImportRowError, 0)
+		for i, c := range reserveImportColumns {
+			v := ""
+			if cols[i] >= 0 && cols[i] < len(rows[r]) {
+				v = strings.TrimSpace(rows[r][cols[i]])
+			}
+			if v == "" {
+				if c.required {
+					rowErrs = append(rowErrs, vo.ImportRowError{Row: line, Column: c.title, Message: "不能为空"})
+				}
+				continue
+			}
+			if err := c.set(req, v); err != nil {
+				rowErrs = append(rowErrs, vo.ImportRowError{Row: line, Column: c.title, Message: err.Error()})
+			}
+		}
+		for _, fe := range req.Validate() {
+			if hasColumnError(rowErrs, fe.Field) {
+				continue
+			}
+			rowErrs = append(rowErrs, vo.ImportRowError{Row: line, Column: columnTitle(fe.Field), Message: fe.Message})
+		}
+		if len(rowErrs) > 0 {
+			errs = append(errs, rowErrs...)
+			continue
+		}
+		parsed = append(parsed, importRow{line: line, req: req})
+	}
+
+	// 同一文件内项目名称重复, 保留首行
+	valid := make([]importRow, 0, len(parsed))
+	seen := make(map[string]int)
+	for _, row := range parsed {
+		if first, ok := seen[row.req.Name]; ok {
+			errs = append(errs, vo.ImportRowError{Row: row.line, Column: "项目名称", Message: fmt.Sprintf("与第%d行项目名称重复", first)})
+			continue
+		}
+		seen[row.req.Name] = row.line
+		valid = append(valid, row)
+	}
+	return valid, errs, total, nil
+}
+
+func normalizeTitle(s string) string {
+	s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "*"))
+	return strings.NewReplacer(" ", "", "（", "(", "）", ")").Replace(s)
+}
+
+func isBlankRow(cells []string) bool {
+	for _, c := range cells {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func columnTitle(field string) string {
+	for _, c := range reserveImportColumns {
+		if c.field == field {
+			return c.title
+		}
+	}
+	return field
+}
+
+func hasColumnError(errs []vo.ImportRowError, field string) bool {
+	title := columnTitle(field)
+	for i := range errs {
+		if errs[i].Column == title {
+			return true
+		}
+	}
+	return false
+}
+
+func sortImportErrors(errs []vo.ImportRowError) {
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Row < errs[j].Row
+	})
+}
+
+func enumHint(names map[int]string) string {
+	keys := make([]int, 0, len(names))
+	for k := range names {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	items := make([]string, 0, len(keys))
+	for _, k := range keys {
+		items = append(items, fmt.Sprintf("%d:%s", k, names[k]))
+	}
+	return strings.Join(items, ", ")
+}
+
+// parseImportEnum 枚举列可填写中文名称或编号
+func parseImportEnum(names map[int]string, v string) (*int, error) {
+	for k, name := range names {
+		if name == v {
+			n := k
+			return &n, nil
+		}
+	}
+	n, err := parseImportInt(v)
+	if err != nil {
+		return nil, fmt.Errorf("取值无效: %s", v)
+	}
+	return n, nil
+}
+
+func parseImportFloat(v string) (*float64, error) {
+	f, err := strconv.ParseFloat(strings.ReplaceAll(v, ",", ""), 64)
+	if err != nil {
+		return nil, fmt.Errorf("不是有效数字: %s", v)
+	}
+	return &f, nil
+}
+
+// parseImportInt 数字单元格可能带小数形式, eg: 12.0
+func parseImportInt(v string) (*int, error) {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f != math.Trunc(f) {
+		return nil, fmt.Errorf("不是有效整数: %s", v)
+	}
+	n := int(f)
+	return &n, nil
+}
+
+func parseImportBool(v string) (*bool, error) {
+	var b bool
+	switch strings.ToUpper(v) {
+	case "是", "有", "TRUE", "1", "Y", "YES":
+		b = true
+	case "否", "无", "FALSE", "0", "N", "NO":
+		b = false
+	default:
+		return nil, fmt.Errorf("请填写是或否: %s", v)
+	}
+	return &b, nil
+}
+
+// parseImportPoint 经度,纬度; 兼容中文逗号及空格分隔
+func parseImportPoint(v string) (*vo.GeoPoint, error) {
+	parts := strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == '，' || r == ' ' })
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("请填写经度,纬度: %s", v)
+	}
+	lng, err1 := strconv.ParseFloat(parts[0], 64)
+	lat, err2 := strconv.ParseFloat(parts[1], 64)
+	if err1 != nil || err2 != nil {
+		return nil, fmt.Errorf("不是有效经纬度: %s", v)
+	}
+	return vo.NewGeoPoint(lng, lat), nil
+}
+
+var importDateLayouts = []string{
+	"2006-01-02", "2006/01/02", "2006-1-2", "2006/1/2", "2006.1.2", "2006年1月2日",
+	"2006-01", "2006/01", "2006-1", "2006/1", "2006年1月",
+}
+
+// parseImportDate 兼容日期格式单元格(Excel 序列号)与文本日期
+func parseImportDate(v string) (*time.Time, error) {
+	if serial, err := strconv.ParseFloat(v, 64); err == nil && serial > 0 && serial < 2958466 {
+		t := xlsx.ExcelTime(serial)
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+		return &t, nil
+	}
+	for _, layout := range importDateLayouts {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("日期格式错误: %s", v)
+}
+
+// importReserves 校验导入数据; confirm 为 true 时在同一事务内以草稿状态写入全部通过校验的行
+func (rsi *reserveServiceImpl) importReserves(openID string, fh *multipart.FileHeader, confirm bool) (*vo.ImportResp, exception.Exception) {
+	rows, errs, total, ex := parseReserveImport(fh)
+	if ex != nil {
+		return nil, ex
+	}
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, row.req.Name)
+	}
+	exists, ex := rsi.repo.ExistNames(rsi.db, names)
+	if ex != nil {
+		return nil, ex
+	}
+	existSet := make(map[string]bool, len(exists))
+	for _, name := range exists {
+		existSet[name] = true
+	}
+	projects := make([]*models.ReservePro, 0, len(rows))
+	for _, row := range rows {
+		if existSet[row.req.Name] {
+			errs = append(errs, vo.ImportRowError{Row: row.line, Column: "项目名称", Message: "项目名称已存在"})
+			continue
+		}
+		row.req.Status = constant.Draft
+		projects = append(projects, row.req.ToModel(openID))
+	}
+	sortImportErrors(errs)
+
+	resp := &vo.ImportResp{
+		Confirmed: confirm,
+		Total:     total,
+		Valid:     len(projects),
+		Invalid:   total - len(projects),
+		Errors:    errs,
+	}
+	if !confirm || len(projects) == 0 {
+		return resp, nil
+	}
+	tx := rsi.db.Begin()
+	if tx.Error != nil {
+		return nil, exception.Wrap(response.ExceptionDatabase, tx.Error)
+	}
+	defer tx.Rollback()
+	if ex := rsi.repo.MultiCreate(tx, projects); ex != nil {
+		return nil, ex
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, exception.Wrap(response.ExceptionDatabase, err)
+	}
+	resp.Imported = len(projects)
+	return resp, nil
+}
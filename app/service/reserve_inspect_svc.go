@@ -22,6 +22,7 @@ type reserveInspectServiceImpl struct {
 	repo        repositories.ReserveInspectRepo
 	reserveRepo repositories.ReserveRepo
 	GovRepo     repositories.ImplementGovRepo
+	attachRepo  repositories.AttachmentRepo
 }
 
 func GetReserveInspectService() ReserveInspectService {
@@ -31,6 +32,7 @@ func GetReserveInspectService() ReserveInspectService {
 			repo:        repositories.GetReserveInspectRepo(),
 			reserveRepo: repositories.GetReserveRepo(),
 			GovRepo:     repositories.GetImplementGovRepo(),
+			attachRepo:  repositories.GetAttachmentRepo(),
 		}
 	})
 	return reserveInspectServiceInstance
@@ -110,7 +112,11 @@ func (ris *reserveInspectServiceImpl) OutStoragePass(openID string, id int64) ex
 		return ex
 	}
 	gov := pro.ToGovReserveModel(openID)
-	if ex = ris.GovRepo.Create(ris.db, gov); ex != nil {
+	if ex = ris.GovRepo.Create(tx, gov); ex != nil {
+		return ex
+	}
+	// 项目附件随项目进入实施库
+	if ex = ris.attachRepo.Copy(tx, constant.AttachReserve, id, constant.AttachGov, gov.ID); ex != nil {
 		return ex
 	}
 	if err := tx.Commit(); err.Error != nil {
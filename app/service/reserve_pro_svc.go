@@ -1,12 +1,15 @@
 package service
 
 import (
+	"lpms/app/models"
 	"lpms/app/repositories"
 	"lpms/app/response"
 	"lpms/app/vo"
 	"lpms/commom/drivers/database"
+	"lpms/commom/xlsx"
 	"lpms/constant"
 	"lpms/exception"
+	"mime/multipart"
 	"strconv"
 	"strings"
 	"sync"
@@ -20,19 +23,21 @@ var (
 )
 
 type reserveServiceImpl struct {
-	db       *gorm.DB
-	repo     repositories.ReserveRepo
-	objRepo  repositories.ObjectRepo
-	userRepo repositories.UserRepo
+	db         *gorm.DB
+	repo       repositories.ReserveRepo
+	objRepo    repositories.ObjectRepo
+	userRepo   repositories.UserRepo
+	attachRepo repositories.AttachmentRepo
 }
 
 func GetReserveService() ReserveService {
 	reserveOnce.Do(func() {
 		reserveServiceInstance = &reserveServiceImpl{
-			db:       database.GetDriver(),
-			repo:     repositories.GetReserveRepo(),
-			objRepo:  repositories.GetObjectRepo(),
-			userRepo: repositories.GetUserRepo(),
+			db:         database.GetDriver(),
+			repo:       repositories.GetReserveRepo(),
+			objRepo:    repositories.GetObjectRepo(),
+			userRepo:   repositories.GetUserRepo(),
+			attachRepo: repositories.GetAttachmentRepo(),
 		}
 	})
 	return reserveServiceInstance
@@ -42,6 +47,9 @@ type ReserveService interface {
 	Create(openID string, param *vo.ReserveReq) exception.Exception
 	Get(id int64) (*vo.ReserveResp, exception.Exception)
 	List(user string, params *vo.ReserveFilterParam, pageInfo *vo.PageInfo) (*vo.DataPagination, exception.Exception)
+	Export(user string, params *vo.ReserveFilterParam) (*xlsx.File, exception.Exception)
+	ImportTemplate() *xlsx.File
+	Import(openID string, fh *multipart.FileHeader, confirm bool) (*vo.ImportResp, exception.Exception)
 	Update(openID string, id int64, param *vo.ReserveUpdateReq) exception.Exception
 	Delete(id int64) exception.Exception
 	MultiDelete(ids string) exception.Exception
@@ -53,8 +61,30 @@ type ReserveService interface {
 }
 
 func (rsi *reserveServiceImpl) Create(openID string, param *vo.ReserveReq) exception.Exception {
+	if errs := param.Validate(); len(errs) > 0 {
+		return exception.Wrap(response.ExceptionInvalidRequestParameters, errs[0])
+	}
+	if ex := checkObjectRefs(rsi.db, rsi.objRepo, rsi.userRepo, openID, nil,
+		param.UploadCadID, param.SitePhoto); ex != nil {
+		return ex
+	}
+	tx := rsi.db.Begin()
+	if tx.Error != nil {
+		return exception.Wrap(response.ExceptionDatabase, tx.Error)
+	}
+	defer tx.Rollback()
 	reserve := param.ToModel(openID)
-	return rsi.repo.Create(rsi.db, reserve)
+	if ex := rsi.repo.Create(tx, reserve); ex != nil {
+		return ex
+	}
+	if ex := syncLegacyAttachments(tx, rsi.attachRepo, constant.AttachReserve, reserve.ID, openID,
+		[2]string{}, [2]string{reserve.UploadCadID, reserve.SitePhoto}); ex != nil {
+		return ex
+	}
+	if err := tx.Commit().Error; err != nil {
+		return exception.Wrap(response.ExceptionDatabase, err)
+	}
+	return nil
 }
 
 func (rsi *reserveServiceImpl) Get(id int64) (*vo.ReserveResp, exception.Exception) {
@@ -99,24 +129,46 @@ func (rsi *reserveServiceImpl) List(user string, params *vo.ReserveFilterParam,
 }
 
 func (rsi *reserveServiceImpl) Update(openID string, id int64, param *vo.ReserveUpdateReq) exception.Exception {
+	if errs := param.Validate(); len(errs) > 0 {
+		return exception.Wrap(response.ExceptionInvalidRequestParameters, errs[0])
+	}
 	pro, ex := rsi.repo.Get(rsi.db, id)
 	if ex != nil {
 		return ex
 	}
+	if ex := checkObjectRefs(rsi.db, rsi.objRepo, rsi.userRepo, openID,
+		map[string]bool{pro.UploadCadID: true, pro.SitePhoto: true}, param.UploadCadID, param.SitePhoto); ex != nil {
+		return ex
+	}
+	tx := rsi.db.Begin()
+	if tx.Error != nil {
+		return exception.Wrap(response.ExceptionDatabase, tx.Error)
+	}
+	defer tx.Rollback()
+	if ex := rsi.repo.Update(tx, id, param.ToMap(openID)); ex != nil {
+		return ex
+	}
+	if ex := syncLegacyAttachments(tx, rsi.attachRepo, constant.AttachReserve, id, openID,
+		[2]string{pro.UploadCadID, pro.SitePhoto}, [2]string{param.UploadCadID, param.SitePhoto}); ex != nil {
+		return ex
+	}
 	// obj change
-	if pro.UploadCadID != "" && pro.UploadCadID != param.UploadCadID {
-		ex := rsi.objRepo.Delete(rsi.db, pro.UploadCadID)
-		if ex != nil {
-			return ex
-		}
+	replaced := make([]string, 0, 2)
+	if pro.UploadCadID != param.UploadCadID {
+		replaced = append(replaced, pro.UploadCadID)
 	}
-	if pro.SitePhoto != "" && pro.SitePhoto != param.SitePhoto {
-		ex := rsi.objRepo.Delete(rsi.db, pro.SitePhoto)
-		if ex != nil {
-			return ex
-		}
+	if pro.SitePhoto != param.SitePhoto {
+		replaced = append(replaced, pro.SitePhoto)
+	}
+	objs, ex := deleteObjects(tx, rsi.objRepo, replaced...)
+	if ex != nil {
+		return ex
 	}
-	return rsi.repo.Update(rsi.db, id, param.ToMap(openID))
+	if err := tx.Commit().Error; err != nil {
+		return exception.Wrap(response.ExceptionDatabase, err)
+	}
+	deleteObjectContents(rsi.objRepo, objs)
+	return nil
 }
 
 func (rsi *reserveServiceImpl) Delete(id int64) exception.Exception {
@@ -124,17 +176,26 @@ func (rsi *reserveServiceImpl) Delete(id int64) exception.Exception {
 	if ex != nil {
 		return ex
 	}
-	if pro.SitePhoto != "" {
-		if exx := rsi.objRepo.Delete(rsi.db, pro.SitePhoto); exx != nil {
-			return exx
-		}
+	tx := rsi.db.Begin()
+	if tx.Error != nil {
+		return exception.Wrap(response.ExceptionDatabase, tx.Error)
 	}
-	if pro.UploadCadID != "" {
-		if exx := rsi.objRepo.Delete(rsi.db, pro.UploadCadID); exx != nil {
-			return exx
-		}
+	defer tx.Rollback()
+	if ex := rsi.attachRepo.DeleteByProject(tx, constant.AttachReserve, id); ex != nil {
+		return ex
+	}
+	if ex := rsi.repo.Delete(tx, id); ex != nil {
+		return ex
+	}
+	objs, ex := deleteObjects(tx, rsi.objRepo, pro.SitePhoto, pro.UploadCadID)
+	if ex != nil {
+		return ex
 	}
-	return rsi.repo.Delete(rsi.db, id)
+	if err := tx.Commit().Error; err != nil {
+		return exception.Wrap(response.ExceptionDatabase, err)
+	}
+	deleteObjectContents(rsi.objRepo, objs)
+	return nil
 }
 
 func (rsi *reserveServiceImpl) MultiDelete(ids string) exception.Exception {
@@ -150,23 +211,34 @@ func (rsi *reserveServiceImpl) MultiDelete(ids string) exception.Exception {
 		}
 		did = append(did, int64(id))
 	}
+	tx := rsi.db.Begin()
+	if tx.Error != nil {
+		return exception.Wrap(response.ExceptionDatabase, tx.Error)
+	}
+	defer tx.Rollback()
+	objIDs := make([]string, 0, len(did)*2)
 	for i := range did {
-		pro, ex := rsi.repo.Get(rsi.db, did[i])
+		pro, ex := rsi.repo.Get(tx, did[i])
 		if ex != nil {
 			return ex
 		}
-		if pro.SitePhoto != "" {
-			if exx := rsi.objRepo.Delete(rsi.db, pro.SitePhoto); exx != nil {
-				return exx
-			}
-		}
-		if pro.UploadCadID != "" {
-			if exx := rsi.objRepo.Delete(rsi.db, pro.UploadCadID); exx != nil {
-				return exx
-			}
-		}
+		objIDs = append(objIDs, pro.SitePhoto, pro.UploadCadID)
+	}
+	if ex := rsi.attachRepo.DeleteByProject(tx, constant.AttachReserve, did...); ex != nil {
+		return ex
 	}
-	return rsi.repo.MultiDelete(rsi.db, did)
+	if ex := rsi.repo.MultiDelete(tx, did); ex != nil {
+		return ex
+	}
+	objs, ex := deleteObjects(tx, rsi.objRepo, objIDs...)
+	if ex != nil {
+		return ex
+	}
+	if err := tx.Commit().Error; err != nil {
+		return exception.Wrap(response.ExceptionDatabase, err)
+	}
+	deleteObjectContents(rsi.objRepo, objs)
+	return nil
 }
 
 func (rsi *reserveServiceImpl) Refer(openID string, id int64) exception.Exception {
@@ -236,3 +308,22 @@ func (rsi *reserveServiceImpl) DataAnalysis(params *vo.ReserveAnalysisFilter) ([
 	}
 	return resp, ex
 }
+
+func (rsi *reserveServiceImpl) ImportTemplate() *xlsx.File {
+	return reserveImportTemplate()
+}
+
+func (rsi *reserveServiceImpl) Import(openID string, fh *multipart.FileHeader, confirm bool) (*vo.ImportResp, exception.Exception) {
+	return rsi.importReserves(openID, fh, confirm)
+}
+
+func (rsi *reserveServiceImpl) Export(user string, params *vo.ReserveFilterParam) (*xlsx.File, exception.Exception) {
+	userInfo, ex := rsi.userRepo.Get(rsi.db, user)
+	if ex != nil {
+		return nil, ex
+	}
+	return exportReserve(func(pageInfo *vo.PageInfo) ([]models.ReservePro, exception.Exception) {
+		_, projects, ex := rsi.repo.List(rsi.db, pageInfo, params, userInfo.IsAdmin, user)
+		return projects, ex
+	})
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"lpms/app/models"
+	"lpms/app/repositories"
+	"lpms/app/response"
+	"lpms/app/vo"
+	"lpms/commom/drivers/database"
+	"lpms/constant"
+	"lpms/exception"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+var (
+	savedViewServiceInstance SavedViewService
+	savedViewOnce            sync.Once
+)
+
+type savedViewServiceImpl struct {
+	db       *gorm.DB
+	repo     repositories.SavedViewRepo
+	userRepo repositories.UserRepo
+}
+
+func GetSavedViewService() SavedViewService {
+	savedViewOnce.Do(func() {
+		savedViewServiceInstance = &savedViewServiceImpl{
+			db:       database.GetDriver(),
+			repo:     repositories.GetSavedViewRepo(),
+			userRepo: repositories.GetUserRepo(),
+		}
+	})
+	return savedViewServiceInstance
+}
+
+type SavedViewService interface {
+	Create(openID string, param *vo.SavedViewReq) (*vo.SavedViewResp, exception.Exception)
+	List(user, listType string) ([]vo.SavedViewResp, exception.Exception)
+	Update(openID string, id int64, param *vo.SavedViewReq) exception.Exception
+	Delete(user string, id int64) exception.Exception
+	SetDefault(user string, id int64) exception.Exception
+	Apply(user string, id int64, pageInfo *vo.PageInfo) (*vo.DataPagination, exception.Exception)
+}
+
+func (svi *savedViewServiceImpl) check(openID string, param *vo.SavedViewReq, id int64) (*models.User, exception.Exception) {
+	if err := param.Validate(); err != nil {
+		return nil, exception.Wrap(response.ExceptionInvalidRequestParameters, err)
+	}
+	sorts, _ := vo.ParseSort(param.Sort)
+	if ex := repositories.CheckListSort(param.ListType, sorts); ex != nil {
+		return nil, ex
+	}
+	userInfo, ex := svi.userRepo.Get(svi.db, openID)
+	if ex != nil {
+		return nil, ex
+	}
+	if param.Shared && userInfo.Unit == "" {
+		return nil, exception.New(response.ExceptionInvalidRequestParameters, "当前用户未设置所属单位, 无法共享视图")
+	}
+	exist, ex := svi.repo.ExistName(svi.db, openID, param.ListType, param.Name, id)
+	if ex != nil {
+		return nil, ex
+	}
+	if exist {
+		return nil, exception.New(response.ExceptionNameDuplicate, "视图名称已存在")
+	}
+	return userInfo, nil
+}
+
+func (svi *savedViewServiceImpl) Create(openID string, param *vo.SavedViewReq) (*vo.SavedViewResp, exception.Exception) {
+	userInfo, ex := svi.check(openID, param, 0)
+	if ex != nil {
+		return nil, ex
+	}
+	view := param.ToModel(openID, userInfo.Unit)
+	tx := svi.db.Begin()
+	if tx.Error != nil {
+		return nil, exception.Wrap(response.ExceptionDatabase, tx.Error)
+	}
+	defer tx.Rollback()
+	if view.IsDefault {
+		if ex := svi.repo.ClearDefault(tx, openID, view.ListType); ex != nil {
+			return nil, ex
+		}
+	}
+	if ex := svi.repo.Create(tx, view); ex != nil {
+		return nil, ex
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, exception.Wrap(response.ExceptionDatabase, err)
+	}
+	resp := vo.NewSavedViewResponse(view, openID)
+	return &resp, nil
+}
+
+// List 本人创建及本单位共享的视图, 默认视图排最前, 其次为本人创建的视图
+func (svi *savedViewServiceImpl) List(user, listType string) ([]vo.SavedViewResp, exception.Exception) {
+	if _, ok := vo.SavedViewListTypes[listType]; listType != "" && !ok {
+		return nil, exception.New(response.ExceptionInvalidRequestParameters, "invalid list_type")
+	}
+	userInfo, ex := svi.userRepo.Get(svi.db, user)
+	if ex != nil {
+		return nil, ex
+	}
+	views, ex := svi.repo.List(svi.db, listType, user, userInfo.Unit)
+	if ex != nil {
+		return nil, ex
+	}
+	resp := make([]vo.SavedViewResp, 0, len(views))
+	for i := range views {
+		resp = append(resp, vo.NewSavedViewResponse(&views[i], user))
+	}
+	sort.SliceStable(resp, func(i, j int) bool {
+		if resp[i].ListType != resp[j].ListType {
+			return resp[i].ListType < resp[j].ListType
+		}
+		if resp[i].IsDefault != resp[j].IsDefault {
+			return resp[i].IsDefault
+		}
+		return resp[i].IsOwner && !resp[j].IsOwner
+	})
+	return resp, nil
+}
+
+// owned 仅创建者可修改、删除及设为默认
+func (svi *savedViewServiceImpl) owned(user string, id int64) (*models.SavedView, exception.Exception) {
+	view, ex := svi.repo.Get(svi.db, id)
+	if ex != nil {
+		return nil, ex
+	}
+	if view.CreateBy != user {
+		return nil, exception.New(response.ExceptionForbidden, "当前操作无权限")
+	}
+	return view, nil
+}
+
+func (svi *savedViewServiceImpl) Update(openID string, id int64, param *vo.SavedViewReq) exception.Exception {
+	view, ex := svi.owned(openID, id)
+	if ex != nil {
+		return ex
+	}
+	// 列表类型不可修改
+	param.ListType = view.ListType
+	userInfo, ex := svi.check(openID, param, id)
+	if ex != nil {
+		return ex
+	}
+	tx := svi.db.Begin()
+	if tx.Error != nil {
+		return exception.Wrap(response.ExceptionDatabase, tx.Error)
+	}
+	defer tx.Rollback()
+	if param.IsDefault {
+		if ex := svi.repo.ClearDefault(tx, openID, view.ListType); ex != nil {
+			return ex
+		}
+	}
+	if ex := svi.repo.Update(tx, id, param.ToMap(openID, userInfo.Unit)); ex != nil {
+		return ex
+	}
+	return exception.Wrap(response.ExceptionDatabase, tx.Commit().Error)
+}
+
+func (svi *savedViewServiceImpl) Delete(user string, id int64) exception.Exception {
+	if _, ex := svi.owned(user, id); ex != nil {
+		return ex
+	}
+	return svi.repo.Delete(svi.db, id)
+}
+
+// SetDefault 设为该列表的默认视图, 同一列表只有一个默认视图
+func (svi *savedViewServiceImpl) SetDefault(user string, id int64) exception.Exception {
+	view, ex := svi.owned(user, id)
+	if ex != nil {
+		return ex
+	}
+	tx := svi.db.Begin()
+	if tx.Error != nil {
+		return exception.Wrap(response.ExceptionDatabase, tx.Error)
+	}
+	defer tx.Rollback()
+	if ex := svi.repo.ClearDefault(tx, user, view.ListType); ex != nil {
+		return ex
+	}
+	if ex := svi.repo.Update(tx, id, map[string]interface{}{"is_default": true, "update_by": user}); ex != nil {
+		return ex
+	}
+	return exception.Wrap(response.ExceptionDatabase, tx.Commit().Error)
+}
+
+// Apply 按视图的筛选条件查询对应列表; 请求未指定排序时使用视图保存的排序
+func (svi *savedViewServiceImpl) Apply(user string, id int64, pageInfo *vo.PageInfo) (*vo.DataPagination, exception.Exception) {
+	view, ex := svi.repo.Get(svi.db, id)
+	if ex != nil {
+		return nil, ex
+	}
+	if view.CreateBy != user {
+		userInfo, ex := svi.userRepo.Get(svi.db, user)
+		if ex != nil {
+			return nil, ex
+		}
+		if !view.Shared || view.Unit == "" || view.Unit != userInfo.Unit {
+			return nil, exception.New(response.ExceptionForbidden, "当前操作无权限")
+		}
+	}
+	params, err := vo.DecodeSavedViewFilter(view.ListType, view.Filter)
+	if err != nil {
+		return nil, exception.Wrap(response.ExceptionInvalidRequestParameters, err)
+	}
+	if len(pageInfo.Sorts) == 0 {
+		if pageInfo.Sorts, err = vo.ParseSort(view.Sort); err != nil {
+			return nil, exception.Wrap(response.ExceptionInvalidRequestParameters, err)
+		}
+	}
+	switch view.ListType {
+	case constant.ViewReserve:
+		return GetReserveService().List(user, params.(*vo.ReserveFilterParam), pageInfo)
+	case constant.ViewEarlyPlan:
+		return GetReserveInspectService().EarlyPlanList(params.(*vo.ReserveInspectParam), pageInfo)
+	case constant.ViewOutStorageInspect:
+		return GetReserveInspectService().OutStorageInspList(params.(*vo.ReserveInspectParam), pageInfo)
+	case constant.ViewImplementGov:
+		return GetImplementGovService().List(user, params.(*vo.ImplementGovFilterParam), pageInfo)
+	case constant.ViewImplementIndustry:
+		return GetImpleIndustryService().List(user, params.(*vo.ImpleIndustryFilterParam), pageInfo)
+	}
+	return nil, exception.New(response.ExceptionInvalidRequestParameters, "invalid list_type")
+}
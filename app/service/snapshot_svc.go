@@ -0,0 +1,353 @@
+package service
+
+import (
+	"fmt"
+	"lpms/app/models"
+	"lpms/app/repositories"
+	"lpms/app/response"
+	"lpms/app/vo"
+	"lpms/commom/drivers/database"
+	"lpms/constant"
+	"lpms/exception"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	snapshotServiceInstance SnapshotService
+	snapshotOnce            sync.Once
+)
+
+type snapshotServiceImpl struct {
+	db           *gorm.DB
+	repo         repositories.SnapshotRepo
+	govRepo      repositories.ImplementGovRepo
+	industryRepo repositories.ImpleIndustryRepo
+	progressRepo repositories.GovProgressRepo
+	userRepo     repositories.UserRepo
+}
+
+func GetSnapshotService() SnapshotService {
+	snapshotOnce.Do(func() {
+		snapshotServiceInstance = &snapshotServiceImpl{
+			db:           database.GetDriver(),
+			repo:         repositories.GetSnapshotRepo(),
+			govRepo:      repositories.GetImplementGovRepo(),
+			industryRepo: repositories.GetImpleIndustryRepo(),
+			progressRepo: repositories.GetGovProgressRepo(),
+			userRepo:     repositories.GetUserRepo(),
+		}
+	})
+	return snapshotServiceInstance
+}
+
+type SnapshotService interface {
+	Run(now time.Time) exception.Exception
+	Take(user string, year, month int) (*vo.SnapshotResp, exception.Exception)
+	List() ([]vo.SnapshotResp, exception.Exception)
+	Diff(user string, from, to int64) (*vo.SnapshotDiffResp, exception.Exception)
+}
+
+// Run 定时任务: 进入新月份后冻结上月末数据, 已生成则跳过
+func (ssi *snapshotServiceImpl) Run(now time.Time) exception.Exception {
+	last := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -1, 0)
+	year, month := last.Year(), int(last.Month())
+	_, ex := ssi.repo.GetByPeriod(ssi.db, year, month)
+	if ex == nil {
+		return nil
+	}
+	if ex.Type() != response.ExceptionRecordNotFound {
+		return ex
+	}
+	_, ex = ssi.take(systemUser, year, month)
+	return ex
+}
+
+// Take 手动生成快照(仅管理员), 快照不可覆盖.
+// 项目状态及竣工时间取当前值, 无法还原历史月份, 只能生成本月或上月的快照
+func (ssi *snapshotServiceImpl) Take(user string, year, month int) (*vo.SnapshotResp, exception.Exception) {
+	if year <= 0 || month < 1 || month > 12 {
+		return nil, exception.New(response.ExceptionInvalidRequestParameters, "invalid year or month")
+	}
+	now := time.Now()
+	cur, period := models.Period(now.Year(), int(now.Month())), models.Period(year, month)
+	if period > cur || period < cur-1 {
+		return nil, exception.New(response.ExceptionInvalidRequestParameters, "只能生成本月或上月的快照")
+	}
+	userInfo, ex := ssi.userRepo.Get(ssi.db, user)
+	if ex != nil {
+		return nil, ex
+	}
+	if !userInfo.IsAdmin {
+		return nil, exception.New(response.ExceptionForbidden, "当前操作无权限")
+	}
+	if _, ex := ssi.repo.GetByPeriod(ssi.db, year, month); ex == nil {
+		return nil, exception.New(response.ExceptionNameDuplicate, fmt.Sprintf("%d年%d月快照已存在", year, month))
+	} else if ex.Type() != response.ExceptionRecordNotFound {
+		return nil, ex
+	}
+	return ssi.take(user, year, month)
+}
+
+func (ssi *snapshotServiceImpl) take(openID string, year, month int) (*vo.SnapshotResp, exception.Exception) {
+	govs, ex := ssi.govRepo.ListAll(ssi.db)
+	if ex != nil {
+		return nil, ex
+	}
+	industries, ex := ssi.industryRepo.ListAll(ssi.db)
+	if ex != nil {
+		return nil, ex
+	}
+	ids := make([]int64, 0, len(govs))
+	for i := range govs {
+		ids = append(ids, govs[i].ID)
+	}
+	sums, ex := ssi.progressRepo.InvestmentSum(ssi.db, year, month, ids...)
+	if ex != nil {
+		return nil, ex
+	}
+	progresses, ex := ssi.progressRepo.ListByMonth(ssi.db, year, month, ids...)
+	if ex != nil {
+		return nil, ex
+	}
+	sumMap := make(map[int64]*models.InvestmentSum, len(sums))
+	for i := range sums {
+		sumMap[sums[i].ProjectID] = &sums[i]
+	}
+	progressMap := make(map[int64]*models.GovProgress, len(progresses))
+	for i := range progresses {
+		progressMap[progresses[i].ProjectID] = &progresses[i]
+	}
+
+	projects := make([]models.SnapshotProject, 0, len(govs)+len(industries))
+	for i := range govs {
+		projects = append(projects, newGovSnapshotProject(&govs[i], year, sumMap[govs[i].ID], progressMap[govs[i].ID]))
+	}
+	for i := range industries {
+		projects = append(projects, newIndustrySnapshotProject(&industries[i]))
+	}
+	snapshot := &models.ReportSnapshot{
+		Year:  year,
+		Month: month,
+		Base: models.Base{
+			CreateBy: openID,
+			UpdateBy: openID,
+		},
+	}
+	snapshot.ProjectCount, snapshot.TotalInvestment, snapshot.YearInvested = snapshotTotals(projects)
+
+	tx := ssi.db.Begin()
+	if tx.Error != nil {
+		return nil, exception.Wrap(response.ExceptionDatabase, tx.Error)
+	}
+	defer tx.Rollback()
+	if ex := ssi.repo.Create(tx, snapshot, projects); ex != nil {
+		return nil, ex
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, exception.Wrap(response.ExceptionDatabase, err)
+	}
+	resp := vo.NewSnapshotResponse(snapshot)
+	return &resp, nil
+}
+
+func newGovSnapshotProject(p *models.ImplementGov, year int, sum *models.InvestmentSum, progress *models.GovProgress) models.SnapshotProject {
+	sp := models.SnapshotProject{
+		Source:           models.SnapshotSourceGov,
+		ProjectID:        p.ID,
+		Type:             p.Type,
+		Name:             p.Name,
+		ProjectCode:      p.ProjectCode,
+		ConstructSubject: p.ConstructSubject,
+		Level:            p.Level,
+		ProjectType:      p.ProjectType,
+		PointType:        p.PointType,
+		DutyUnit:         p.DutyUint,
+		Township:         p.Township,
+		Status:           p.Status,
+		PlanBegin:        p.PlanBegin,
+		StartTime:        p.StartTime,
+		FinishTime:       p.FinishTime,
+		ProjectCreateAt:  p.CreateAt,
+		CreateBy:         p.CreateBy,
+		TotalInvestment:  p.TotalInvestment,
+	}
+	details, _ := vo.ParseInvestmentDetail(p.InvestmentDetail)
+	for _, plan := range vo.PlanBySource(details, year) {
+		sp.YearPlan += plan
+	}
+	if sum != nil {
+		sp.YearInvested = sum.YearInvested
+		sp.StartInvested = sum.StartInvested
+	}
+	if progress != nil {
+		status := progress.Status
+		sp.ProgressStatus = &status
+		sp.MonthPlan = progress.PlanInvest
+		sp.MonthInvested = progress.PlanInvested
+		sp.PlanProgress = progress.PlanProgress
+		sp.ActualProgress = progress.ActualProgress
+		sp.ProblemDetail = progress.ProblemDetail
+	}
+	return sp
+}
+
+func newIndustrySnapshotProject(p *models.ImpleIndustry) models.SnapshotProject {
+	return models.SnapshotProject{
+		Source:           models.SnapshotSourceIndustry,
+		ProjectID:        p.ID,
+		Type:             constant.IndustryProject,
+		Name:             p.Name,
+		ConstructSubject: p.ConstructSubject,
+		Level:            p.Level,
+		ProjectType:      p.ProjectType,
+		PointType:        p.PointType,
+		Status:           p.Status,
+		PlanBegin:        p.PlanBegin,
+		StartTime:        p.StartTime,
+		FinishTime:       p.FinishTime,
+		ProjectCreateAt:  p.CreateAt,
+		CreateBy:         p.CreateBy,
+		TotalInvestment:  p.TotalInvestment,
+	}
+}
+
+// snapshotTotals 汇总口径与投资统计一致: 未开工、已开工、已竣工项目
+func snapshotTotals(projects []models.SnapshotProject) (int64, float64, float64) {
+	count, total, invested := int64(0), float64(0), float64(0)
+	for i := range projects {
+		switch projects[i].Status {
+		case constant.UnStart, constant.Started, constant.Finished:
+			count++
+			total += deref(projects[i].TotalInvestment)
+			invested += projects[i].YearInvested
+		}
+	}
+	return count, total, invested
+}
+
+func (ssi *snapshotServiceImpl) List() ([]vo.SnapshotResp, exception.Exception) {
+	snapshots, ex := ssi.repo.List(ssi.db)
+	if ex != nil {
+		return nil, ex
+	}
+	return vo.NewSnapshotResponses(snapshots), nil
+}
+
+type snapshotField struct {
+	field string
+	name  string
+	value func(p *models.SnapshotProject) interface{}
+}
+
+func optionalFloat(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func progressStatusName(status *int) string {
+	if status == nil {
+		return "未填报"
+	}
+	if *status == 1 {
+		return "已提交"
+	}
+	return "未提交"
+}
+
+var snapshotDiffFields = []snapshotField{
+	{"name", "项目名称", func(p *models.SnapshotProject) interface{} { return p.Name }},
+	{"status", "项目状态", func(p *models.SnapshotProject) interface{} { return vo.Label(vo.ImplementStatusNames, &p.Status) }},
+	{"duty_unit", "责任单位", func(p *models.SnapshotProject) interface{} { return p.DutyUnit }},
+	{"township", "所属街镇", func(p *models.SnapshotProject) interface{} { return p.Township }},
+	{"project_type", "项目类型", func(p *models.SnapshotProject) interface{} { return vo.Label(vo.ProjectTypeNames, p.ProjectType) }},
+	{"level", "项目级别", func(p *models.SnapshotProject) interface{} { return vo.Label(vo.LevelNames, p.Level) }},
+	{"point_type", "重点类型", func(p *models.SnapshotProject) interface{} { return vo.Label(vo.PointTypeNames, p.PointType) }},
+	{"start_time", "开工时间", func(p *models.SnapshotProject) interface{} { return formatDate(p.StartTime) }},
+	{"finish_time", "竣工时间", func(p *models.SnapshotProject) interface{} { return formatDate(p.FinishTime) }},
+	{"total_investment", "总投资", func(p *models.SnapshotProject) interface{} { return optionalFloat(p.TotalInvestment) }},
+	{"year_plan", "当年计划投资", func(p *models.SnapshotProject) interface{} { return p.YearPlan }},
+	{"year_invested", "当年累计完成投资", func(p *models.SnapshotProject) interface{} { return p.YearInvested }},
+	{"start_invested", "开工至今累计完成投资", func(p *models.SnapshotProject) interface{} { return p.StartInvested }},
+	{"month_invested", "本月完成投资", func(p *models.SnapshotProject) interface{} { return optionalFloat(p.MonthInvested) }},
+	{"progress_status", "填报状态", func(p *models.SnapshotProject) interface{} { return progressStatusName(p.ProgressStatus) }},
+}
+
+// Diff 对比两个快照: 汇总数变化、新增/移除项目及各项目关键字段变化, 非管理员仅对比本人创建的项目
+func (ssi *snapshotServiceImpl) Diff(user string, from, to int64) (*vo.SnapshotDiffResp, exception.Exception) {
+	userInfo, ex := ssi.userRepo.Get(ssi.db, user)
+	if ex != nil {
+		return nil, ex
+	}
+	fromSnap, ex := ssi.repo.Get(ssi.db, from)
+	if ex != nil {
+		return nil, ex
+	}
+	toSnap, ex := ssi.repo.Get(ssi.db, to)
+	if ex != nil {
+		return nil, ex
+	}
+	fromProjects, ex := ssi.repo.ListProjects(ssi.db, from, userInfo.IsAdmin, user)
+	if ex != nil {
+		return nil, ex
+	}
+	toProjects, ex := ssi.repo.ListProjects(ssi.db, to, userInfo.IsAdmin, user)
+	if ex != nil {
+		return nil, ex
+	}
+
+	resp := &vo.SnapshotDiffResp{
+		From:    vo.NewSnapshotResponse(fromSnap),
+		To:      vo.NewSnapshotResponse(toSnap),
+		Added:   make([]vo.SnapshotProjectBrief, 0),
+		Removed: make([]vo.SnapshotProjectBrief, 0),
+		Changed: make([]vo.SnapshotProjectChange, 0),
+	}
+	fromCount, fromTotal, fromInvested := snapshotTotals(fromProjects)
+	toCount, toTotal, toInvested := snapshotTotals(toProjects)
+	resp.ProjectCountDelta = toCount - fromCount
+	resp.TotalInvestmentDelta = toTotal - fromTotal
+	resp.YearInvestedDelta = toInvested - fromInvested
+
+	type key struct {
+		source int
+		id     int64
+	}
+	before := make(map[key]*models.SnapshotProject, len(fromProjects))
+	for i := range fromProjects {
+		before[key{fromProjects[i].Source, fromProjects[i].ProjectID}] = &fromProjects[i]
+	}
+	for i := range toProjects {
+		cur := &toProjects[i]
+		k := key{cur.Source, cur.ProjectID}
+		prev, ok := before[k]
+		if !ok {
+			resp.Added = append(resp.Added, vo.NewSnapshotProjectBrief(cur))
+			continue
+		}
+		delete(before, k)
+		changes := make([]vo.FieldChange, 0)
+		for _, f := range snapshotDiffFields {
+			a, b := f.value(prev), f.value(cur)
+			if fmt.Sprint(a) != fmt.Sprint(b) {
+				changes = append(changes, vo.FieldChange{Field: f.field, Name: f.name, From: a, To: b})
+			}
+		}
+		if len(changes) > 0 {
+			resp.Changed = append(resp.Changed, vo.SnapshotProjectChange{
+				SnapshotProjectBrief: vo.NewSnapshotProjectBrief(cur),
+				Changes:              changes,
+			})
+		}
+	}
+	for i := range fromProjects {
+		if _, ok := before[key{fromProjects[i].Source, fromProjects[i].ProjectID}]; ok {
+			resp.Removed = append(resp.Removed, vo.NewSnapshotProjectBrief(&fromProjects[i]))
+		}
+	}
+	return resp, nil
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"lpms/app/repositories"
+	"lpms/app/response"
 	"lpms/app/vo"
 	"lpms/commom/drivers/database"
 	"lpms/exception"
@@ -37,6 +38,9 @@ type WindowService interface {
 }
 
 func (wsi *windowServiceImpl) Create(openID string, param *vo.WindowsReq) exception.Exception {
+	if err := param.Validate(); err != nil {
+		return exception.Wrap(response.ExceptionInvalidRequestParameters, err)
+	}
 	return wsi.repo.Create(wsi.db, param.ToModel(openID))
 }
 
@@ -52,6 +56,9 @@ func (wsi *windowServiceImpl) List() (*vo.WindowsResponse, exception.Exception)
 }
 
 func (wsi *windowServiceImpl) Update(openID string, param *vo.WindowsReq) exception.Exception {
+	if err := param.Validate(); err != nil {
+		return exception.Wrap(response.ExceptionInvalidRequestParameters, err)
+	}
 	setting, ex := wsi.repo.List(wsi.db)
 	if ex != nil {
 		return ex
@@ -0,0 +1,152 @@
+package vo
+
+import (
+	"errors"
+	"lpms/app/models"
+	"lpms/constant"
+	"time"
+	"unicode/utf8"
+)
+
+// AttachmentSources 附件所属项目库
+var AttachmentSources = map[string]string{
+	constant.AttachReserve:  "储备库项目",
+	constant.AttachGov:      "实施库政府投资项目",
+	constant.AttachIndustry: "实施库产业项目",
+}
+
+// AttachmentCategories 附件类别
+var AttachmentCategories = map[int]string{
+	constant.AttachOther:         "其他",
+	constant.AttachCad:           "CAD图纸",
+	constant.AttachSitePhoto:     "现场照片",
+	constant.AttachApproval:      "立项批复",
+	constant.AttachFeasibility:   "可行性研究报告",
+	constant.AttachLandPreReview: "用地预审意见",
+	constant.AttachEnvAssessment: "环境影响评价",
+}
+
+// maxAttachObjects 单次最多添加的附件数
+const maxAttachObjects = 50
+
+// AttachmentProject 附件所属项目
+type AttachmentProject struct {
+	// 所属项目库 reserve:储备库项目, gov:实施库政府投资项目, industry:实施库产业项目
+	Source string `json:"source"`
+	// 项目id
+	ProjectID int64 `json:"project_id"`
+}
+
+func (p *AttachmentProject) Validate() error {
+	if _, ok := AttachmentSources[p.Source]; !ok {
+		return errors.New("所属项目库无效")
+	}
+	if p.ProjectID <= 0 {
+		return errors.New("缺少项目id")
+	}
+	return nil
+}
+
+// AttachmentReq 添加项目附件, 文件须先通过上传接口存储
+type AttachmentReq struct {
+	AttachmentProject
+	// 文件id, 可多个, 按顺序排在已有附件之后
+	ObjectIDs []string `json:"object_ids"`
+	// 类别 0:其他,1:CAD图纸,2:现场照片,3:立项批复,4:可行性研究报告,5:用地预审意见,6:环境影响评价
+	Category int `json:"category"`
+	// 附件说明
+	Description string `json:"description"`
+}
+
+func (r *AttachmentReq) Validate() error {
+	if err := r.AttachmentProject.Validate(); err != nil {
+		return err
+	}
+	if len(r.ObjectIDs) == 0 || len(r.ObjectIDs) > maxAttachObjects {
+		return errors.New("文件id不能为空且不超过50个")
+	}
+	seen := make(map[string]bool, len(r.ObjectIDs))
+	for _, id := range r.ObjectIDs {
+		if id == "" || seen[id] {
+			return errors.New("文件id不能为空或重复")
+		}
+		seen[id] = true
+	}
+	if _, ok := AttachmentCategories[r.Category]; !ok {
+		return errors.New("附件类别无效")
+	}
+	if utf8.RuneCountInString(r.Description) > 500 {
+		return errors.New("附件说明不超过500个字符")
+	}
+	return nil
+}
+
+func (r *AttachmentReq) ToModels(openID string, firstSort int) []models.ProjectAttachment {
+	res := make([]models.ProjectAttachment, 0, len(r.ObjectIDs))
+	for i, id := range r.ObjectIDs {
+		res = append(res, models.ProjectAttachment{
+			Source:      r.Source,
+			ProjectID:   r.ProjectID,
+			ObjectID:    id,
+			Category:    r.Category,
+			Description: r.Description,
+			Sort:        firstSort + i,
+			Base: models.Base{
+				CreateBy: openID,
+				UpdateBy: openID,
+			},
+		})
+	}
+	return res
+}
+
+// AttachmentSortReq 调整项目附件顺序
+type AttachmentSortReq struct {
+	AttachmentProject
+	// 项目全部附件的id, 按新的顺序排列
+	IDs []int64 `json:"ids"`
+}
+
+type AttachmentResp struct {
+	// 附件id
+	ID int64 `json:"id"`
+	// 所属项目库
+	Source string `json:"source"`
+	// 项目id
+	ProjectID int64 `json:"project_id"`
+	// 文件id
+	ObjectID string `json:"object_id"`
+	// 文件名称
+	Filename string `json:"filename"`
+	// 文件大小(字节)
+	Size int64 `json:"size"`
+	// 文件类型(MIME)
+	ContentType string `json:"content_type"`
+	// 类别 0:其他,1:CAD图纸,2:现场照片,3:立项批复,4:可行性研究报告,5:用地预审意见,6:环境影响评价
+	Category int `json:"category"`
+	// 附件说明
+	Description string `json:"description"`
+	// 排序
+	Sort int `json:"sort"`
+	// 添加人
+	CreateBy string `json:"create_by"`
+	// 添加时间
+	CreateAt time.Time `json:"create_at"`
+}
+
+func NewAttachmentResponse(a *models.ProjectAttachmentFile) AttachmentResp {
+	return AttachmentResp{
+		ID:          a.ID,
+		Source:      a.Source,
+		ProjectID:   a.ProjectID,
+		ObjectID:    a.ObjectID,
+		Filename:    a.Filename,
+		Size:        a.Size,
+		ContentType: a.ContentType,
+		Category:    a.Category,
+		Description: a.Description,
+		Sort:        a.Sort,
+		CreateBy:    a.CreateBy,
+		CreateAt:    a.CreateAt,
+	}
+}
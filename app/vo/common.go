@@ -1,13 +1,20 @@
 package vo
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Pagination 分页信息
 type Pagination struct {
 	// 请求页
 	Page int `json:"page"`
 	// 页大小
 	PageSize int `json:"page_size"`
-	// 数据总条数
+	// 数据总条数, 游标分页且未要求统计总数(with_count)时为 -1
 	TotalCount int64 `json:"total_count"`
+	// 下一页游标, 没有下一页时为空
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // DataPagination 数据包含分页信息
@@ -19,9 +26,53 @@ type DataPagination struct {
 }
 
 type PageInfo struct {
-	Keywords string `json:"keywords"`
-	Page     int    `json:"page"`
-	PageSize int    `json:"page_size"`
+	Keywords string      `json:"keywords"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+	Sorts    []SortField `json:"sorts"`
+	// 游标, 不为空时按游标分页并忽略 Page
+	Cursor string `json:"cursor"`
+	// 游标分页时是否统计总数
+	WithCount bool `json:"with_count"`
+	// 查询后回填的下一页游标
+	NextCursor string `json:"next_cursor"`
+}
+
+// SortField 排序字段
+type SortField struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
+
+// maxSortFields 最多排序字段数
+const maxSortFields = 5
+
+// ParseSort 解析排序参数, 格式为 字段[:asc|desc], 多个字段以逗号分隔, eg: total_investment:desc,name
+func ParseSort(sort string) ([]SortField, error) {
+	sorts := make([]SortField, 0)
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(sort, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		field, dir := item, ""
+		if i := strings.Index(item, ":"); i >= 0 {
+			field, dir = strings.TrimSpace(item[:i]), strings.ToLower(strings.TrimSpace(item[i+1:]))
+		}
+		if field == "" || (dir != "" && dir != "asc" && dir != "desc") {
+			return nil, fmt.Errorf("invalid sort %q", item)
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("duplicate sort field %q", field)
+		}
+		seen[field] = true
+		sorts = append(sorts, SortField{Field: field, Desc: dir == "desc"})
+	}
+	if len(sorts) > maxSortFields {
+		return nil, fmt.Errorf("too many sort fields, max %d", maxSortFields)
+	}
+	return sorts, nil
 }
 
 func (p *PageInfo) Offset() int {
@@ -35,6 +86,7 @@ func NewDataPagination(count int64, data interface{}, page *PageInfo) *DataPagin
 			Page:       page.Page,
 			PageSize:   page.PageSize,
 			TotalCount: count,
+			NextCursor: page.NextCursor,
 		},
 	}
 }
@@ -0,0 +1,179 @@
+package vo
+
+import (
+	"lpms/app/models"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+type ContractReq struct {
+	// 项目ID (月度进度填报时无需传, 以进度所属项目为准)
+	ProjectID int64 `json:"project_id"`
+	// 合同编号
+	ContractNo string `json:"contract_no"`
+	// 合同名称
+	Name string `json:"name"`
+	// 合同相对方
+	Counterparty string `json:"counterparty"`
+	// 合同金额(万)
+	Amount *float64 `json:"amount"`
+	// 签订日期
+	SignDate *time.Time `json:"sign_date"`
+	// 合同类型 0:施工,1:监理,2:设计,3:勘察,4:采购,5:其他
+	ContractType *int `json:"contract_type"`
+	// 附件文件ID(上传文件接口返回的ID), 须为当前用户上传的文件
+	Attachments []string `json:"attachments"`
+	// 备注
+	Comment string `json:"comment"`
+}
+
+func (r *ContractReq) ToModel(openID string) (*models.Contract, error) {
+	attachments, err := json.Marshal(r.attachments())
+	if err != nil {
+		return nil, err
+	}
+	return &models.Contract{
+		ProjectID:    r.ProjectID,
+		ContractNo:   r.ContractNo,
+		Name:         r.Name,
+		Counterparty: r.Counterparty,
+		Amount:       r.Amount,
+		SignDate:     r.SignDate,
+		ContractType: r.ContractType,
+		Attachments:  attachments,
+		Comment:      r.Comment,
+		Base: models.Base{
+			UpdateBy: openID,
+			CreateBy: openID,
+		},
+	}, nil
+}
+
+func (r *ContractReq) ToMap(openID string) (map[string]interface{}, error) {
+	attachments, err := json.Marshal(r.attachments())
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"contract_no":   r.ContractNo,
+		"name":          r.Name,
+		"counterparty":  r.Counterparty,
+		"amount":        r.Amount,
+		"sign_date":     r.SignDate,
+		"contract_type": r.ContractType,
+		"attachments":   json.RawMessage(attachments),
+		"comment":       r.Comment,
+		"update_by":     openID,
+	}, nil
+}
+
+func (r *ContractReq) attachments() []string {
+	if r.Attachments == nil {
+		return []string{}
+	}
+	return r.Attachments
+}
+
+type ContractResp struct {
+	// id
+	ID int64 `json:"id"`
+	// 项目ID
+	ProjectID int64 `json:"project_id"`
+	// 来源月度进度ID
+	ProgressID *int64 `json:"progress_id"`
+	// 填报年份
+	Year int `json:"year"`
+	// 填报月份
+	Month int `json:"month"`
+	// 合同编号
+	ContractNo string `json:"contract_no"`
+	// 合同名称
+	Name string `json:"name"`
+	// 合同相对方
+	Counterparty string `json:"counterparty"`
+	// 合同金额(万)
+	Amount *float64 `json:"amount"`
+	// 签订日期
+	SignDate *time.Time `json:"sign_date"`
+	// 合同类型 0:施工,1:监理,2:设计,3:勘察,4:采购,5:其他
+	ContractType *int `json:"contract_type"`
+	// 附件文件ID
+	Attachments []string `json:"attachments"`
+	// 备注
+	Comment string `json:"comment"`
+	// 创建时间
+	CreateAt time.Time `json:"create_at"`
+}
+
+func NewContractResponse(r *models.Contract) (*ContractResp, error) {
+	attachments := make([]string, 0)
+	if len(r.Attachments) != 0 {
+		if err := json.Unmarshal(r.Attachments, &attachments); err != nil {
+			return nil, err
+		}
+	}
+	return &ContractResp{
+		ID:           r.ID,
+		ProjectID:    r.ProjectID,
+		ProgressID:   r.ProgressID,
+		Year:         r.Year,
+		Month:        r.Month,
+		ContractNo:   r.ContractNo,
+		Name:         r.Name,
+		Counterparty: r.Counterparty,
+		Amount:       r.Amount,
+		SignDate:     r.SignDate,
+		ContractType: r.ContractType,
+		Attachments:  attachments,
+		Comment:      r.Comment,
+		CreateAt:     r.CreateAt,
+	}, nil
+}
+
+func NewContractResponses(rs []models.Contract) ([]ContractResp, error) {
+	resp := make([]ContractResp, 0, len(rs))
+	for i := range rs {
+		r, err := NewContractResponse(&rs[i])
+		if err != nil {
+			return nil, err
+		}
+		resp = append(resp, *r)
+	}
+	return resp, nil
+}
+
+type ContractFilterParam struct {
+	// 项目ID ***注意:（所有参数，有就传，无则不传）***
+	ProjectID *int64 `json:"project_id"`
+	// 合同编号
+	ContractNo string `json:"contract_no"`
+	// 合同相对方
+	Counterparty string `json:"counterparty"`
+	// 合同类型
+	ContractType *int `json:"contract_type"`
+	// 签订日期起始(闭区间) eg: 2022-01-01 00:00:00
+	SignBegin string `json:"sign_begin"`
+	// 签订日期终止(开区间) eg: 2023-01-01 00:00:00
+	SignEnd string `json:"sign_end"`
+}
+
+type ContractTotalResp struct {
+	// 项目ID
+	ProjectID int64 `json:"project_id"`
+	// 合同数量
+	Count int64 `json:"count"`
+	// 合同总额(万)
+	Amount float64 `json:"amount"`
+}
+
+type ContractCounterpartyResp struct {
+	// 合同相对方
+	Counterparty string `json:"counterparty"`
+	// 合同数量
+	Count int64 `json:"count"`
+	// 合同总额(万)
+	Amount float64 `json:"amount"`
+	// 涉及项目数
+	ProjectCount int64 `json:"project_count"`
+}
@@ -0,0 +1,10 @@
+package vo
+
+// 筛选参数结构体以 filter 标签声明对应的数据库列及筛选方式, 格式 filter:"列名[,操作]", 操作:
+// eq(默认) 等于; in 多值; gte/lte/lt 区间端点, 同一列的端点须全部传入才筛选. 未传(空字符串、null、空数组)的参数不参与筛选
+
+// EmptyFilter 未填写筛选
+type EmptyFilter struct {
+	// 未填写(为空)的字段, 可选字段为本筛选条件中可按等于/多值筛选的字段 eg: ["duty_unit", "level"]
+	Empty []string `json:"empty"`
+}
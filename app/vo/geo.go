@@ -0,0 +1,268 @@
+package vo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/goccy/go-json"
+)
+
+// maxBoundaryPositions 红线范围最多顶点数
+const maxBoundaryPositions = 5000
+
+const (
+	GeoTypePoint   = "Point"
+	GeoTypePolygon = "Polygon"
+)
+
+// GeoPoint GeoJSON 点, 坐标为 [经度, 纬度](WGS84)
+type GeoPoint struct {
+	// 固定为 Point
+	Type string `json:"type"`
+	// [经度, 纬度]
+	Coordinates []float64 `json:"coordinates"`
+}
+
+func NewGeoPoint(lng, lat float64) *GeoPoint {
+	return &GeoPoint{Type: GeoTypePoint, Coordinates: []float64{lng, lat}}
+}
+
+func (p *GeoPoint) JSON() json.RawMessage {
+	return geoJSON(p)
+}
+
+// GeoPolygon GeoJSON 面, 第一个环为外边界, 其余为内部空洞, 每个环首尾坐标相同
+type GeoPolygon struct {
+	// 固定为 Polygon
+	Type string `json:"type"`
+	// [[[经度, 纬度], ...], ...]
+	Coordinates [][][]float64 `json:"coordinates"`
+}
+
+// ProjectGeo 项目位置及红线范围
+type ProjectGeo struct {
+	// 项目位置(GeoJSON Point), 未传且有红线范围时取外边界顶点的平均位置 eg: {"type":"Point","coordinates":[120.1234,30.5678]}
+	Location *GeoPoint `json:"location"`
+	// 红线范围(GeoJSON Polygon), 可选
+	Boundary *GeoPolygon `json:"boundary"`
+}
+
+func checkPosition(p []float64) error {
+	if len(p) != 2 {
+		return errors.New("坐标须为[经度, 纬度]")
+	}
+	lng, lat := p[0], p[1]
+	if math.IsNaN(lng) || lng < -180 || lng > 180 {
+		return fmt.Errorf("经度超出范围: %v", lng)
+	}
+	if math.IsNaN(lat) || lat < -90 || lat > 90 {
+		return fmt.Errorf("纬度超出范围: %v", lat)
+	}
+	return nil
+}
+
+func (p *GeoPoint) validate() error {
+	if p.Type != GeoTypePoint {
+		return fmt.Errorf("类型须为%s", GeoTypePoint)
+	}
+	return checkPosition(p.Coordinates)
+}
+
+func (p *GeoPolygon) validate() error {
+	if p.Type != GeoTypePolygon {
+		return fmt.Errorf("类型须为%s", GeoTypePolygon)
+	}
+	if len(p.Coordinates) == 0 {
+		return errors.New("缺少外边界")
+	}
+	total := 0
+	for _, ring := range p.Coordinates {
+		if len(ring) < 4 {
+			return errors.New("每个环至少4个坐标")
+		}
+		total += len(ring)
+		if total > maxBoundaryPositions {
+			return fmt.Errorf("坐标数不能超过%d个", maxBoundaryPositions)
+		}
+		for _, position := range ring {
+			if err := checkPosition(position); err != nil {
+				return err
+			}
+		}
+		first, last := ring[0], ring[len(ring)-1]
+		if first[0] != last[0] || first[1] != last[1] {
+			return errors.New("环的首尾坐标须相同")
+		}
+	}
+	return nil
+}
+
+// Validate 校验位置及红线范围, 返回全部不合规项
+func (g *ProjectGeo) Validate() []FieldError {
+	errs := make([]FieldError, 0)
+	if g.Location != nil {
+		if err := g.Location.validate(); err != nil {
+			errs = append(errs, FieldError{Field: "location", Message: err.Error()})
+		}
+	}
+	if g.Boundary != nil {
+		if err := g.Boundary.validate(); err != nil {
+			errs = append(errs, FieldError{Field: "boundary", Message: err.Error()})
+		}
+	}
+	return errs
+}
+
+// location 项目位置, 未传时取红线外边界顶点(不含闭合点)的平均位置
+func (g *ProjectGeo) location() *GeoPoint {
+	if g.Location != nil || g.Boundary == nil || len(g.Boundary.Coordinates) == 0 {
+		return g.Location
+	}
+	ring := g.Boundary.Coordinates[0]
+	if len(ring) < 2 {
+		return nil
+	}
+	ring = ring[:len(ring)-1]
+	lng, lat := 0.0, 0.0
+	for _, position := range ring {
+		lng += position[0]
+		lat += position[1]
+	}
+	return NewGeoPoint(lng/float64(len(ring)), lat/float64(len(ring)))
+}
+
+// LocationJSON 入库的项目位置, 无位置时为 nil(NULL)
+func (g *ProjectGeo) LocationJSON() json.RawMessage {
+	if location := g.location(); location != nil {
+		return location.JSON()
+	}
+	return nil
+}
+
+// BoundaryJSON 入库的红线范围, 无红线范围时为 nil(NULL)
+func (g *ProjectGeo) BoundaryJSON() json.RawMessage {
+	if g.Boundary == nil {
+		return nil
+	}
+	return geoJSON(g.Boundary)
+}
+
+func geoJSON(v interface{}) json.RawMessage {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return buf
+}
+
+// MapFilterParam 地图查询条件, 筛选与实施库统计一致
+type MapFilterParam struct {
+	ImplementGovCountFilter
+	// 所属街镇(多选)
+	Townships []string `json:"townships" filter:"township,in"`
+	// 项目状态(多选) 0:未开工,2:开工建设,4:已竣工
+	Statuses []int `json:"statuses" filter:"status,in"`
+	// 地图范围 [最小经度, 最小纬度, 最大经度, 最大纬度], 按项目位置筛选, 不传时不限范围
+	BBox []float64 `json:"bbox"`
+	// 是否同时返回红线范围要素, 仅项目点位接口有效
+	WithBoundary bool `json:"with_boundary"`
+}
+
+// ValidateBBox 校验地图范围
+func (p *MapFilterParam) ValidateBBox() error {
+	if len(p.BBox) == 0 {
+		return nil
+	}
+	if len(p.BBox) != 4 {
+		return errors.New("bbox 须为[最小经度, 最小纬度, 最大经度, 最大纬度]")
+	}
+	for i := 0; i < 4; i += 2 {
+		if err := checkPosition(p.BBox[i : i+2]); err != nil {
+			return err
+		}
+	}
+	if p.BBox[0] > p.BBox[2] || p.BBox[1] > p.BBox[3] {
+		return errors.New("bbox 最小值不能大于最大值")
+	}
+	return nil
+}
+
+// GeoFeature GeoJSON 要素
+type GeoFeature struct {
+	// 固定为 Feature
+	Type string `json:"type"`
+	// 几何, 无位置时为 null
+	Geometry json.RawMessage `json:"geometry"`
+	// 属性
+	Properties interface{} `json:"properties"`
+}
+
+// GeoFeatureCollection GeoJSON 要素集合
+type GeoFeatureCollection struct {
+	// 固定为 FeatureCollection
+	Type string `json:"type"`
+	// 要素
+	Features []GeoFeature `json:"features"`
+}
+
+func NewGeoFeatureCollection(features []GeoFeature) *GeoFeatureCollection {
+	return &GeoFeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+func NewGeoFeature(geometry json.RawMessage, properties interface{}) GeoFeature {
+	if len(geometry) == 0 {
+		geometry = json.RawMessage("null")
+	}
+	return GeoFeature{Type: "Feature", Geometry: geometry, Properties: properties}
+}
+
+// 项目要素图层
+const (
+	MapLayerLocation = "location"
+	MapLayerBoundary = "boundary"
+)
+
+// MapProjectProps 地图项目要素属性
+type MapProjectProps struct {
+	// 项目id
+	ID int64 `json:"id"`
+	// 来源 1:政府投资项目,2:产业项目
+	Source int `json:"source"`
+	// 图层 location:项目位置, boundary:红线范围
+	Layer string `json:"layer"`
+	// 项目名称
+	Name string `json:"name"`
+	// 项目分类 1:政府投资项目 2：社会产业项目
+	Type int `json:"type"`
+	// 项目级别
+	Level *int `json:"level"`
+	// 项目类型
+	ProjectType *int `json:"project_type"`
+	// 项目状态 0:未开工,2:开工建设,4:已竣工
+	Status int `json:"status"`
+	// 所属街镇
+	Township string `json:"township"`
+	// 总投资(万)
+	TotalInvestment *float64 `json:"total_investment"`
+	// 进度 1：红灯 2绿灯, 产业项目为空
+	Progress *int `json:"progress"`
+}
+
+// MapTownshipProps 地图街镇汇总要素属性, 要素位置为街镇内有位置项目的平均位置
+type MapTownshipProps struct {
+	// 所属街镇, 未填写时为空
+	Township string `json:"township"`
+	// 项目数
+	Count int64 `json:"count"`
+	// 总投资(万)
+	TotalInvestment float64 `json:"total_investment"`
+	// 未开工项目数
+	UnStart int64 `json:"un_start"`
+	// 开工建设项目数
+	Started int64 `json:"started"`
+	// 已竣工项目数
+	Finished int64 `json:"finished"`
+	// 红灯项目数
+	Red int64 `json:"red"`
+}
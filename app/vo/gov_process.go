@@ -75,11 +75,11 @@ type GovProgressResp struct {
 	PlanProgress string `json:"plan_progress"`
 	//本月完成投资额(万)
 	PlanInvested *float64 `json:"plan_invested"`
-	//当年累计投资额(万)
+	//当年累计投资额(万), 当年全部月份合计; 一月至本月累计见 total_plan_invested
 	YearSumInvested *float64 `json:"year_sum_invested"`
 	//上月完成固投(万)
 	LastMonthFixedInvested *float64 `json:"last_month_fixed_invested"`
-	//当年累计固投(万)
+	//当年累计固投(万), 当年全部月份合计
 	YearSumFixedInvested *float64 `json:"year_sum_fixed_invested"`
 	//本月完成形象进度
 	ActualProgress string `json:"actual_progress"`
@@ -88,7 +88,9 @@ type GovProgressResp struct {
 	//本月产生联系单变更
 	ChangeContent string `json:"change_content"`
 	// 本月新增合同信息
-	Contracts string `json:"contracts"`
+	Contracts []ContractResp `json:"contracts"`
+	// 本月现场照片
+	Photos []ProgressPhotoResp `json:"photos"`
 	//备注
 	Comment string `json:"comment"`
 	// 一月至本月计划累计完成投资额
@@ -101,7 +103,12 @@ type GovProgressResp struct {
 	Status int `json:"status"`
 }
 
-func NewGovProgressResponse(r *models.GovProgress, total_plan_invested, start_sum, start_fixed float64) (*GovProgressResp, error) {
+func NewGovProgressResponse(r *models.GovProgress, contracts []models.Contract, total_plan_invested, start_sum,
+	start_fixed float64) (*GovProgressResp, error) {
+	contractResp, err := NewContractResponses(contracts)
+	if err != nil {
+		return nil, err
+	}
 	return &GovProgressResp{
 		ID:                     r.ID,
 		ProjectID:              r.ProjectID,
@@ -116,7 +123,7 @@ func NewGovProgressResponse(r *models.GovProgress, total_plan_invested, start_su
 		ActualProgress:         r.ActualProgress,
 		ProblemDetail:          string(r.ProblemDetail),
 		ChangeContent:          string(r.ChangeContent),
-		Contracts:              string(r.Contracts),
+		Contracts:              contractResp,
 		Comment:                r.Comment,
 		TotalPlanInvested:      total_plan_invested,
 		StartSumInvested:       start_sum,
@@ -140,14 +147,30 @@ type GovProgressUpdateReq struct {
 	ProblemDetail string `json:"problem_detail"`
 	//本月产生联系单变更
 	ChangeContent string `json:"change_content"`
-	// 本月新增合同信息
-	Contracts string `json:"contracts"`
+	// 本月新增合同信息, 保存时整体替换本月已填报的合同
+	Contracts []ContractReq `json:"contracts"`
 	//备注
 	Comment string `json:"comment"`
 	// 操作方式：1：保存 2：提交
 	Method int `json:"method"`
 }
 
+func (g *GovProgressUpdateReq) ToContracts(openID string, progress *models.GovProgress) ([]models.Contract, error) {
+	contracts := make([]models.Contract, 0, len(g.Contracts))
+	for i := range g.Contracts {
+		contract, err := g.Contracts[i].ToModel(openID)
+		if err != nil {
+			return nil, err
+		}
+		contract.ProjectID = progress.ProjectID
+		contract.ProgressID = &progress.ID
+		contract.Year = progress.Year
+		contract.Month = progress.Month
+		contracts = append(contracts, *contract)
+	}
+	return contracts, nil
+}
+
 func (g *GovProgressUpdateReq) ToMap(openID string) map[string]interface{} {
 	status := int(0)
 	if g.Method == 2 {
@@ -161,7 +184,6 @@ func (g *GovProgressUpdateReq) ToMap(openID string) map[string]interface{} {
 		"actual_progress":           g.ActualProgress,
 		"problem_detail":            json.RawMessage([]byte(g.ProblemDetail)),
 		"change_content":            json.RawMessage([]byte(g.ChangeContent)),
-		"contracts":                 json.RawMessage([]byte(g.Contracts)),
 		"comment":                   g.Comment,
 		"update_by":                 openID,
 		"status":                    status,
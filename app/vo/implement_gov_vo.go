@@ -9,67 +9,57 @@ import (
 )
 
 type ImplementGovFilterParam struct {
-	//项目名称
-	Name string `json:"name"`
-	// 项目级别
-	Level *int `json:"level"`
-	// 项目分类 1:政府投资项目 2：社会产业项目
-	Type *int `json:"type"`
-	// 项目类型
-	ProjectType *int `json:"project_type"`
-	// 建设主体  ***注意:（所有参数，有就传，无则不传）***
-	ConstructSubject string `json:"construct_subject"`
-	// 标签 0:省重点实施项目,1:省重点预备项目,2:省重大产业项目;3:省4+1项目;4:省6千亿项目;5:市重点实施项目;6:市重点预备项目;7:无重点类型;8: 152工程
-	PointType *int `json:"point_type"`
-	// 计划开工开始时间
-	PlanBegin string `json:"plan_begin"`
-	// 计划开工结束时间
-	PlanEnd string `json:"plan_end"`
-	// 开工开始时间
-	StartTime string `json:"start_time"`
-	// 开工结束时间
-	EndTime string `json:"end_time"`
+	ImplementGovCountFilter
 	// 状态(必传参数) -1: 当年全部(注意cur_year_all_begin和cur_year_all_end带上) 0:未开工, 2:开工建设; 4:竣工库(如果是 当年竣工，必须要带上cur_year_all_begin和cur_year_all_end参数)
 	Status *int `json:"status"`
 	// 当年 起始时间(闭区间) eg: 2022-01-01 00:00:00
 	CurYearBegin string `json:"cur_year_all_begin"`
 	// 当年 终止时间(开区间) eg : 2023-01-01 00:00:00
 	CurYearEnd string `json:"cur_year_all_end"`
-	// 总投资额范围开始
-	BeginInvest *float64 `json:"begin_invest"`
-	// 总投资额范围结束
-	EndInvest *float64 `json:"end_invest"`
-	// 责任单位
-	DutyUnit string `json:"duty_unit"`
+	// 所属街镇
+	Township string `json:"township" filter:"township"`
+	// 所属街镇(多选)
+	Townships []string `json:"townships" filter:"township,in"`
 }
 
 type ImplementGovCountFilter struct {
+	EmptyFilter
 	//项目名称
-	Name string `json:"name"`
+	Name string `json:"name" filter:"name"`
 	// 项目级别
-	Level *int `json:"level"`
+	Level *int `json:"level" filter:"level"`
+	// 项目级别(多选)
+	Levels []int `json:"levels" filter:"level,in"`
 	// 项目类型
-	ProjectType *int `json:"project_type"`
+	ProjectType *int `json:"project_type" filter:"project_type"`
+	// 项目类型(多选)
+	ProjectTypes []int `json:"project_types" filter:"project_type,in"`
 	// 建设主体  ***注意:（所有参数，有就传，无则不传）***
-	ConstructSubject string `json:"construct_subject"`
+	ConstructSubject string `json:"construct_subject" filter:"construct_subject"`
 	// 标签 0:省重点实施项目,1:省重点预备项目,2:省重大产业项目;3:省4+1项目;4:省6千亿项目;5:市重点实施项目;6:市重点预备项目;7:无重点类型;8: 152工程
-	PointType *int `json:"point_type"`
+	PointType *int `json:"point_type" filter:"point_type"`
+	// 标签(多选)
+	PointTypes []int `json:"point_types" filter:"point_type,in"`
 	// 计划开工开始时间
-	PlanBegin string `json:"plan_begin"`
+	PlanBegin string `json:"plan_begin" filter:"plan_begin,gte"`
 	// 计划开工结束时间
-	PlanEnd string `json:"plan_end"`
+	PlanEnd string `json:"plan_end" filter:"plan_begin,lte"`
 	// 开工开始时间
-	StartTime string `json:"start_time"`
+	StartTime string `json:"start_time" filter:"start_time,gte"`
 	// 开工结束时间
-	EndTime string `json:"end_time"`
+	EndTime string `json:"end_time" filter:"start_time,lte"`
 	// 总投资额范围开始
-	BeginInvest *float64 `json:"begin_invest"`
+	BeginInvest *float64 `json:"begin_invest" filter:"total_investment,gte"`
 	// 总投资额范围结束
-	EndInvest *float64 `json:"end_invest"`
+	EndInvest *float64 `json:"end_invest" filter:"total_investment,lte"`
 	// 责任单位
-	DutyUnit string `json:"duty_unit"`
+	DutyUnit string `json:"duty_unit" filter:"duty_unit"`
+	// 责任单位(多选)
+	DutyUnits []string `json:"duty_units" filter:"duty_unit,in"`
 	// 项目分类 1:政府投资项目 2：社会产业项目
-	Type *int `json:"type"`
+	Type *int `json:"type" filter:"type"`
+	// 项目分类(多选)
+	Types []int `json:"types" filter:"type,in"`
 }
 
 type ImplementGovReq struct {
@@ -107,7 +97,7 @@ type ImplementGovReq struct {
 	NoConformUsePlan *float64 `json:"no_conform_use_plan"`
 	// 选址红线 0:有拆迁,1:无拆迁
 	SiteRed *int `json:"site_red"`
-	// 无拆迁照片
+	// 无拆迁照片, 已由项目附件(现场照片)替代, 写入时同步为该类别附件
 	SitePhoto string `json:"site_photo"`
 	// 需征地面积
 	NeedCollect *float64 `json:"need_collect"`
@@ -115,7 +105,7 @@ type ImplementGovReq struct {
 	NeedPeopleMove *int `json:"need_people_move"`
 	// 企/事业单位(家)
 	CompanyBusiness *int `json:"company_business"`
-	// CAD文件ID(上传文件接口返回的ID)
+	// CAD文件ID(上传文件接口返回的ID), 已由项目附件(CAD图纸)替代, 写入时同步为该类别附件
 	UploadCadID string `json:"upload_cad_id"`
 	// 总投资
 	TotalInvestment *float64 `json:"total_investment"`
@@ -135,8 +125,12 @@ type ImplementGovReq struct {
 	ProjectCode string `json:"project_code"`
 	// 责任单位
 	DutyUnit string `json:"duty_unit"`
+	// 所属街镇
+	Township string `json:"township"`
 	// 项目本质类型 1:政府项目 2：产业项目
 	Type int `json:"type"`
+	// 项目位置及红线范围
+	ProjectGeo
 }
 
 func (r *ImplementGovReq) ToModel(openID string) *models.ImplementGov {
@@ -172,7 +166,10 @@ func (r *ImplementGovReq) ToModel(openID string) *models.ImplementGov {
 		Status:                  constant.UnStart,
 		ProjectCode:             r.ProjectCode,
 		DutyUint:                r.DutyUnit,
+		Township:                r.Township,
 		Type:                    r.Type,
+		Location:                r.LocationJSON(),
+		Boundary:                r.BoundaryJSON(),
 		Base: models.Base{
 			UpdateBy: openID,
 			CreateBy: openID,
@@ -217,7 +214,7 @@ type ImplementGovResp struct {
 	NoConformUsePlan *float64 `json:"no_conform_use_plan"`
 	// 选址红线 0:有拆迁,1:无拆迁
 	SiteRed *int `json:"site_red"`
-	// 无拆迁照片
+	// 无拆迁照片, 已由项目附件(现场照片)替代, 仅保留兼容
 	SitePhoto string `json:"site_photo"`
 	// 需征地面积
 	NeedCollect *float64 `json:"need_collect"`
@@ -225,7 +222,7 @@ type ImplementGovResp struct {
 	NeedPeopleMove *int `json:"need_people_move"`
 	// 企/事业单位(家)
 	CompanyBusiness *int `json:"company_business"`
-	// CAD文件ID(上传文件接口返回的ID)
+	// CAD文件ID(上传文件接口返回的ID), 已由项目附件(CAD图纸)替代, 仅保留兼容
 	UploadCadID string `json:"upload_cad_id"`
 	// 总投资
 	TotalInvestment *float64 `json:"total_investment"`
@@ -235,6 +232,8 @@ type ImplementGovResp struct {
 	MoveLandComsumption *float64 `json:"move_land_comsumption"`
 	// 资金详情
 	InvestmentDetail string `json:"investment_detail"`
+	// 资金详情(解析后), 按资金来源及年份
+	InvestmentDetails []InvestmentDetail `json:"investment_details"`
 	// 前期工作联系人
 	Contract string `json:"contract"`
 	// 联系人手机号
@@ -245,8 +244,14 @@ type ImplementGovResp struct {
 	ProjectCode string `json:"project_code"`
 	// 责任单位
 	DutyUnit string `json:"duty_unit"`
+	// 所属街镇
+	Township string `json:"township"`
 	// 项目本质类型 1:政府项目 2：产业项目
 	Type int `json:"type"`
+	// 项目位置(GeoJSON Point)
+	Location json.RawMessage `json:"location"`
+	// 红线范围(GeoJSON Polygon)
+	Boundary json.RawMessage `json:"boundary"`
 }
 
 func NewImplementGovResponse(r *models.ImplementGov) (*ImplementGovResp, error) {
@@ -278,12 +283,16 @@ func NewImplementGovResponse(r *models.ImplementGov) (*ImplementGovResp, error)
 		ProjectComsumption:      r.ProjectComsumption,
 		MoveLandComsumption:     r.MoveLandComsumption,
 		InvestmentDetail:        string(r.InvestmentDetail),
+		InvestmentDetails:       parseInvestmentDetails(r.InvestmentDetail),
 		Contract:                r.Contract,
 		Phone:                   r.Phone,
 		Status:                  r.Status,
 		ProjectCode:             r.ProjectCode,
 		DutyUnit:                r.DutyUint,
+		Township:                r.Township,
 		Type:                    r.Type,
+		Location:                r.Location,
+		Boundary:                r.Boundary,
 	}, nil
 }
 
@@ -312,6 +321,8 @@ type ListImplementGovResp struct {
 	ProjectCode string `json:"project_code"`
 	// 责任单位
 	DutyUnit string `json:"duty_unit"`
+	// 所属街镇
+	Township string `json:"township"`
 	// 项目本质类型 1:政府项目 2：产业项目
 	Type int `json:"type"`
 	// 投资额
@@ -9,26 +9,35 @@ import (
 )
 
 type ImpleIndustryFilterParam struct {
+	EmptyFilter
 	//项目名称
-	Name string `json:"name"`
+	Name string `json:"name" filter:"name"`
 	// 项目级别
-	Level *int `json:"level"`
+	Level *int `json:"level" filter:"level"`
+	// 项目级别(多选)
+	Levels []int `json:"levels" filter:"level,in"`
 	// 项目类型
-	ProjectType *int `json:"project_type"`
+	ProjectType *int `json:"project_type" filter:"project_type"`
+	// 项目类型(多选)
+	ProjectTypes []int `json:"project_types" filter:"project_type,in"`
 	// 建设主体  ***注意:（所有参数，有就传，无则不传）***
-	ConstructSubject string `json:"construct_subject"`
+	ConstructSubject string `json:"construct_subject" filter:"construct_subject"`
 	// 标签 0:省重点实施项目,1:省重点预备项目,2:省重大产业项目;3:省4+1项目;4:省6千亿项目;5:市重点实施项目;6:市重点预备项目;7:无重点类型;8: 152工程
-	PointType *int `json:"point_type"`
+	PointType *int `json:"point_type" filter:"point_type"`
+	// 标签(多选)
+	PointTypes []int `json:"point_types" filter:"point_type,in"`
 	// 计划开始时间
-	PlanBegin string `json:"plan_begin"`
+	PlanBegin string `json:"plan_begin" filter:"create_at,gte"`
 	// 计划结束时间
-	PlanEnd string `json:"plan_end"`
+	PlanEnd string `json:"plan_end" filter:"create_at,lte"`
 	// 状态
-	Status *int `json:"status"`
+	Status *int `json:"status" filter:"status"`
+	// 状态(多选)
+	Statuses []int `json:"statuses" filter:"status,in"`
 	// 当年起始时间(闭区间) eg: 2022-01-01 00:00:00
-	CurYearBegin string `json:"cur_year_begin"`
+	CurYearBegin string `json:"cur_year_begin" filter:"finish_time,gte"`
 	// 当年终止时间(开区间) eg : 2023-01-01 00:00:00
-	CurYearEnd string `json:"cur_year_end"`
+	CurYearEnd string `json:"cur_year_end" filter:"finish_time,lt"`
 }
 
 type ImpleIndustryReq struct {
@@ -66,7 +75,7 @@ type ImpleIndustryReq struct {
 	NoConformUsePlan *float64 `json:"no_conform_use_plan"`
 	// 选址红线 0:有拆迁,1:无拆迁
 	SiteRed *int `json:"site_red"`
-	// 无拆迁照片
+	// 无拆迁照片, 已由项目附件(现场照片)替代, 写入时同步为该类别附件
 	SitePhoto string `json:"site_photo"`
 	// 需征地面积
 	NeedCollect *float64 `json:"need_collect"`
@@ -74,7 +83,7 @@ type ImpleIndustryReq struct {
 	NeedPeopleMove *int `json:"need_people_move"`
 	// 企/事业单位(家)
 	CompanyBusiness *int `json:"company_business"`
-	// CAD文件ID(上传文件接口返回的ID)
+	// CAD文件ID(上传文件接口返回的ID), 已由项目附件(CAD图纸)替代, 写入时同步为该类别附件
 	UploadCadID string `json:"upload_cad_id"`
 	// 总投资
 	TotalInvestment *float64 `json:"total_investment"`
@@ -90,6 +99,8 @@ type ImpleIndustryReq struct {
 	Contract string `json:"contract"`
 	// 联系人手机号
 	Phone string `json:"phone"`
+	// 项目位置及红线范围
+	ProjectGeo
 }
 
 func (r *ImpleIndustryReq) ToModel(openID string) *models.ImpleIndustry {
@@ -123,6 +134,8 @@ func (r *ImpleIndustryReq) ToModel(openID string) *models.ImpleIndustry {
 		Contract:                r.Contract,
 		Phone:                   r.Phone,
 		Status:                  constant.UnStart,
+		Location:                r.LocationJSON(),
+		Boundary:                r.BoundaryJSON(),
 		Base: models.Base{
 			UpdateBy: openID,
 			CreateBy: openID,
@@ -167,7 +180,7 @@ type ImpleIndustryResp struct {
 	NoConformUsePlan *float64 `json:"no_conform_use_plan"`
 	// 选址红线 0:有拆迁,1:无拆迁
 	SiteRed *int `json:"site_red"`
-	// 无拆迁照片
+	// 无拆迁照片, 已由项目附件(现场照片)替代, 仅保留兼容
 	SitePhoto string `json:"site_photo"`
 	// 需征地面积
 	NeedCollect *float64 `json:"need_collect"`
@@ -175,7 +188,7 @@ type ImpleIndustryResp struct {
 	NeedPeopleMove *int `json:"need_people_move"`
 	// 企/事业单位(家)
 	CompanyBusiness *int `json:"company_business"`
-	// CAD文件ID(上传文件接口返回的ID)
+	// CAD文件ID(上传文件接口返回的ID), 已由项目附件(CAD图纸)替代, 仅保留兼容
 	UploadCadID string `json:"upload_cad_id"`
 	// 总投资
 	TotalInvestment *float64 `json:"total_investment"`
@@ -185,12 +198,18 @@ type ImpleIndustryResp struct {
 	MoveLandComsumption *float64 `json:"move_land_comsumption"`
 	// 资金详情
 	InvestmentDetail string `json:"investment_detail"`
+	// 资金详情(解析后), 按资金来源及年份
+	InvestmentDetails []InvestmentDetail `json:"investment_details"`
 	// 前期工作联系人
 	Contract string `json:"contract"`
 	// 联系人手机号
 	Phone string `json:"phone"`
 	// 项目状态 0:未开工,1:开工待审核,2:已开工;3:竣工待审核;4:已竣工"
 	Status int `json:"status"`
+	// 项目位置(GeoJSON Point)
+	Location json.RawMessage `json:"location"`
+	// 红线范围(GeoJSON Polygon)
+	Boundary json.RawMessage `json:"boundary"`
 }
 
 func NewImpleIndustryResponse(r *models.ImpleIndustry) (*ImpleIndustryResp, error) {
@@ -221,9 +240,12 @@ func NewImpleIndustryResponse(r *models.ImpleIndustry) (*ImpleIndustryResp, erro
 		ProjectComsumption:      r.ProjectComsumption,
 		MoveLandComsumption:     r.MoveLandComsumption,
 		InvestmentDetail:        string(r.InvestmentDetail),
+		InvestmentDetails:       parseInvestmentDetails(r.InvestmentDetail),
 		Contract:                r.Contract,
 		Phone:                   r.Phone,
 		Status:                  r.Status,
+		Location:                r.Location,
+		Boundary:                r.Boundary,
 	}, nil
 }
 
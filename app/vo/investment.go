@@ -0,0 +1,221 @@
+package vo
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// 资金来源 0:区财政;1:自筹;2:其他
+const (
+	FundSourceFinance = 0
+	FundSourceSelf    = 1
+	FundSourceOther   = 2
+)
+
+var FundSourceNames = map[int]string{
+	FundSourceFinance: "区财政",
+	FundSourceSelf:    "自筹",
+	FundSourceOther:   "其他",
+}
+
+// ParseInvestmentDetail 将资金详情解析为按资金来源、年份的明细,
+// 兼容 {"year":"2022","value":20} 与 {"2022":20} 两种年度写法, 数值可为字符串
+func ParseInvestmentDetail(raw []byte) ([]InvestmentDetail, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return []InvestmentDetail{}, nil
+	}
+	// 前端以字符串提交, 历史数据可能被二次编码
+	if raw[0] == '"' {
+		s := ""
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, err
+		}
+		return ParseInvestmentDetail([]byte(s))
+	}
+	items := make([]map[string]interface{}, 0)
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+	res := make([]InvestmentDetail, 0, len(items))
+	for _, item := range items {
+		if len(item) == 0 {
+			continue
+		}
+		source, err := toFloat(item["type"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid type: %w", err)
+		}
+		total, err := toFloat(item["total"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid total: %w", err)
+		}
+		detail, err := parseInvestDetail(item["detail"])
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, InvestmentDetail{
+			Type:   int(source),
+			Total:  total,
+			Detail: detail,
+		})
+	}
+	return res, nil
+}
+
+func parseInvestDetail(v interface{}) ([]InvestDetail, error) {
+	rows := make([]map[string]interface{}, 0)
+	switch d := v.(type) {
+	case nil:
+	case map[string]interface{}:
+		rows = append(rows, d)
+	case []interface{}:
+		for i := range d {
+			if m, ok := d[i].(map[string]interface{}); ok {
+				rows = append(rows, m)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid detail: %v", v)
+	}
+	res := make([]InvestDetail, 0, len(rows))
+	for _, row := range rows {
+		total, err := toFloat(row["total"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid detail total: %w", err)
+		}
+		comment, _ := row["comment"].(string)
+		if year, ok := row["year"]; ok {
+			value, err := toFloat(row["value"])
+			if err != nil {
+				return nil, fmt.Errorf("invalid detail value: %w", err)
+			}
+			res = append(res, InvestDetail{Total: total, Year: strings.TrimSpace(fmt.Sprint(year)), Value: value, Comment: comment})
+			continue
+		}
+		for key, val := range row {
+			if !isYear(key) {
+				continue
+			}
+			value, err := toFloat(val)
+			if err != nil {
+				return nil, fmt.Errorf("invalid detail %s: %w", key, err)
+			}
+			res = append(res, InvestDetail{Total: total, Year: key, Value: value, Comment: comment})
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Year < res[j].Year
+	})
+	return res, nil
+}
+
+func isYear(s string) bool {
+	if len(s) != 4 {
+		return false
+	}
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return n, nil
+	case int64:
+		return float64(n), nil
+	case string:
+		n = strings.TrimSpace(n)
+		if n == "" {
+			return 0, nil
+		}
+		return strconv.ParseFloat(n, 64)
+	default:
+		return 0, fmt.Errorf("not a number: %v", v)
+	}
+}
+
+// PlanBySource 某年各资金来源的计划投资额
+func PlanBySource(details []InvestmentDetail, year int) map[int]float64 {
+	res := make(map[int]float64)
+	y := strconv.Itoa(year)
+	for i := range details {
+		for j := range details[i].Detail {
+			if details[i].Detail[j].Year == y {
+				res[details[i].Type] += details[i].Detail[j].Value
+			}
+		}
+	}
+	return res
+}
+
+// parseInvestmentDetails 响应中附带解析后的资金详情, 历史数据格式错误时返回空
+func parseInvestmentDetails(raw []byte) []InvestmentDetail {
+	res, err := ParseInvestmentDetail(raw)
+	if err != nil {
+		return []InvestmentDetail{}
+	}
+	return res
+}
+
+type ReconcileFilterParam struct {
+	// 年份(必传)
+	Year int `json:"year"`
+	// 项目ID ***注意:（以下参数，有就传，无则不传）***
+	ProjectID *int64 `json:"project_id"`
+	// 责任单位
+	DutyUnit string `json:"duty_unit"`
+	// 所属街镇
+	Township string `json:"township"`
+	// 项目类型
+	ProjectType *int `json:"project_type"`
+	// 滞后阈值, 时间进度与资金完成率之差超过该值视为严重滞后, 默认0.3
+	LagThreshold *float64 `json:"lag_threshold"`
+	// 只看异常(超计划或严重滞后)项目
+	OnlyAbnormal bool `json:"only_abnormal"`
+}
+
+type SourcePlan struct {
+	// 资金来源 0:区财政;1:自筹;2:其他
+	Type int `json:"type"`
+	// 资金来源名称
+	Name string `json:"name"`
+	// 当年计划资金(万)
+	Plan float64 `json:"plan"`
+}
+
+type ReconcileResp struct {
+	// 项目ID
+	ProjectID int64 `json:"project_id"`
+	// 项目名称
+	Name string `json:"name"`
+	// 项目编码
+	ProjectCode string `json:"project_code"`
+	// 责任单位
+	DutyUnit string `json:"duty_unit"`
+	// 年份
+	Year int `json:"year"`
+	// 各资金来源当年计划
+	Sources []SourcePlan `json:"sources"`
+	// 当年计划资金合计(万)
+	PlanTotal float64 `json:"plan_total"`
+	// 当年已填报完成投资额(万)
+	Invested float64 `json:"invested"`
+	// 资金完成率 完成投资/计划资金, 无计划时为0
+	Completeness float64 `json:"completeness"`
+	// 时间进度 已过月份/12
+	TimeProgress float64 `json:"time_progress"`
+	// 是否超计划
+	Overrun bool `json:"overrun"`
+	// 是否严重滞后
+	Lagging bool `json:"lagging"`
+	// 资金详情解析失败(历史数据格式错误)
+	InvalidDetail bool `json:"invalid_detail"`
+}
@@ -0,0 +1,91 @@
+package vo
+
+import (
+	"fmt"
+	"lpms/constant"
+)
+
+// 枚举字段中文名称, 用于导出及展示
+
+var LevelNames = map[int]string{
+	0: "区级",
+	1: "街镇级",
+}
+
+var ProjectTypeNames = map[int]string{
+	0: "安置房",
+	1: "道路交通",
+	2: "市政设施",
+	3: "提升整治",
+	4: "卫生",
+	5: "五水共治",
+	6: "学校",
+	7: "其他",
+}
+
+var PointTypeNames = map[int]string{
+	0: "省重点实施项目",
+	1: "省重点预备项目",
+	2: "省重大产业项目",
+	3: "省4+1项目",
+	4: "省6千亿项目",
+	5: "市重点实施项目",
+	6: "市重点预备项目",
+	7: "无重点类型",
+	8: "152工程",
+}
+
+var ImplementTypeNames = map[int]string{
+	0: "新开工",
+	1: "续建",
+}
+
+var EnterDBTypeNames = map[int]string{
+	0: "A类",
+	1: "B类",
+	2: "C类",
+}
+
+var SiteRedNames = map[int]string{
+	0: "有拆迁",
+	1: "无拆迁",
+}
+
+var ResearchNames = map[int]string{
+	0: "编制中",
+	1: "已完成",
+}
+
+var ReserveStatusNames = map[int]string{
+	constant.Draft:             "草稿",
+	constant.EnteredDB:         "已入库",
+	constant.EarlyPlan:         "前期计划",
+	constant.Posted:            "已发文",
+	constant.OutStorageInspect: "出库审核中",
+	constant.OutStorage:        "已出库",
+}
+
+var ImplementStatusNames = map[int]string{
+	constant.UnStart:         "未开工",
+	constant.StartInspecting: "开工待审核",
+	constant.Started:         "已开工",
+	constant.FinishInspect:   "竣工待审核",
+	constant.Finished:        "已竣工",
+	constant.Change:          "项目变更",
+}
+
+var ProjectNatureNames = map[int]string{
+	constant.GovProject:      "政府项目",
+	constant.IndustryProject: "产业项目",
+}
+
+// Label 枚举值对应的中文名称, 空值返回空串, 未知值原样返回
+func Label(names map[int]string, v *int) string {
+	if v == nil {
+		return ""
+	}
+	if name, ok := names[*v]; ok {
+		return name
+	}
+	return fmt.Sprint(*v)
+}
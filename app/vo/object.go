@@ -1,14 +1,106 @@
 package vo
 
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ObjectResp 下载的文件, Content 由响应写出后关闭
 type ObjectResp struct {
 	// 对象id
 	ID string `json:"id"`
 	// 对象名称
 	Filename string `json:"filename"`
+	// 文件类型(MIME)
+	ContentType string `json:"content_type"`
+	// 文件大小(字节)
+	Size int64 `json:"size"`
+	// 内容标识
+	ETag string `json:"etag"`
+	// 最后修改时间
+	ModTime time.Time `json:"mod_time"`
 	// 对象内容
-	Content string `json:"content"`
+	Content io.ReadSeekCloser `json:"-"`
 }
 
 type UUID struct {
 	ID string `json:"id"`
 }
+
+// ObjectMeta 上传后的文件信息
+type ObjectMeta struct {
+	// 对象id
+	ID string `json:"id"`
+	// 文件名称
+	Filename string `json:"filename"`
+	// 文件大小(字节)
+	Size int64 `json:"size"`
+	// 文件类型(MIME)
+	ContentType string `json:"content_type"`
+}
+
+// ObjectURL 文件下载签名链接
+type ObjectURL struct {
+	// 下载地址(相对路径), 无需登录令牌
+	URL string `json:"url"`
+	// 过期时间
+	ExpireAt time.Time `json:"expire_at"`
+}
+
+// ObjectUploadReq 申请直传存储
+type ObjectUploadReq struct {
+	// 文件名称
+	Filename string `json:"filename"`
+	// 文件大小(字节), 完成上传时校验
+	Size int64 `json:"size"`
+	// 文件类型(MIME), 不传时按扩展名推断; 完成上传时以文件头识别的类型为准
+	ContentType string `json:"content_type"`
+	// 上传类别 default:默认, cad:CAD文件, image:图片, document:文档; 不传时为 default
+	Category string `json:"category"`
+}
+
+// Validate 校验文件名称及大小
+func (r *ObjectUploadReq) Validate() error {
+	if r.Filename == "" || utf8.RuneCountInString(r.Filename) > 200 || strings.ContainsAny(r.Filename, `/\`) {
+		return errors.New("文件名称不能为空、不超过200个字符且不能包含路径")
+	}
+	if r.Size <= 0 {
+		return errors.New("文件大小须大于0")
+	}
+	return nil
+}
+
+// ObjectUploadURL 直传存储地址
+type ObjectUploadURL struct {
+	// 对象id
+	ID string `json:"id"`
+	// 上传地址, 以 PUT 方式直接上传文件内容
+	URL string `json:"url"`
+	// 上传方式
+	Method string `json:"method"`
+	// 上传凭证, 上传完成后调用完成上传接口时传入
+	UploadToken string `json:"upload_token"`
+	// 上传地址过期时间
+	ExpireAt time.Time `json:"expire_at"`
+}
+
+// ObjectFinalizeReq 完成直传
+type ObjectFinalizeReq struct {
+	// 申请直传时返回的上传凭证
+	UploadToken string `json:"upload_token"`
+}
+
+// ObjectReconcileReport 文件记录与存储对账结果
+type ObjectReconcileReport struct {
+	// 超过保留期且未被任何项目引用的文件id
+	Unreferenced []string `json:"unreferenced"`
+	// 存储中缺少内容的文件id
+	Missing []string `json:"missing"`
+	// 超过保留期且没有文件记录的存储对象路径
+	Stray []string `json:"stray"`
+	// 是否已删除未引用的文件及没有记录的存储对象
+	Removed bool `json:"removed"`
+}
@@ -0,0 +1,181 @@
+package vo
+
+import (
+	"lpms/app/models"
+	"strconv"
+)
+
+// 统计维度
+const (
+	DimensionAll         = "all"
+	DimensionType        = "type"
+	DimensionProjectType = "project_type"
+	DimensionLevel       = "level"
+	DimensionPointType   = "point_type"
+	DimensionDutyUnit    = "duty_unit"
+)
+
+type PortfolioStatFilter struct {
+	ImplementGovCountFilter
+	// 统计年份, 默认当年
+	Year int `json:"year"`
+	// 统计截至月份, 默认当年为当月, 往年为12月
+	Month int `json:"month"`
+	// 是否按月末快照统计, 快照不存在时返回记录不存在
+	Snapshot bool `json:"snapshot"`
+}
+
+type PortfolioStatItem struct {
+	// 分组取值, 未填写时为空
+	Key string `json:"key"`
+	// 分组名称
+	Name string `json:"name"`
+	// 项目数
+	Count int64 `json:"count"`
+	// 总投资(万)
+	TotalInvestment float64 `json:"total_investment"`
+	// 当年累计完成投资(万), 一月至统计月份
+	YearInvested float64 `json:"year_invested"`
+	// 完成率 当年累计完成投资/总投资
+	CompletionRate float64 `json:"completion_rate"`
+}
+
+type PortfolioStatResp struct {
+	// 统计年份
+	Year int `json:"year"`
+	// 统计截至月份
+	Month int `json:"month"`
+	// 全部项目汇总
+	Summary PortfolioStatItem `json:"summary"`
+	// 按项目分类(政府/产业)
+	ByType []PortfolioStatItem `json:"by_type"`
+	// 按项目类型
+	ByProjectType []PortfolioStatItem `json:"by_project_type"`
+	// 按项目级别
+	ByLevel []PortfolioStatItem `json:"by_level"`
+	// 按重点类型
+	ByPointType []PortfolioStatItem `json:"by_point_type"`
+	// 按责任单位
+	ByDutyUnit []PortfolioStatItem `json:"by_duty_unit"`
+}
+
+func NewPortfolioStatResponse(year, month int, stats []models.PortfolioStat) *PortfolioStatResp {
+	resp := &PortfolioStatResp{
+		Year:          year,
+		Month:         month,
+		ByType:        make([]PortfolioStatItem, 0),
+		ByProjectType: make([]PortfolioStatItem, 0),
+		ByLevel:       make([]PortfolioStatItem, 0),
+		ByPointType:   make([]PortfolioStatItem, 0),
+		ByDutyUnit:    make([]PortfolioStatItem, 0),
+	}
+	for i := range stats {
+		s := &stats[i]
+		item := PortfolioStatItem{
+			Count:           s.Count,
+			TotalInvestment: s.TotalInvestment,
+			YearInvested:    s.YearInvested,
+		}
+		if s.TotalInvestment > 0 {
+			item.CompletionRate = s.YearInvested / s.TotalInvestment
+		}
+		if s.GroupKey != nil {
+			item.Key = *s.GroupKey
+		}
+		switch s.Dimension {
+		case DimensionAll:
+			resp.Summary = item
+		case DimensionType:
+			item.Name = groupName(ProjectNatureNames, item.Key)
+			resp.ByType = append(resp.ByType, item)
+		case DimensionProjectType:
+			item.Name = groupName(ProjectTypeNames, item.Key)
+			resp.ByProjectType = append(resp.ByProjectType, item)
+		case DimensionLevel:
+			item.Name = groupName(LevelNames, item.Key)
+			resp.ByLevel = append(resp.ByLevel, item)
+		case DimensionPointType:
+			item.Name = groupName(PointTypeNames, item.Key)
+			resp.ByPointType = append(resp.ByPointType, item)
+		case DimensionDutyUnit:
+			item.Name = item.Key
+			if item.Name == "" {
+				item.Name = "未填写"
+			}
+			resp.ByDutyUnit = append(resp.ByDutyUnit, item)
+		}
+	}
+	return resp
+}
+
+func groupName(names map[int]string, key string) string {
+	if key == "" {
+		return "未填写"
+	}
+	n, err := strconv.Atoi(key)
+	if err != nil {
+		return key
+	}
+	return Label(names, &n)
+}
+
+type ImplementAnalysisFilter struct {
+	ImplementGovCountFilter
+	// 统计方式 0:按月, 1:按年
+	QueryType int `json:"query_type"`
+	// 统计开始时间(闭区间, 必传) eg month:2022-01-01 00:00:00 year:2020-01-01 00:00:00
+	Begin string `json:"begin"`
+	// 统计结束时间(开区间, 必传) eg month:2023-01-01 00:00:00 year:2023-01-01 00:00:00
+	End string `json:"end"`
+	// 是否同比(与上年同期对比)
+	Compare bool `json:"compare"`
+}
+
+type ImplementAnalysisData struct {
+	// 时间 eg: 2022-03 / 2022
+	Bucket string `json:"bucket"`
+	// 新开工项目数
+	Started int64 `json:"started"`
+	// 竣工项目数
+	Finished int64 `json:"finished"`
+	// 完成投资额(万)
+	Invested float64 `json:"invested"`
+}
+
+type ImplementAnalysisResp struct {
+	ImplementAnalysisData
+	// 上年同期, 仅同比时返回
+	LastYear *ImplementAnalysisData `json:"last_year,omitempty"`
+	// 新开工项目数同比增长率, 上年同期为0时为空
+	StartedYoY *float64 `json:"started_yoy,omitempty"`
+	// 竣工项目数同比增长率
+	FinishedYoY *float64 `json:"finished_yoy,omitempty"`
+	// 完成投资额同比增长率
+	InvestedYoY *float64 `json:"invested_yoy,omitempty"`
+}
+
+func NewImplementAnalysisData(bucket string, r *models.ImplementAnalysis) ImplementAnalysisData {
+	data := ImplementAnalysisData{Bucket: bucket}
+	if r != nil {
+		data.Started = r.Started
+		data.Finished = r.Finished
+		data.Invested = r.Invested
+	}
+	return data
+}
+
+// SetLastYear 设置上年同期数据并计算同比增长率
+func (r *ImplementAnalysisResp) SetLastYear(last ImplementAnalysisData) {
+	r.LastYear = &last
+	r.StartedYoY = growthRate(float64(r.Started), float64(last.Started))
+	r.FinishedYoY = growthRate(float64(r.Finished), float64(last.Finished))
+	r.InvestedYoY = growthRate(r.Invested, last.Invested)
+}
+
+func growthRate(cur, last float64) *float64 {
+	if last == 0 {
+		return nil
+	}
+	rate := (cur - last) / last
+	return &rate
+}
@@ -0,0 +1,94 @@
+package vo
+
+import (
+	"lpms/app/models"
+	"time"
+)
+
+type ProgressPhotoResp struct {
+	// id
+	ID int64 `json:"id"`
+	// 项目ID
+	ProjectID int64 `json:"project_id"`
+	// 月度进度ID
+	ProgressID int64 `json:"progress_id"`
+	// 填报年份
+	Year int `json:"year"`
+	// 填报月份
+	Month int `json:"month"`
+	// 原图文件ID
+	ObjectID string `json:"object_id"`
+	// 缩略图文件ID
+	ThumbnailID string `json:"thumbnail_id"`
+	// 文件名称
+	Filename string `json:"filename"`
+	// 拍摄时间(照片EXIF信息, 无则为空)
+	CaptureAt *time.Time `json:"capture_at"`
+	// 纬度(照片EXIF信息, 无则为空)
+	Latitude *float64 `json:"latitude"`
+	// 经度(照片EXIF信息, 无则为空)
+	Longitude *float64 `json:"longitude"`
+	// 照片说明
+	Description string `json:"description"`
+	// 上传人
+	CreateBy string `json:"create_by"`
+	// 上传时间
+	CreateAt time.Time `json:"create_at"`
+}
+
+func NewProgressPhotoResponse(r *models.ProgressPhoto) ProgressPhotoResp {
+	return ProgressPhotoResp{
+		ID:          r.ID,
+		ProjectID:   r.ProjectID,
+		ProgressID:  r.ProgressID,
+		Year:        r.Year,
+		Month:       r.Month,
+		ObjectID:    r.ObjectID,
+		ThumbnailID: r.ThumbnailID,
+		Filename:    r.Filename,
+		CaptureAt:   r.CaptureAt,
+		Latitude:    r.Latitude,
+		Longitude:   r.Longitude,
+		Description: r.Description,
+		CreateBy:    r.CreateBy,
+		CreateAt:    r.CreateAt,
+	}
+}
+
+func NewProgressPhotoResponses(rs []models.ProgressPhoto) []ProgressPhotoResp {
+	resp := make([]ProgressPhotoResp, 0, len(rs))
+	for i := range rs {
+		resp = append(resp, NewProgressPhotoResponse(&rs[i]))
+	}
+	return resp
+}
+
+type ProgressPhotoMonth struct {
+	// 年份
+	Year int `json:"year"`
+	// 月份
+	Month int `json:"month"`
+	// 照片数量
+	Count int `json:"count"`
+	// 照片列表, 按拍摄时间排序
+	Photos []ProgressPhotoResp `json:"photos"`
+}
+
+// NewProgressPhotoTimeline 按月份分组, 入参需已按期间排序
+func NewProgressPhotoTimeline(rs []models.ProgressPhoto) []ProgressPhotoMonth {
+	resp := make([]ProgressPhotoMonth, 0)
+	for i := range rs {
+		n := len(resp)
+		if n == 0 || resp[n-1].Year != rs[i].Year || resp[n-1].Month != rs[i].Month {
+			resp = append(resp, ProgressPhotoMonth{
+				Year:   rs[i].Year,
+				Month:  rs[i].Month,
+				Photos: make([]ProgressPhotoResp, 0),
+			})
+			n++
+		}
+		resp[n-1].Photos = append(resp[n-1].Photos, NewProgressPhotoResponse(&rs[i]))
+		resp[n-1].Count++
+	}
+	return resp
+}
@@ -0,0 +1,115 @@
+package vo
+
+import (
+	"lpms/app/models"
+	"time"
+)
+
+type ProgressReminderFilterParam struct {
+	// 年份 ***注意:（所有参数，有就传，无则不传）***
+	Year *int `json:"year"`
+	// 月份
+	Month *int `json:"month"`
+	// 状态 0:未补报,1:已补报
+	Status *int `json:"status"`
+	// 责任单位
+	DutyUnit string `json:"duty_unit"`
+	// 所属街镇
+	Township string `json:"township"`
+}
+
+type ProgressReminderResp struct {
+	// id
+	ID int64 `json:"id"`
+	// 项目ID
+	ProjectID int64 `json:"project_id"`
+	// 项目名称
+	ProjectName string `json:"project_name"`
+	// 项目编码
+	ProjectCode string `json:"project_code"`
+	// 年份
+	Year int `json:"year"`
+	// 月份
+	Month int `json:"month"`
+	// 责任单位
+	DutyUnit string `json:"duty_unit"`
+	// 所属街镇
+	Township string `json:"township"`
+	// 提醒对象(项目填报人)
+	Receiver string `json:"receiver"`
+	// 状态 0:未补报,1:已补报
+	Status int `json:"status"`
+	// 补报时间
+	ResolvedAt *time.Time `json:"resolved_at"`
+	// 提醒生成时间
+	CreateAt time.Time `json:"create_at"`
+}
+
+func NewProgressReminderResponses(rs []models.ProgressReminder) []ProgressReminderResp {
+	resp := make([]ProgressReminderResp, 0, len(rs))
+	for i := range rs {
+		resp = append(resp, ProgressReminderResp{
+			ID:          rs[i].ID,
+			ProjectID:   rs[i].ProjectID,
+			ProjectName: rs[i].ProjectName,
+			ProjectCode: rs[i].ProjectCode,
+			Year:        rs[i].Year,
+			Month:       rs[i].Month,
+			DutyUnit:    rs[i].DutyUnit,
+			Township:    rs[i].Township,
+			Receiver:    rs[i].Receiver,
+			Status:      rs[i].Status,
+			ResolvedAt:  rs[i].ResolvedAt,
+			CreateAt:    rs[i].CreateAt,
+		})
+	}
+	return resp
+}
+
+type ComplianceRate struct {
+	// 责任单位/街镇, 未填写的归为"未填写"
+	Name string `json:"name"`
+	// 应填报项目数
+	Total int64 `json:"total"`
+	// 已提交项目数
+	Submitted int64 `json:"submitted"`
+	// 未提交项目数
+	Unsubmitted int64 `json:"unsubmitted"`
+	// 填报率 已提交/应填报, 无应填报项目时为0
+	Rate float64 `json:"rate"`
+}
+
+func NewComplianceRate(name string, total, submitted int64) ComplianceRate {
+	rate := float64(0)
+	if total != 0 {
+		rate = float64(submitted) / float64(total)
+	}
+	return ComplianceRate{
+		Name:        name,
+		Total:       total,
+		Submitted:   submitted,
+		Unsubmitted: total - submitted,
+		Rate:        rate,
+	}
+}
+
+func NewComplianceRates(rs []models.ComplianceStat) []ComplianceRate {
+	resp := make([]ComplianceRate, 0, len(rs))
+	for i := range rs {
+		resp = append(resp, NewComplianceRate(rs[i].Name, rs[i].Total, rs[i].Submitted))
+	}
+	return resp
+}
+
+type ComplianceResp struct {
+	// 年份
+	Year int `json:"year"`
+	// 月份
+	Month int `json:"month"`
+	// 全区汇总
+	Summary ComplianceRate `json:"summary"`
+	// 按责任单位
+	DutyUnits []ComplianceRate `json:"duty_units"`
+	// 按街镇
+	Townships []ComplianceRate `json:"townships"`
+}
@@ -0,0 +1,36 @@
+package vo
+
+type FieldError struct {
+	// 字段
+	Field string `json:"field"`
+	// 错误原因
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+type ImportRowError struct {
+	// 表格行号(含表头, 从1开始)
+	Row int `json:"row"`
+	// 列名
+	Column string `json:"column"`
+	// 错误原因
+	Message string `json:"message"`
+}
+
+type ImportResp struct {
+	// 是否已确认导入; false 为试导入, 仅校验不入库
+	Confirmed bool `json:"confirmed"`
+	// 数据行数(不含表头及空行)
+	Total int `json:"total"`
+	// 校验通过行数
+	Valid int `json:"valid"`
+	// 校验失败行数
+	Invalid int `json:"invalid"`
+	// 实际导入行数, 试导入时为0
+	Imported int `json:"imported"`
+	// 逐行错误
+	Errors []ImportRowError `json:"errors"`
+}
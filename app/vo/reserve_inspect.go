@@ -1,16 +1,21 @@
 package vo
 
 type ReserveInspectParam struct {
+	EmptyFilter
 	//项目名称
-	Name string `json:"name"`
+	Name string `json:"name" filter:"name"`
 	// 项目级别
-	Level *int `json:"level"`
+	Level *int `json:"level" filter:"level"`
+	// 项目级别(多选)
+	Levels []int `json:"levels" filter:"level,in"`
 	// 项目类型
-	ProjectType *int `json:"project_type"`
+	ProjectType *int `json:"project_type" filter:"project_type"`
+	// 项目类型(多选)
+	ProjectTypes []int `json:"project_types" filter:"project_type,in"`
 	// 建设主体 ***注意:（所有参数，有就传，无则不传）***
-	ConstructSubject string `json:"construct_subject"`
+	ConstructSubject string `json:"construct_subject" filter:"construct_subject"`
 	// 计划开始时间
-	PlanBegin string `json:"plan_begin"`
+	PlanBegin string `json:"plan_begin" filter:"create_at,gte"`
 	// 计划结束时间
-	PlanEnd string `json:"plan_end"`
+	PlanEnd string `json:"plan_end" filter:"create_at,lte"`
 }
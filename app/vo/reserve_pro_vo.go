@@ -12,8 +12,12 @@
 package vo
 
 import (
+	"fmt"
 	"lpms/app/models"
+	"regexp"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/goccy/go-json"
 )
@@ -72,7 +76,7 @@ type ReserveReq struct {
 	NoConformUsePlan *float64 `json:"no_conform_use_plan"`
 	// 选址红线 0:有拆迁,1:无拆迁
 	SiteRed *int `json:"site_red"`
-	// 无拆迁照片
+	// 无拆迁照片, 已由项目附件(现场照片)替代, 写入时同步为该类别附件
 	SitePhoto string `json:"site_photo"`
 	// 需征地面积
 	NeedCollect *float64 `json:"need_collect"`
@@ -80,7 +84,7 @@ type ReserveReq struct {
 	NeedPeopleMove *int `json:"need_people_move"`
 	// 企/事业单位(家)
 	CompanyBusiness *int `json:"company_business"`
-	// CAD文件ID(上传文件接口返回的ID)
+	// CAD文件ID(上传文件接口返回的ID), 已由项目附件(CAD图纸)替代, 写入时同步为该类别附件
 	UploadCadID string `json:"upload_cad_id"`
 	// 总投资
 	TotalInvestment *float64 `json:"total_investment"`
@@ -98,6 +102,8 @@ type ReserveReq struct {
 	Phone string `json:"phone"`
 	// 状态: 暂存->0; 提交->1
 	Status int `json:"status"`
+	// 项目位置及红线范围
+	ProjectGeo
 }
 
 func (r *ReserveReq) ToModel(openID string) *models.ReservePro {
@@ -131,6 +137,8 @@ func (r *ReserveReq) ToModel(openID string) *models.ReservePro {
 		Contract:                r.Contract,
 		Phone:                   r.Phone,
 		Status:                  r.Status,
+		Location:                r.LocationJSON(),
+		Boundary:                r.BoundaryJSON(),
 		Base: models.Base{
 			UpdateBy: openID,
 			CreateBy: openID,
@@ -138,6 +146,62 @@ func (r *ReserveReq) ToModel(openID string) *models.ReservePro {
 	}
 }
 
+var phonePattern = regexp.MustCompile(`^(1\d{10}|0\d{2,3}-?\d{7,8})$`)
+
+// Validate 校验储备库项目字段, 返回全部不合规项(字段名->原因)
+func (r *ReserveReq) Validate() []FieldError {
+	errs := make([]FieldError, 0)
+	check := func(ok bool, field, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+		}
+	}
+	maxLen := func(field, v string, n int) {
+		check(utf8.RuneCountInString(v) <= n, field, "长度不能超过%d个字", n)
+	}
+	enum := func(field string, names map[int]string, v *int) {
+		if v != nil {
+			_, ok := names[*v]
+			check(ok, field, "取值无效: %d", *v)
+		}
+	}
+	nonNegative := func(field string, v *float64) {
+		check(v == nil || *v >= 0, field, "不能为负数")
+	}
+
+	check(strings.TrimSpace(r.Name) != "", "name", "不能为空")
+	maxLen("name", r.Name, 60)
+	maxLen("construct_subject", r.ConstructSubject, 60)
+	maxLen("construct_site", r.ConstructSite, 200)
+	maxLen("contract", r.Contract, 20)
+	check(r.Phone == "" || phonePattern.MatchString(r.Phone), "phone", "格式错误: %s", r.Phone)
+	enum("level", LevelNames, r.Level)
+	enum("project_type", ProjectTypeNames, r.ProjectType)
+	enum("point_type", PointTypeNames, r.PointType)
+	enum("implement_type", ImplementTypeNames, r.ImplementType)
+	enum("enter_db_type", EnterDBTypeNames, r.EnterDBType)
+	enum("site_red", SiteRedNames, r.SiteRed)
+	check(r.Period == nil || *r.Period > 0, "period", "必须大于0")
+	check(r.NeedPeopleMove == nil || *r.NeedPeopleMove >= 0, "need_people_move", "不能为负数")
+	check(r.CompanyBusiness == nil || *r.CompanyBusiness >= 0, "company_business", "不能为负数")
+	nonNegative("total", r.Total)
+	nonNegative("add", r.Add)
+	nonNegative("no_conform_use_plan", r.NoConformUsePlan)
+	nonNegative("need_collect", r.NeedCollect)
+	nonNegative("total_investment", r.TotalInvestment)
+	nonNegative("project_consumption", r.ProjectComsumption)
+	nonNegative("move_land_comsumption", r.MoveLandComsumption)
+	if r.TotalInvestment != nil && r.ProjectComsumption != nil && r.MoveLandComsumption != nil {
+		check(*r.ProjectComsumption+*r.MoveLandComsumption <= *r.TotalInvestment+1e-6,
+			"total_investment", "工程费用与征迁/土地费用之和超过总投资")
+	}
+	if r.InvestmentDetail != "" {
+		_, err := ParseInvestmentDetail([]byte(r.InvestmentDetail))
+		check(err == nil, "investment_detail", "格式错误")
+	}
+	return append(errs, r.ProjectGeo.Validate()...)
+}
+
 type ReserveResp struct {
 	// id
 	ID int64 `json:"id"`
@@ -175,7 +239,7 @@ type ReserveResp struct {
 	NoConformUsePlan *float64 `json:"no_conform_use_plan"`
 	// 选址红线 0:有拆迁,1:无拆迁
 	SiteRed *int `json:"site_red"`
-	// 无拆迁照片
+	// 无拆迁照片, 已由项目附件(现场照片)替代, 仅保留兼容
 	SitePhoto string `json:"site_photo"`
 	// 需征地面积
 	NeedCollect *float64 `json:"need_collect"`
@@ -183,7 +247,7 @@ type ReserveResp struct {
 	NeedPeopleMove *int `json:"need_people_move"`
 	// 企/事业单位(家)
 	CompanyBusiness *int `json:"company_business"`
-	// CAD文件ID(上传文件接口返回的ID)
+	// CAD文件ID(上传文件接口返回的ID), 已由项目附件(CAD图纸)替代, 仅保留兼容
 	UploadCadID string `json:"upload_cad_id"`
 	// 总投资
 	TotalInvestment *float64 `json:"total_investment"`
@@ -193,6 +257,8 @@ type ReserveResp struct {
 	MoveLandComsumption *float64 `json:"move_land_comsumption"`
 	// 资金详情
 	InvestmentDetail string `json:"investment_detail"`
+	// 资金详情(解析后), 按资金来源及年份
+	InvestmentDetails []InvestmentDetail `json:"investment_details"`
 	// 前期工作联系人
 	Contract string `json:"contract"`
 	// 联系人手机号
@@ -201,6 +267,10 @@ type ReserveResp struct {
 	Status int `json:"status"`
 	// 创建时间
 	CreateAt string `json:"create_at"`
+	// 项目位置(GeoJSON Point)
+	Location json.RawMessage `json:"location"`
+	// 红线范围(GeoJSON Polygon)
+	Boundary json.RawMessage `json:"boundary"`
 }
 
 type InvestmentDetail struct {
@@ -259,29 +329,41 @@ func NewReserveProResponse(r *models.ReservePro) (*ReserveResp, error) {
 		ProjectComsumption:      r.ProjectComsumption,
 		MoveLandComsumption:     r.MoveLandComsumption,
 		InvestmentDetail:        string(r.InvestmentDetail),
+		InvestmentDetails:       parseInvestmentDetails(r.InvestmentDetail),
 		Contract:                r.Contract,
 		Phone:                   r.Phone,
 		Status:                  r.Status,
+		Location:                r.Location,
+		Boundary:                r.Boundary,
 	}, nil
 }
 
 type ReserveFilterParam struct {
+	EmptyFilter
 	//项目名称
-	Name string `json:"name"`
+	Name string `json:"name" filter:"name"`
 	// 项目级别
-	Level *int `json:"level"`
+	Level *int `json:"level" filter:"level"`
+	// 项目级别(多选)
+	Levels []int `json:"levels" filter:"level,in"`
 	// 项目类型
-	ProjectType *int `json:"project_type"`
+	ProjectType *int `json:"project_type" filter:"project_type"`
+	// 项目类型(多选)
+	ProjectTypes []int `json:"project_types" filter:"project_type,in"`
 	// 建设主体 ***注意:（所有参数，有就传，无则不传）***
-	ConstructSubject string `json:"construct_subject"`
+	ConstructSubject string `json:"construct_subject" filter:"construct_subject"`
 	// 标签 0:省重点实施项目,1:省重点预备项目,2:省重大产业项目;3:省4+1项目;4:省6千亿项目;5:市重点实施项目;6:市重点预备项目;7:无重点类型;8: 152工程
-	PointType *int `json:"point_type"`
+	PointType *int `json:"point_type" filter:"point_type"`
+	// 标签(多选)
+	PointTypes []int `json:"point_types" filter:"point_type,in"`
 	// 计划开始时间
-	PlanBegin string `json:"plan_begin"`
+	PlanBegin string `json:"plan_begin" filter:"create_at,gte"`
 	// 计划结束时间
-	PlanEnd string `json:"plan_end"`
+	PlanEnd string `json:"plan_end" filter:"create_at,lte"`
 	// 状态
-	Status *int `json:"status"`
+	Status *int `json:"status" filter:"status"`
+	// 状态(多选)
+	Statuses []int `json:"statuses" filter:"status,in"`
 }
 
 type ListReserveProResp struct {
@@ -336,7 +418,7 @@ type ReserveUpdateReq struct {
 	NoConformUsePlan *float64 `json:"no_conform_use_plan"`
 	// 选址红线 0:有拆迁,1:无拆迁
 	SiteRed *int `json:"site_red"`
-	// 无拆迁照片
+	// 无拆迁照片, 已由项目附件(现场照片)替代, 写入时同步为该类别附件
 	SitePhoto string `json:"site_photo"`
 	// 需征地面积
 	NeedCollect *float64 `json:"need_collect"`
@@ -344,7 +426,7 @@ type ReserveUpdateReq struct {
 	NeedPeopleMove *int `json:"need_people_move"`
 	// 企/事业单位(家)
 	CompanyBusiness *int `json:"company_business"`
-	// CAD文件ID(上传文件接口返回的ID)
+	// CAD文件ID(上传文件接口返回的ID), 已由项目附件(CAD图纸)替代, 写入时同步为该类别附件
 	UploadCadID string `json:"upload_cad_id"`
 	// 总投资
 	TotalInvestment *float64 `json:"total_investment"`
@@ -360,6 +442,44 @@ type ReserveUpdateReq struct {
 	Contract string `json:"contract"`
 	// 联系人手机号
 	Phone string `json:"phone"`
+	// 项目位置及红线范围
+	ProjectGeo
+}
+
+// Validate 校验规则与新建及导入一致
+func (r *ReserveUpdateReq) Validate() []FieldError {
+	req := ReserveReq{
+		Level:                   r.Level,
+		Name:                    r.Name,
+		ConstructSubject:        r.ConstructSubject,
+		ConstructSite:           r.ConstructSite,
+		ProjectType:             r.ProjectType,
+		PlanBegin:               r.PlanBegin,
+		Period:                  r.Period,
+		PointType:               r.PointType,
+		ImplementType:           r.ImplementType,
+		ConstructContentScope:   r.ConstructContentScope,
+		ConstructBasisNecessity: r.ConstructBasisNecessity,
+		EnterDBType:             r.EnterDBType,
+		IsLandUse:               r.IsLandUse,
+		Total:                   r.Total,
+		Add:                     r.Add,
+		NoConformUsePlan:        r.NoConformUsePlan,
+		SiteRed:                 r.SiteRed,
+		SitePhoto:               r.SitePhoto,
+		NeedCollect:             r.NeedCollect,
+		NeedPeopleMove:          r.NeedPeopleMove,
+		CompanyBusiness:         r.CompanyBusiness,
+		UploadCadID:             r.UploadCadID,
+		TotalInvestment:         r.TotalInvestment,
+		ProjectComsumption:      r.ProjectComsumption,
+		MoveLandComsumption:     r.MoveLandComsumption,
+		InvestmentDetail:        r.InvestmentDetail,
+		Contract:                r.Contract,
+		Phone:                   r.Phone,
+		ProjectGeo:              r.ProjectGeo,
+	}
+	return req.Validate()
 }
 
 func (r *ReserveUpdateReq) ToMap(openID string) map[string]interface{} {
@@ -392,6 +512,8 @@ func (r *ReserveUpdateReq) ToMap(openID string) map[string]interface{} {
 		"investment_detail":         r.InvestmentDetail,
 		"contract":                  r.Contract,
 		"phone":                     r.Phone,
+		"location":                  r.LocationJSON(),
+		"boundary":                  r.BoundaryJSON(),
 		"update_by":                 openID,
 	}
 }
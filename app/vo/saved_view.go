@@ -0,0 +1,163 @@
+package vo
+
+import (
+	"bytes"
+	"errors"
+	"lpms/app/models"
+	"lpms/constant"
+	"time"
+	"unicode/utf8"
+
+	"github.com/goccy/go-json"
+)
+
+// SavedViewListTypes 可保存视图的列表
+var SavedViewListTypes = map[string]string{
+	constant.ViewReserve:           "储备库项目列表",
+	constant.ViewEarlyPlan:         "前期计划审核列表",
+	constant.ViewOutStorageInspect: "出库审核列表",
+	constant.ViewImplementGov:      "实施库政府投资项目列表",
+	constant.ViewImplementIndustry: "实施库产业项目列表",
+}
+
+// NewSavedViewFilter 列表类型对应的筛选条件
+func NewSavedViewFilter(listType string) interface{} {
+	switch listType {
+	case constant.ViewReserve:
+		return &ReserveFilterParam{}
+	case constant.ViewEarlyPlan, constant.ViewOutStorageInspect:
+		return &ReserveInspectParam{}
+	case constant.ViewImplementGov:
+		return &ImplementGovFilterParam{}
+	case constant.ViewImplementIndustry:
+		return &ImpleIndustryFilterParam{}
+	}
+	return nil
+}
+
+// DecodeSavedViewFilter 按列表类型解析筛选条件, 不允许未知字段
+func DecodeSavedViewFilter(listType string, raw json.RawMessage) (interface{}, error) {
+	params := NewSavedViewFilter(listType)
+	if params == nil {
+		return nil, errors.New("invalid list_type")
+	}
+	if len(raw) == 0 {
+		return params, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+type SavedViewReq struct {
+	// 列表类型 reserve:储备库项目列表, early_plan:前期计划审核列表, out_storage_inspect:出库审核列表, implement_gov:实施库政府投资项目列表, implement_industry:实施库产业项目列表
+	ListType string `json:"list_type"`
+	// 视图名称
+	Name string `json:"name"`
+	// 筛选条件, 与对应列表接口的请求体一致
+	Filter json.RawMessage `json:"filter"`
+	// 排序, 与列表接口 sort 参数一致 eg: total_investment:desc,name
+	Sort string `json:"sort"`
+	// 是否共享给本单位用户
+	Shared bool `json:"shared"`
+	// 是否设为该列表的默认视图
+	IsDefault bool `json:"is_default"`
+}
+
+// Validate 校验名称、列表类型、筛选条件及排序格式
+func (r *SavedViewReq) Validate() error {
+	if r.Name == "" || utf8.RuneCountInString(r.Name) > 60 {
+		return errors.New("视图名称不能为空且不超过60个字符")
+	}
+	if _, ok := SavedViewListTypes[r.ListType]; !ok {
+		return errors.New("列表类型无效")
+	}
+	if _, err := DecodeSavedViewFilter(r.ListType, r.Filter); err != nil {
+		return errors.New("筛选条件无效: " + err.Error())
+	}
+	if utf8.RuneCountInString(r.Sort) > 200 {
+		return errors.New("排序不超过200个字符")
+	}
+	if _, err := ParseSort(r.Sort); err != nil {
+		return errors.New("排序无效: " + err.Error())
+	}
+	return nil
+}
+
+func (r *SavedViewReq) filter() json.RawMessage {
+	if len(r.Filter) == 0 || string(r.Filter) == "null" {
+		return json.RawMessage("{}")
+	}
+	return r.Filter
+}
+
+func (r *SavedViewReq) ToModel(openID, unit string) *models.SavedView {
+	return &models.SavedView{
+		ListType:  r.ListType,
+		Name:      r.Name,
+		Filter:    r.filter(),
+		Sort:      r.Sort,
+		Shared:    r.Shared,
+		Unit:      unit,
+		IsDefault: r.IsDefault,
+		Base: models.Base{
+			CreateBy: openID,
+			UpdateBy: openID,
+		},
+	}
+}
+
+// ToMap 列表类型不可修改
+func (r *SavedViewReq) ToMap(openID, unit string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":       r.Name,
+		"filter":     r.filter(),
+		"sort":       r.Sort,
+		"shared":     r.Shared,
+		"unit":       unit,
+		"is_default": r.IsDefault,
+		"update_by":  openID,
+	}
+}
+
+type SavedViewResp struct {
+	// id
+	ID int64 `json:"id"`
+	// 列表类型
+	ListType string `json:"list_type"`
+	// 视图名称
+	Name string `json:"name"`
+	// 筛选条件
+	Filter json.RawMessage `json:"filter"`
+	// 排序
+	Sort string `json:"sort"`
+	// 是否共享给本单位用户
+	Shared bool `json:"shared"`
+	// 是否为当前用户该列表的默认视图
+	IsDefault bool `json:"is_default"`
+	// 创建者
+	Owner string `json:"owner"`
+	// 是否为当前用户创建, 仅创建者可修改、删除及设为默认
+	IsOwner bool `json:"is_owner"`
+	// 最后更新时间
+	UpdateAt time.Time `json:"update_at"`
+}
+
+func NewSavedViewResponse(v *models.SavedView, user string) SavedViewResp {
+	isOwner := v.CreateBy == user
+	return SavedViewResp{
+		ID:        v.ID,
+		ListType:  v.ListType,
+		Name:      v.Name,
+		Filter:    v.Filter,
+		Sort:      v.Sort,
+		Shared:    v.Shared,
+		IsDefault: isOwner && v.IsDefault,
+		Owner:     v.CreateBy,
+		IsOwner:   isOwner,
+		UpdateAt:  v.UpdateAt,
+	}
+}
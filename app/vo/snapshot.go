@@ -0,0 +1,105 @@
+package vo
+
+import (
+	"lpms/app/models"
+	"time"
+)
+
+type SnapshotResp struct {
+	// id
+	ID int64 `json:"id"`
+	// 年份
+	Year int `json:"year"`
+	// 月份
+	Month int `json:"month"`
+	// 项目数(未开工/已开工/已竣工)
+	ProjectCount int64 `json:"project_count"`
+	// 总投资(万)
+	TotalInvestment float64 `json:"total_investment"`
+	// 当年累计完成投资(万), 一月至快照月份
+	YearInvested float64 `json:"year_invested"`
+	// 生成者, 定时任务生成时为 system
+	CreateBy string `json:"create_by"`
+	// 生成时间
+	CreateAt time.Time `json:"create_at"`
+}
+
+func NewSnapshotResponse(s *models.ReportSnapshot) SnapshotResp {
+	return SnapshotResp{
+		ID:              s.ID,
+		Year:            s.Year,
+		Month:           s.Month,
+		ProjectCount:    s.ProjectCount,
+		TotalInvestment: s.TotalInvestment,
+		YearInvested:    s.YearInvested,
+		CreateBy:        s.CreateBy,
+		CreateAt:        s.CreateAt,
+	}
+}
+
+func NewSnapshotResponses(s []models.ReportSnapshot) []SnapshotResp {
+	resp := make([]SnapshotResp, 0, len(s))
+	for i := range s {
+		resp = append(resp, NewSnapshotResponse(&s[i]))
+	}
+	return resp
+}
+
+type SnapshotProjectBrief struct {
+	// 来源 1:政府投资项目, 2:产业项目
+	Source int `json:"source"`
+	// 项目ID
+	ProjectID int64 `json:"project_id"`
+	// 项目名称
+	Name string `json:"name"`
+	// 项目编码
+	ProjectCode string `json:"project_code"`
+	// 责任单位
+	DutyUnit string `json:"duty_unit"`
+}
+
+func NewSnapshotProjectBrief(p *models.SnapshotProject) SnapshotProjectBrief {
+	return SnapshotProjectBrief{
+		Source:      p.Source,
+		ProjectID:   p.ProjectID,
+		Name:        p.Name,
+		ProjectCode: p.ProjectCode,
+		DutyUnit:    p.DutyUnit,
+	}
+}
+
+type FieldChange struct {
+	// 字段
+	Field string `json:"field"`
+	// 字段名称
+	Name string `json:"name"`
+	// 变更前
+	From interface{} `json:"from"`
+	// 变更后
+	To interface{} `json:"to"`
+}
+
+type SnapshotProjectChange struct {
+	SnapshotProjectBrief
+	// 变更字段
+	Changes []FieldChange `json:"changes"`
+}
+
+type SnapshotDiffResp struct {
+	// 对比基准快照
+	From SnapshotResp `json:"from"`
+	// 对比目标快照
+	To SnapshotResp `json:"to"`
+	// 项目数变化
+	ProjectCountDelta int64 `json:"project_count_delta"`
+	// 总投资变化(万)
+	TotalInvestmentDelta float64 `json:"total_investment_delta"`
+	// 当年累计完成投资变化(万)
+	YearInvestedDelta float64 `json:"year_invested_delta"`
+	// 新增项目
+	Added []SnapshotProjectBrief `json:"added"`
+	// 移除项目
+	Removed []SnapshotProjectBrief `json:"removed"`
+	// 数据有变化的项目
+	Changed []SnapshotProjectChange `json:"changed"`
+}
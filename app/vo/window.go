@@ -1,6 +1,7 @@
 package vo
 
 import (
+	"errors"
 	"lpms/app/models"
 
 	"github.com/goccy/go-json"
@@ -9,7 +10,7 @@ import (
 type WindowsReq struct {
 	// 储备库填报
 	ReserveSetting string `json:"reserve_setting"`
-	// 项目进度填报
+	// 项目进度填报 eg: {"start_day": 1, "end_day": 25}, 见 ProgressWindow
 	ProgressSetting string `json:"progress_setting"`
 	// 项目计划填报
 	ProPlanSetting string `json:"pro_plan_setting"`
@@ -58,3 +59,32 @@ func (w *WindowsReq) ToMap(openID string) map[string]interface{} {
 		"update_by":        openID,
 	}
 }
+
+// ProgressWindow 项目进度填报窗口期, 每月 start_day 日至 end_day 日填报当月进度
+type ProgressWindow struct {
+	// 填报开始日
+	StartDay int `json:"start_day"`
+	// 填报截止日, 过后生成当月逾期提醒
+	EndDay int `json:"end_day"`
+}
+
+// ParseProgressWindow 解析项目进度填报设置
+func ParseProgressWindow(setting []byte) (*ProgressWindow, error) {
+	w := &ProgressWindow{}
+	if err := json.Unmarshal(setting, w); err != nil {
+		return nil, errors.New("项目进度填报设置格式错误")
+	}
+	if w.EndDay < 1 || w.EndDay > 31 || w.StartDay < 0 || w.StartDay > w.EndDay {
+		return nil, errors.New("项目进度填报截止日须为1-31且不早于开始日")
+	}
+	return w, nil
+}
+
+// Validate 校验项目进度填报设置, 未设置时不校验
+func (w *WindowsReq) Validate() error {
+	if w.ProgressSetting == "" {
+		return nil
+	}
+	_, err := ParseProgressWindow([]byte(w.ProgressSetting))
+	return err
+}
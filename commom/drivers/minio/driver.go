@@ -1,6 +1,8 @@
 package minio
 
 import (
+	"fmt"
+	"log"
 	"lpms/config"
 	"lpms/minio_sdk"
 	"sync"
@@ -11,15 +13,24 @@ var (
 	once     sync.Once
 )
 
+// GetDriver 按 storage.type 创建文件存储, fs 为本地文件系统, minio 或未配置时为 MinIO; 其他类型及创建失败时返回 nil
 func GetDriver() minio_sdk.Client {
-	var err error
 	once.Do(func() {
+		var err error
 		cfg := config.GetConfig()
-		instance, err = minio_sdk.New(
-			cfg.MinIO.ADDR, cfg.MinIO.AccessKeyID, cfg.MinIO.SecretAccessKey, cfg.MinIO.SSL)
+		switch cfg.Storage.Type {
+		case "", "minio":
+			instance, err = minio_sdk.New(
+				cfg.MinIO.ADDR, cfg.MinIO.AccessKeyID, cfg.MinIO.SecretAccessKey, cfg.MinIO.SSL)
+		case "fs":
+			instance, err = minio_sdk.NewFS(cfg.Storage.Path)
+		default:
+			err = fmt.Errorf("unsupported storage type %q, expect minio or fs", cfg.Storage.Type)
+		}
+		if err != nil {
+			log.Println("storage init error: ", err.Error())
+			instance = nil
+		}
 	})
-	if err != nil {
-		return nil
-	}
 	return instance
 }
@@ -0,0 +1,36 @@
+package scanner
+
+import (
+	"log"
+	"lpms/commom/scan"
+	"lpms/config"
+	"sync"
+	"time"
+)
+
+var (
+	instance scan.Scanner
+	once     sync.Once
+)
+
+// GetDriver 按 upload.scanner.type 创建文件安全扫描, 仅 noop 不扫描; 其他未知类型启动失败, 避免配置错误时静默关闭扫描
+func GetDriver() scan.Scanner {
+	once.Do(func() {
+		cfg := config.GetConfig().Upload.Scanner
+		switch cfg.Type {
+		case "clamd":
+			s, err := scan.NewClamd(cfg.Addr, time.Duration(cfg.Timeout)*time.Second)
+			if err != nil {
+				log.Fatal("init clamd scanner error:", err)
+			}
+			instance = s
+		case "fake":
+			instance = scan.NewFake()
+		case "noop":
+			instance = scan.NewNoop()
+		default:
+			log.Fatalf("unsupported scanner type %q, expect clamd, noop or fake", cfg.Type)
+		}
+	})
+	return instance
+}
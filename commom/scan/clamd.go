@@ -0,0 +1,98 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamdChunkSize INSTREAM 每次发送的数据块大小
+const clamdChunkSize = 64 << 10
+
+type clamd struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamd clamd 服务扫描, addr 格式为 unix:/path/to/clamd.sock 或 tcp:host:port
+func NewClamd(addr string, timeout time.Duration) (Scanner, error) {
+	i := strings.Index(addr, ":")
+	if i < 0 {
+		return nil, fmt.Errorf("invalid clamd address %q", addr)
+	}
+	network, address := addr[:i], addr[i+1:]
+	if network != "unix" && network != "tcp" {
+		return nil, fmt.Errorf("unsupported clamd network %q", network)
+	}
+	return &clamd{network: network, address: address, timeout: timeout}, nil
+}
+
+// Scan 以 INSTREAM 命令发送内容, 响应为 "stream: OK" 或 "stream: <名称> FOUND"
+func (c *clamd) Scan(r io.Reader) (*Result, error) {
+	conn, err := net.DialTimeout(c.network, c.address, c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if c.timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+			return nil, err
+		}
+	}
+	w := bufio.NewWriterSize(conn, clamdChunkSize+4)
+	if _, err := w.WriteString("zINSTREAM\x00"); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, clamdChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := w.Write(size); err != nil {
+				return nil, err
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				return nil, err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := w.Write(size); err != nil {
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		return nil, err
+	}
+	return parseClamdReply(string(bytes.TrimRight(reply, "\x00\n")))
+}
+
+func parseClamdReply(reply string) (*Result, error) {
+	reply = strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case reply == "OK":
+		return &Result{}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return &Result{Infected: true, Signature: strings.TrimSuffix(reply, " FOUND")}, nil
+	case reply == "":
+		return nil, errors.New("clamd: empty reply")
+	}
+	return nil, errors.New("clamd: " + reply)
+}
@@ -0,0 +1,42 @@
+package scan
+
+import (
+	"bytes"
+	"io"
+)
+
+// EICAR 杀毒软件通用测试文件内容
+const EICAR = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+type fake struct {
+	signatures [][]byte
+}
+
+// NewFake 测试用扫描, 内容包含任一特征时视为威胁, 未指定特征时识别 EICAR 测试文件
+func NewFake(signatures ...string) Scanner {
+	if len(signatures) == 0 {
+		signatures = []string{EICAR}
+	}
+	f := &fake{}
+	for _, s := range signatures {
+		f.signatures = append(f.signatures, []byte(s))
+	}
+	return f
+}
+
+func (f *fake) Scan(r io.Reader) (*Result, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, signature := range f.signatures {
+		if bytes.Contains(content, signature) {
+			name := "Fake-Signature"
+			if string(signature) == EICAR {
+				name = "Eicar-Test-Signature"
+			}
+			return &Result{Infected: true, Signature: name}, nil
+		}
+	}
+	return &Result{}, nil
+}
@@ -0,0 +1,27 @@
+package scan
+
+import "io"
+
+// Result 扫描结果
+type Result struct {
+	// 是否发现威胁
+	Infected bool
+	// 威胁名称
+	Signature string
+}
+
+// Scanner 文件安全扫描
+type Scanner interface {
+	Scan(r io.Reader) (*Result, error)
+}
+
+type noop struct{}
+
+// NewNoop 不扫描, 所有文件均视为安全
+func NewNoop() Scanner {
+	return noop{}
+}
+
+func (noop) Scan(r io.Reader) (*Result, error) {
+	return &Result{}, nil
+}
@@ -0,0 +1,267 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"strings"
+	"time"
+)
+
+const exifDateTimeFormat = "2006:01:02 15:04:05"
+
+// exif tag
+const (
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagDateTimeOriginal = 0x9003
+	tagGPSLatitudeRef   = 0x0001
+	tagGPSLatitude      = 0x0002
+	tagGPSLongitudeRef  = 0x0003
+	tagGPSLongitude     = 0x0004
+)
+
+var (
+	ErrNoExif        = errors.New("exif not found")
+	ErrImageTooLarge = errors.New("image dimensions too large")
+)
+
+// maxImagePixels 解码图片的像素上限, 避免按声明尺寸分配过大内存
+const maxImagePixels = 60_000_000
+
+// Exif 照片拍摄信息
+type Exif struct {
+	// 拍摄时间
+	CaptureAt *time.Time
+	// 纬度
+	Latitude *float64
+	// 经度
+	Longitude *float64
+}
+
+type exifReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+type exifEntry struct {
+	typ    uint16
+	count  uint32
+	offset uint32
+	// 值字段原始4字节, 长度不超过4字节的值直接存放于此
+	raw []byte
+}
+
+// ParseExif 解析JPEG中的拍摄时间与GPS坐标, 非JPEG或无EXIF返回ErrNoExif
+func ParseExif(buf []byte) (*Exif, error) {
+	tiff, err := findExif(buf)
+	if err != nil {
+		return nil, err
+	}
+	if len(tiff) < 8 {
+		return nil, ErrNoExif
+	}
+	r := &exifReader{data: tiff}
+	switch string(tiff[:2]) {
+	case "II":
+		r.order = binary.LittleEndian
+	case "MM":
+		r.order = binary.BigEndian
+	default:
+		return nil, ErrNoExif
+	}
+	ifd0, err := r.readIFD(r.order.Uint32(tiff[4:8]))
+	if err != nil {
+		return nil, err
+	}
+	res := &Exif{}
+	datetime := r.ascii(ifd0[tagDateTime])
+	if e, ok := ifd0[tagExifIFD]; ok {
+		if sub, err := r.readIFD(e.offset); err == nil {
+			if s := r.ascii(sub[tagDateTimeOriginal]); s != "" {
+				datetime = s
+			}
+		}
+	}
+	if datetime != "" {
+		if t, err := time.ParseInLocation(exifDateTimeFormat, datetime, time.Local); err == nil {
+			res.CaptureAt = &t
+		}
+	}
+	if e, ok := ifd0[tagGPSIFD]; ok {
+		if gps, err := r.readIFD(e.offset); err == nil {
+			res.Latitude = r.coordinate(gps[tagGPSLatitude], r.ascii(gps[tagGPSLatitudeRef]), "S")
+			res.Longitude = r.coordinate(gps[tagGPSLongitude], r.ascii(gps[tagGPSLongitudeRef]), "W")
+		}
+	}
+	return res, nil
+}
+
+// findExif 定位JPEG APP1段中的TIFF数据
+func findExif(buf []byte) ([]byte, error) {
+	if len(buf) < 4 || buf[0] != 0xFF || buf[1] != 0xD8 {
+		return nil, ErrNoExif
+	}
+	i := 2
+	for i+4 <= len(buf) {
+		if buf[i] != 0xFF {
+			return nil, ErrNoExif
+		}
+		marker := buf[i+1]
+		// SOS之后为图像数据, 不再有元数据段
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+		size := int(binary.BigEndian.Uint16(buf[i+2 : i+4]))
+		if size < 2 || i+2+size > len(buf) {
+			return nil, ErrNoExif
+		}
+		seg := buf[i+4 : i+2+size]
+		if marker == 0xE1 && bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+			return seg[6:], nil
+		}
+		i += 2 + size
+	}
+	return nil, ErrNoExif
+}
+
+func (r *exifReader) readIFD(offset uint32) (map[uint16]exifEntry, error) {
+	start := int(offset)
+	if start < 0 || start+2 > len(r.data) {
+		return nil, ErrNoExif
+	}
+	n := int(r.order.Uint16(r.data[start:]))
+	if start+2+n*12 > len(r.data) {
+		return nil, ErrNoExif
+	}
+	entries := make(map[uint16]exifEntry, n)
+	for i := 0; i < n; i++ {
+		p := r.data[start+2+i*12:]
+		entries[r.order.Uint16(p)] = exifEntry{
+			typ:    r.order.Uint16(p[2:]),
+			count:  r.order.Uint32(p[4:]),
+			offset: r.order.Uint32(p[8:]),
+			raw:    p[8:12],
+		}
+	}
+	return entries, nil
+}
+
+// ascii 读取ASCII类型(2)的值
+func (r *exifReader) ascii(e exifEntry) string {
+	if e.typ != 2 || e.count == 0 {
+		return ""
+	}
+	var b []byte
+	if e.count <= 4 {
+		b = e.raw[:e.count]
+	} else {
+		end := int(e.offset) + int(e.count)
+		if end > len(r.data) || end < int(e.offset) {
+			return ""
+		}
+		b = r.data[e.offset:end]
+	}
+	return strings.TrimSpace(strings.TrimRight(string(b), "\x00"))
+}
+
+// coordinate 将度分秒(3个RATIONAL)换算为十进制度数
+func (r *exifReader) coordinate(e exifEntry, ref, negative string) *float64 {
+	if e.typ != 5 || e.count != 3 {
+		return nil
+	}
+	start := int(e.offset)
+	if start < 0 || start+24 > len(r.data) {
+		return nil
+	}
+	values := [3]float64{}
+	for i := range values {
+		num := r.order.Uint32(r.data[start+i*8:])
+		den := r.order.Uint32(r.data[start+i*8+4:])
+		if den == 0 {
+			return nil
+		}
+		values[i] = float64(num) / float64(den)
+	}
+	res := values[0] + values[1]/60 + values[2]/3600
+	if strings.EqualFold(ref, negative) {
+		res = -res
+	}
+	return &res
+}
+
+// Thumbnail 生成最长边不超过maxSize的JPEG缩略图
+func Thumbnail(buf []byte, maxSize int) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, errors.New("empty image")
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return nil, errors.New("empty image")
+	}
+	dw, dh := w, h
+	if w > maxSize || h > maxSize {
+		if w >= h {
+			dw, dh = maxSize, h*maxSize/w
+		} else {
+			dw, dh = w*maxSize/h, maxSize
+		}
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	// 区域平均缩放, 每个目标像素取对应源区域的均值
+	for y := 0; y < dh; y++ {
+		sy0, sy1 := b.Min.Y+y*h/dh, b.Min.Y+(y+1)*h/dh
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+		for x := 0; x < dw; x++ {
+			sx0, sx1 := b.Min.X+x*w/dw, b.Min.X+(x+1)*w/dw
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+			var sr, sg, sb, sa, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					sr, sg, sb, sa = sr+uint64(cr), sg+uint64(cg), sb+uint64(cb), sa+uint64(ca)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(sr / n >> 8),
+				G: uint8(sg / n >> 8),
+				B: uint8(sb / n >> 8),
+				A: uint8(sa / n >> 8),
+			})
+		}
+	}
+	out := &bytes.Buffer{}
+	if err := jpeg.Encode(out, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
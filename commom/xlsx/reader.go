@@ -0,0 +1,235 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoSheet     = errors.New("xlsx: no worksheet")
+	ErrTooManyRows = errors.New("xlsx: too many rows")
+	ErrTooManyCols = errors.New("xlsx: column out of range")
+	ErrTooLarge    = errors.New("xlsx: file content too large")
+)
+
+const (
+	// maxColumns 最大列数, 即 XFD 列
+	maxColumns = 16384
+	// maxXMLSize 单个 xml 文件解压后的大小上限
+	maxXMLSize = 64 << 20
+)
+
+// excelEpoch Excel 日期序列号起点(已计入 1900 闰年错误)
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.Local)
+
+type xmlRichText struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t *xmlRichText) String() string {
+	if len(t.R) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for i := range t.R {
+		b.WriteString(t.R[i].T)
+	}
+	return b.String()
+}
+
+type xmlSheetData struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R  string      `xml:"r,attr"`
+			T  string      `xml:"t,attr"`
+			V  string      `xml:"v"`
+			Is xmlRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadRows 读取第一个工作表的全部行, 单元格统一以字符串返回, 空行保留为空切片.
+// 行号超过 maxRows 或列超过 XFD 时返回错误, 不再继续读取
+func ReadRows(r io.ReaderAt, size int64, maxRows int) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	shared, err := readSharedStrings(files["xl/sharedStrings.xml"])
+	if err != nil {
+		return nil, err
+	}
+	sheet := files[firstSheetPath(files)]
+	if sheet == nil {
+		return nil, ErrNoSheet
+	}
+	data := xmlSheetData{}
+	if err := decodeZipXML(sheet, &data); err != nil {
+		return nil, err
+	}
+	rows := make([][]string, 0, len(data.Rows))
+	for _, xr := range data.Rows {
+		idx := xr.R - 1
+		if idx < len(rows) {
+			idx = len(rows)
+		}
+		if idx >= maxRows {
+			return nil, ErrTooManyRows
+		}
+		for len(rows) <= idx {
+			rows = append(rows, []string{})
+		}
+		cells := rows[idx]
+		for i, c := range xr.Cells {
+			col := i
+			if c.R != "" {
+				if n, ok := columnIndex(c.R); ok {
+					col = n
+				}
+			}
+			if col >= maxColumns {
+				return nil, ErrTooManyCols
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+			switch c.T {
+			case "s":
+				n, err := strconv.Atoi(strings.TrimSpace(c.V))
+				if err == nil && n >= 0 && n < len(shared) {
+					cells[col] = shared[n]
+				}
+			case "inlineStr":
+				cells[col] = c.Is.String()
+			case "b":
+				if c.V == "1" {
+					cells[col] = "TRUE"
+				} else {
+					cells[col] = "FALSE"
+				}
+			default:
+				cells[col] = c.V
+			}
+		}
+		rows[idx] = cells
+	}
+	return rows, nil
+}
+
+// ExcelTime 将 Excel 日期序列号转换为时间
+func ExcelTime(serial float64) time.Time {
+	return excelEpoch.Add(time.Duration(serial * float64(24*time.Hour))).Round(time.Second)
+}
+
+func readSharedStrings(f *zip.File) ([]string, error) {
+	if f == nil {
+		return nil, nil
+	}
+	sst := struct {
+		SI []xmlRichText `xml:"si"`
+	}{}
+	if err := decodeZipXML(f, &sst); err != nil {
+		return nil, err
+	}
+	res := make([]string, 0, len(sst.SI))
+	for i := range sst.SI {
+		res = append(res, sst.SI[i].String())
+	}
+	return res, nil
+}
+
+// firstSheetPath 按 workbook.xml 中的顺序定位第一个工作表
+func firstSheetPath(files map[string]*zip.File) string {
+	const fallback = "xl/worksheets/sheet1.xml"
+	wb := struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}{}
+	rels := struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}{}
+	if decodeZipXML(files["xl/workbook.xml"], &wb) != nil || len(wb.Sheets) == 0 {
+		return fallback
+	}
+	if decodeZipXML(files["xl/_rels/workbook.xml.rels"], &rels) != nil {
+		return fallback
+	}
+	for _, rel := range rels.Rels {
+		if rel.ID != wb.Sheets[0].ID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join("xl", rel.Target)
+	}
+	return fallback
+}
+
+func decodeZipXML(f *zip.File, v interface{}) error {
+	if f == nil {
+		return ErrNoSheet
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(&limitedReader{r: rc, n: maxXMLSize}).Decode(v)
+}
+
+// limitedReader 读取超过 n 字节时返回 ErrTooLarge, 避免解压炸弹
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		return 0, ErrTooLarge
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// columnIndex 由单元格坐标(如 AB12)得到从0开始的列号, 超过 XFD 时返回 maxColumns
+func columnIndex(ref string) (int, bool) {
+	col := 0
+	n := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+		n++
+		if col > maxColumns {
+			return maxColumns, true
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return col - 1, true
+}
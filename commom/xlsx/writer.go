@@ -0,0 +1,344 @@
+// Package xlsx 基于 archive/zip 的精简 Office Open XML 表格读写
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateTimeFormat = "2006-01-02 15:04:05"
+
+// Style 单元格样式
+type Style int
+
+const (
+	// StyleNormal 普通单元格, 带边框
+	StyleNormal Style = iota
+	// StyleHeader 表头, 加粗/灰底/居中
+	StyleHeader
+	// StyleBold 加粗, 用于小计/合计行
+	StyleBold
+	// StyleTitle 标题, 加粗大号居中, 无边框
+	StyleTitle
+)
+
+type row struct {
+	style  Style
+	values []interface{}
+}
+
+// Sheet 工作表
+type Sheet struct {
+	Name string
+	// FreezeRows 冻结前几行
+	FreezeRows int
+	rows       []row
+	stream     func(add func(values ...interface{}) error) error
+	widths     map[int]float64
+	merges     []string
+}
+
+// File 工作簿
+type File struct {
+	sheets []*Sheet
+}
+
+func NewFile() *File {
+	return &File{}
+}
+
+// AddSheet 新建工作表, 名称不超过31字符且不含 []:*?/\
+func (f *File) AddSheet(name string) *Sheet {
+	name = strings.NewReplacer("[", "", "]", "", ":", "", "*", "", "?", "", "/", "", "\\", "").Replace(name)
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	if name == "" {
+		name = fmt.Sprintf("Sheet%d", len(f.sheets)+1)
+	}
+	s := &Sheet{Name: name, widths: make(map[int]float64)}
+	f.sheets = append(f.sheets, s)
+	return s
+}
+
+// AddRow 追加普通行, 支持 string/数值/bool/time.Time 及其指针, nil 为空单元格
+func (s *Sheet) AddRow(values ...interface{}) {
+	s.AddStyledRow(StyleNormal, values...)
+}
+
+func (s *Sheet) AddStyledRow(style Style, values ...interface{}) {
+	s.rows = append(s.rows, row{style: style, values: values})
+}
+
+// StreamRows 设置写出时逐行生成的普通行, 接在已添加的行之后直接写入文件, 不在内存中保留.
+// rows 每调用一次 add 写出一行, add 或 rows 返回错误时写出中止
+func (s *Sheet) StreamRows(rows func(add func(values ...interface{}) error) error) {
+	s.stream = rows
+}
+
+// SetColWidth 设置列宽(字符数), col 从0开始
+func (s *Sheet) SetColWidth(col int, width float64) {
+	s.widths[col] = width
+}
+
+// Merge 合并单元格, 行列均从0开始
+func (s *Sheet) Merge(row1, col1, row2, col2 int) {
+	s.merges = append(s.merges, CellName(row1, col1)+":"+CellName(row2, col2))
+}
+
+// RowCount 当前行数, 不含 StreamRows 写出的行
+func (s *Sheet) RowCount() int {
+	return len(s.rows)
+}
+
+// ColName 列序号转列名, 0 -> A, 26 -> AA
+func ColName(col int) string {
+	name := ""
+	for col >= 0 {
+		name = string(rune('A'+col%26)) + name
+		col = col/26 - 1
+	}
+	return name
+}
+
+// CellName 单元格坐标, 行列均从0开始
+func CellName(row, col int) string {
+	return ColName(col) + strconv.Itoa(row+1)
+}
+
+// Write 写出 .xlsx
+func (f *File) Write(w io.Writer) error {
+	if len(f.sheets) == 0 {
+		f.AddSheet("Sheet1")
+	}
+	zw := zip.NewWriter(w)
+	parts := []struct {
+		name  string
+		write func(w io.Writer) error
+	}{
+		{"[Content_Types].xml", f.writeContentTypes},
+		{"_rels/.rels", writeString(rootRels)},
+		{"xl/workbook.xml", f.writeWorkbook},
+		{"xl/_rels/workbook.xml.rels", f.writeWorkbookRels},
+		{"xl/styles.xml", writeString(styles)},
+	}
+	for _, p := range parts {
+		pw, err := zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if err := p.write(pw); err != nil {
+			return err
+		}
+	}
+	for i, s := range f.sheets {
+		pw, err := zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1))
+		if err != nil {
+			return err
+		}
+		bw := bufio.NewWriter(pw)
+		if err := s.write(bw); err != nil {
+			return err
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeString(s string) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := io.WriteString(w, s)
+		return err
+	}
+}
+
+func escape(s string) string {
+	b := &strings.Builder{}
+	_ = xml.EscapeText(b, []byte(s))
+	return b.String()
+}
+
+func (f *File) writeContentTypes(w io.Writer) error {
+	b := &strings.Builder{}
+	b.WriteString(xml.Header)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := range f.sheets {
+		fmt.Fprintf(b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	b.WriteString(`</Types>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (f *File) writeWorkbook(w io.Writer) error {
+	b := &strings.Builder{}
+	b.WriteString(xml.Header)
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, s := range f.sheets {
+		fmt.Fprintf(b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(s.Name), i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (f *File) writeWorkbookRels(w io.Writer) error {
+	b := &strings.Builder{}
+	b.WriteString(xml.Header)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range f.sheets {
+		fmt.Fprintf(b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(f.sheets)+1)
+	b.WriteString(`</Relationships>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (s *Sheet) write(w *bufio.Writer) error {
+	w.WriteString(xml.Header)
+	w.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	if s.FreezeRows > 0 {
+		fmt.Fprintf(w, `<sheetViews><sheetView workbookViewId="0"><pane ySplit="%d" topLeftCell="A%d" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`,
+			s.FreezeRows, s.FreezeRows+1)
+	}
+	if len(s.widths) != 0 {
+		w.WriteString(`<cols>`)
+		for col := 0; col <= maxKey(s.widths); col++ {
+			if width, ok := s.widths[col]; ok {
+				fmt.Fprintf(w, `<col min="%d" max="%d" width="%g" customWidth="1"/>`, col+1, col+1, width)
+			}
+		}
+		w.WriteString(`</cols>`)
+	}
+	w.WriteString(`<sheetData>`)
+	for i, r := range s.rows {
+		writeRow(w, i, r.style, r.values)
+	}
+	if s.stream != nil {
+		i := len(s.rows)
+		err := s.stream(func(values ...interface{}) error {
+			writeRow(w, i, StyleNormal, values)
+			i++
+			// 写出失败时缓冲区保留首个错误
+			_, err := w.Write(nil)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	w.WriteString(`</sheetData>`)
+	if len(s.merges) != 0 {
+		fmt.Fprintf(w, `<mergeCells count="%d">`, len(s.merges))
+		for _, m := range s.merges {
+			fmt.Fprintf(w, `<mergeCell ref="%s"/>`, m)
+		}
+		w.WriteString(`</mergeCells>`)
+	}
+	_, err := w.WriteString(`</worksheet>`)
+	return err
+}
+
+func maxKey(m map[int]float64) int {
+	res := -1
+	for k := range m {
+		if k > res {
+			res = k
+		}
+	}
+	return res
+}
+
+func writeRow(w *bufio.Writer, i int, style Style, values []interface{}) {
+	fmt.Fprintf(w, `<row r="%d">`, i+1)
+	for j, v := range values {
+		writeCell(w, CellName(i, j), style, v)
+	}
+	w.WriteString(`</row>`)
+}
+
+func writeCell(w *bufio.Writer, ref string, style Style, v interface{}) {
+	switch val := v.(type) {
+	case nil:
+		fmt.Fprintf(w, `<c r="%s" s="%d"/>`, ref, style)
+	case *string, *int, *int64, *float64, *bool, *time.Time:
+		writeCell(w, ref, style, indirect(val))
+	case int:
+		fmt.Fprintf(w, `<c r="%s" s="%d"><v>%d</v></c>`, ref, style, val)
+	case int64:
+		fmt.Fprintf(w, `<c r="%s" s="%d"><v>%d</v></c>`, ref, style, val)
+	case float64:
+		// NaN/Inf 不是合法的单元格数值
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			writeCell(w, ref, style, nil)
+			return
+		}
+		fmt.Fprintf(w, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(val, 'f', -1, 64))
+	case bool:
+		writeCell(w, ref, style, map[bool]string{true: "是", false: "否"}[val])
+	case time.Time:
+		if val.IsZero() {
+			writeCell(w, ref, style, nil)
+			return
+		}
+		writeCell(w, ref, style, val.Format(dateTimeFormat))
+	case string:
+		fmt.Fprintf(w, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escape(val))
+	default:
+		writeCell(w, ref, style, fmt.Sprint(val))
+	}
+}
+
+// indirect 取指针值, 空指针返回 nil
+func indirect(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return nil
+	}
+	return rv.Elem().Interface()
+}
+
+const rootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// 样式序号与 Style 常量一一对应
+const styles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="3">` +
+	`<font><sz val="11"/><name val="宋体"/></font>` +
+	`<font><b/><sz val="11"/><name val="宋体"/></font>` +
+	`<font><b/><sz val="16"/><name val="宋体"/></font>` +
+	`</fonts>` +
+	`<fills count="3">` +
+	`<fill><patternFill patternType="none"/></fill>` +
+	`<fill><patternFill patternType="gray125"/></fill>` +
+	`<fill><patternFill patternType="solid"><fgColor rgb="FFD9D9D9"/><bgColor indexed="64"/></patternFill></fill>` +
+	`</fills>` +
+	`<borders count="2">` +
+	`<border><left/><right/><top/><bottom/><diagonal/></border>` +
+	`<border><left style="thin"/><right style="thin"/><top style="thin"/><bottom style="thin"/><diagonal/></border>` +
+	`</borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="1" xfId="0" applyBorder="1"><alignment vertical="center" wrapText="1"/></xf>` +
+	`<xf numFmtId="0" fontId="1" fillId="2" borderId="1" xfId="0" applyFont="1" applyFill="1" applyBorder="1" applyAlignment="1"><alignment horizontal="center" vertical="center" wrapText="1"/></xf>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="1" xfId="0" applyFont="1" applyBorder="1"><alignment vertical="center"/></xf>` +
+	`<xf numFmtId="0" fontId="2" fillId="0" borderId="0" xfId="0" applyFont="1" applyAlignment="1"><alignment horizontal="center" vertical="center"/></xf>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
@@ -0,0 +1,63 @@
+package xlsx
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestStreamRows(t *testing.T) {
+	f := NewFile()
+	s := f.AddSheet("test")
+	s.AddStyledRow(StyleHeader, "序号", "名称", "金额")
+	s.StreamRows(func(add func(values ...interface{}) error) error {
+		for i := 1; i <= 3; i++ {
+			if err := add(i, "a&b", float64(i)/2); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	buf := &bytes.Buffer{}
+	if err := f.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := ReadRows(bytes.NewReader(buf.Bytes()), int64(buf.Len()), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"序号", "名称", "金额"}, {"1", "a&b", "0.5"}, {"2", "a&b", "1"}, {"3", "a&b", "1.5"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("expected %v, got %v", want, rows)
+	}
+}
+
+func TestStreamRowsError(t *testing.T) {
+	f := NewFile()
+	want := errors.New("query failed")
+	f.AddSheet("test").StreamRows(func(add func(values ...interface{}) error) error {
+		return want
+	})
+	if err := f.Write(&bytes.Buffer{}); err != want {
+		t.Fatalf("expected %v, got %v", want, err)
+	}
+}
+
+func TestNonFiniteFloat(t *testing.T) {
+	f := NewFile()
+	f.AddSheet("test").AddRow("x", math.NaN(), math.Inf(1), math.Inf(-1), 1.25)
+	buf := &bytes.Buffer{}
+	if err := f.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := ReadRows(bytes.NewReader(buf.Bytes()), int64(buf.Len()), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"x", "", "", "", "1.25"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("expected %v, got %v", want, rows)
+	}
+}
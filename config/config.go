@@ -46,6 +46,46 @@ type Config struct {
 		SecretAccessKey string `toml:"secret_access_key"`
 		SSL             bool   `toml:"ssl"`
 	} `toml:"minio"`
+	Object struct {
+		// 文件签名下载链接及直传凭证的签名密钥, 须为随机值且不同于登录令牌密钥; 未配置时每次启动随机生成, 重启后已签发的链接失效
+		SignSecret string `toml:"sign_secret"`
+	} `toml:"object"`
+	Storage struct {
+		// 文件存储 minio:MinIO 服务(默认), fs:本地文件系统
+		Type string `toml:"type"`
+		// 本地文件系统存储的根目录, 仅 fs 有效
+		Path string `toml:"path"`
+	} `toml:"storage"`
+	Job struct {
+		// 文件对账执行时间(时)
+		ObjectReconcileHour int `toml:"object_reconcile_hour"`
+		// 文件对账保留期(小时), 创建未满保留期的文件及存储对象不参与对账
+		ObjectGraceHours int `toml:"object_grace_hours"`
+		// 是否删除未引用的文件及没有记录的存储对象, 否则仅记录日志
+		ObjectCleanup bool `toml:"object_cleanup"`
+	} `toml:"job"`
+	Upload struct {
+		// 各上传类别的校验规则, 键为类别名称, 未指定类别时使用 default
+		Categories map[string]UploadRule `toml:"categories"`
+		Scanner    struct {
+			// 文件安全扫描 noop:不扫描, clamd:clamd 服务, fake:仅识别 EICAR 测试文件(用于测试); 须明确配置, 其他类型启动失败
+			Type string `toml:"type"`
+			// clamd 地址 eg: unix:/var/run/clamav/clamd.ctl, tcp:127.0.0.1:3310
+			Addr string `toml:"addr"`
+			// 单个文件扫描超时(秒)
+			Timeout int `toml:"timeout"`
+		} `toml:"scanner"`
+	} `toml:"upload"`
+}
+
+// UploadRule 上传文件校验规则
+type UploadRule struct {
+	// 允许的扩展名(小写, 含点), 为空时不限
+	Extensions []string `toml:"extensions"`
+	// 允许的文件类型(按文件头识别), 支持 image/* 形式, 为空时不限
+	MimeTypes []string `toml:"mime_types"`
+	// 文件大小上限(MB)
+	MaxSize int64 `toml:"max_size"`
 }
 
 func GetConfig() *Config {
@@ -60,3 +100,9 @@ func GetConfig() *Config {
 	})
 	return &config
 }
+
+// SetConfig 替换当前配置, 不再读取 config.toml, 用于测试
+func SetConfig(c Config) {
+	once.Do(func() {})
+	config = c
+}
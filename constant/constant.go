@@ -11,6 +11,9 @@ const (
 	Page       = "page"
 	PageSize   = "page_size"
 	TextSearch = "keywords"
+	Sort       = "sort"
+	Cursor     = "cursor"
+	WithCount  = "with_count"
 )
 
 // http request
@@ -29,6 +32,69 @@ const (
 	Month            = "month"
 	Year             = "year"
 	ProjectID        = "project_id"
+	Description      = "description"
+	Confirm          = "confirm"
+	Snapshot         = "snapshot"
+	From             = "from"
+	To               = "to"
+	ListType         = "list_type"
+	Expires          = "expires"
+	Signature        = "signature"
+	Source           = "source"
+	Category         = "category"
+)
+
+// QuarantineBucketName 未通过安全扫描的文件隔离存储
+const QuarantineBucketName = "lpms-quarantine"
+
+// 文件签名链接有效期(秒)
+const (
+	// 下载签名链接
+	ObjectURLExpireSeconds = 300
+	// 存储直传预签名地址
+	ObjectPresignExpireSeconds = 900
+)
+
+// 保存视图的列表类型
+const (
+	// 储备库项目列表
+	ViewReserve = "reserve"
+	// 前期计划审核列表
+	ViewEarlyPlan = "early_plan"
+	// 出库审核列表
+	ViewOutStorageInspect = "out_storage_inspect"
+	// 实施库政府投资项目列表
+	ViewImplementGov = "implement_gov"
+	// 实施库产业项目列表
+	ViewImplementIndustry = "implement_industry"
+)
+
+// 项目附件所属项目库
+const (
+	// 储备库项目
+	AttachReserve = "reserve"
+	// 实施库政府投资项目
+	AttachGov = "gov"
+	// 实施库产业项目
+	AttachIndustry = "industry"
+)
+
+// 项目附件类别
+const (
+	// 其他
+	AttachOther = 0
+	// CAD图纸
+	AttachCad = 1
+	// 现场照片
+	AttachSitePhoto = 2
+	// 立项批复
+	AttachApproval = 3
+	// 可行性研究报告
+	AttachFeasibility = 4
+	// 用地预审意见
+	AttachLandPreReview = 5
+	// 环境影响评价
+	AttachEnvAssessment = 6
 )
 
 // reserver project status
@@ -74,3 +140,11 @@ const (
 	Red   = 1
 	Green = 2
 )
+
+// 项目本质类型
+const (
+	// 政府项目
+	GovProject = 1
+	// 产业项目
+	IndustryProject = 2
+)
@@ -2,6 +2,7 @@ package main
 
 import (
 	"lpms/commom/drivers/database"
+	"lpms/commom/drivers/minio"
 	"lpms/migrations"
 )
 
@@ -19,9 +20,9 @@ func init() {
 		panic("connect database error")
 	}
 
-	// if minio.GetDriver() == nil {
-	// 	panic("connect minio error")
-	// }
+	if minio.GetDriver() == nil {
+		panic("init storage error")
+	}
 
 	if err := migrations.Migrate(); err != nil {
 		panic(err)
@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"lpms/app"
+	"lpms/app/jobs"
 	"lpms/config"
 	"os"
 	"os/signal"
@@ -27,6 +28,8 @@ func main() {
 	cfg := config.GetConfig()
 	// go monitor.Start()
 	go app.Run(cfg.Server.Port)
+	// 定时任务
+	go jobs.Start()
 	// go app.RunJs(cfg.JsServer.Port)
 
 	// 性能监控
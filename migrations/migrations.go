@@ -13,6 +13,17 @@ var migrations = []*gormigrate.Migration{
 	// init data
 	versions.V0002InitData,
 	versions.V0003InitProgressTables,
+	versions.V0004InitContractTables,
+	versions.V0005ProgressPeriod,
+	versions.V0006InitProgressPhotoTables,
+	versions.V0007ProgressReminder,
+	versions.V0008ReportSnapshot,
+	versions.V0009KeywordSearch,
+	versions.V0010SavedView,
+	versions.V0011ProjectLocation,
+	versions.V0012ObjectContentType,
+	versions.V0013ProjectAttachment,
+	versions.V0014LegacyContract,
 }
 
 func Migrate() error {
@@ -0,0 +1,22 @@
+package versions
+
+import (
+	"lpms/app/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// V0004InitContractTables init tables
+var V0004InitContractTables = &gormigrate.Migration{
+	ID: "0004_init_contract",
+	Migrate: func(tx *gorm.DB) error {
+		if err := tx.AutoMigrate(
+			// 实施库-合同台账
+			models.Contract{},
+		); err != nil {
+			return err
+		}
+		return nil
+	},
+}
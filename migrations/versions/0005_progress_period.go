@@ -0,0 +1,24 @@
+package versions
+
+import (
+	"fmt"
+	"lpms/app/models"
+	"lpms/app/models/tables"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// V0005ProgressPeriod 进度表增加期间字段(year*12+month), 用于跨年累计
+var V0005ProgressPeriod = &gormigrate.Migration{
+	ID: "0005_progress_period",
+	Migrate: func(tx *gorm.DB) error {
+		if err := tx.AutoMigrate(
+			// 实施库-政府项目-进度
+			models.GovProgress{},
+		); err != nil {
+			return err
+		}
+		return tx.Exec(fmt.Sprintf("UPDATE %s SET period = year * 12 + month", tables.GovProgress)).Error
+	},
+}
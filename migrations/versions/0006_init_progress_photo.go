@@ -0,0 +1,19 @@
+package versions
+
+import (
+	"lpms/app/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// V0006InitProgressPhotoTables 月度进度现场照片
+var V0006InitProgressPhotoTables = &gormigrate.Migration{
+	ID: "0006_init_progress_photo",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(
+			// 实施库-进度现场照片
+			models.ProgressPhoto{},
+		)
+	},
+}
@@ -0,0 +1,21 @@
+package versions
+
+import (
+	"lpms/app/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// V0007ProgressReminder 实施库项目增加所属街镇, 新增进度逾期提醒表
+var V0007ProgressReminder = &gormigrate.Migration{
+	ID: "0007_progress_reminder",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(
+			// 实施库-政府项目
+			models.ImplementGov{},
+			// 实施库-进度逾期提醒
+			models.ProgressReminder{},
+		)
+	},
+}
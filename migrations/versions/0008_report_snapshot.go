@@ -0,0 +1,21 @@
+package versions
+
+import (
+	"lpms/app/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// V0008ReportSnapshot 新增月末统计快照表
+var V0008ReportSnapshot = &gormigrate.Migration{
+	ID: "0008_report_snapshot",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(
+			// 实施库-月末统计快照
+			models.ReportSnapshot{},
+			// 实施库-月末统计快照项目数据
+			models.SnapshotProject{},
+		)
+	},
+}
@@ -0,0 +1,35 @@
+package versions
+
+import (
+	"fmt"
+	"lpms/app/models/tables"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// V0009KeywordSearch 启用 pg_trgm, 为储备库及实施库项目建立关键词检索索引
+var V0009KeywordSearch = &gormigrate.Migration{
+	ID: "0009_keyword_search",
+	Migrate: func(tx *gorm.DB) error {
+		if err := tx.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+			return err
+		}
+		indexes := []struct {
+			table string
+			doc   string
+		}{
+			{tables.Reserve, tables.ReserveSearchDoc},
+			{tables.ImplementGov, tables.ImplementGovSearchDoc},
+			{tables.ImplementIndustry, tables.ImplementIndustrySearchDoc},
+		}
+		for _, idx := range indexes {
+			sql := fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_search ON %s USING gin ((%s) gin_trgm_ops)",
+				idx.table, idx.table, idx.doc)
+			if err := tx.Exec(sql).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,21 @@
+package versions
+
+import (
+	"lpms/app/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// V0010SavedView 用户增加所属单位, 新增列表保存视图表
+var V0010SavedView = &gormigrate.Migration{
+	ID: "0010_saved_view",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(
+			// 用户
+			models.User{},
+			// 列表保存视图
+			models.SavedView{},
+		)
+	},
+}
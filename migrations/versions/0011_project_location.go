@@ -0,0 +1,35 @@
+package versions
+
+import (
+	"fmt"
+	"lpms/app/models"
+	"lpms/app/models/tables"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// V0011ProjectLocation 储备库及实施库项目增加项目位置、红线范围(GeoJSON), 实施库按位置经纬度建立索引用于地图范围查询
+var V0011ProjectLocation = &gormigrate.Migration{
+	ID: "0011_project_location",
+	Migrate: func(tx *gorm.DB) error {
+		if err := tx.AutoMigrate(
+			// 储备库项目
+			models.ReservePro{},
+			// 实施库-政府项目
+			models.ImplementGov{},
+			// 实施库-产业项目
+			models.ImpleIndustry{},
+		); err != nil {
+			return err
+		}
+		for _, table := range []string{tables.ImplementGov, tables.ImplementIndustry} {
+			sql := fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_location ON %s (%s, %s)",
+				table, table, tables.LocationLng, tables.LocationLat)
+			if err := tx.Exec(sql).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,19 @@
+package versions
+
+import (
+	"lpms/app/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// V0012ObjectContentType 文件对象增加文件类型
+var V0012ObjectContentType = &gormigrate.Migration{
+	ID: "0012_object_content_type",
+	Migrate: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(
+			// 文件对象
+			models.Object{},
+		)
+	},
+}
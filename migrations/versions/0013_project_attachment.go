@@ -0,0 +1,52 @@
+package versions
+
+import (
+	"fmt"
+	"lpms/app/models"
+	"lpms/app/models/tables"
+	"lpms/constant"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// V0013ProjectAttachment 新增项目附件表, 并将项目已有的CAD文件及无拆迁照片迁入附件
+var V0013ProjectAttachment = &gormigrate.Migration{
+	ID: "0013_project_attachment",
+	Migrate: func(tx *gorm.DB) error {
+		if err := tx.AutoMigrate(
+			// 项目附件
+			models.ProjectAttachment{},
+		); err != nil {
+			return err
+		}
+		projects := []struct {
+			source string
+			table  string
+		}{
+			{constant.AttachReserve, tables.Reserve},
+			{constant.AttachGov, tables.ImplementGov},
+			{constant.AttachIndustry, tables.ImplementIndustry},
+		}
+		legacy := []struct {
+			column   string
+			category int
+		}{
+			{"upload_cad_id", constant.AttachCad},
+			{"site_photo", constant.AttachSitePhoto},
+		}
+		for _, project := range projects {
+			for sort, field := range legacy {
+				sql := fmt.Sprintf(`INSERT INTO %s (create_at, update_at, create_by, update_by, source, project_id,
+object_id, category, description, sort)
+SELECT now(), now(), p.create_by, p.create_by, ?, p.id, o.id, ?, '', ?
+FROM %s p JOIN %s o ON o.id = p.%s
+ON CONFLICT DO NOTHING`, tables.ProjectAttachment, project.table, tables.Object, field.column)
+				if err := tx.Exec(sql, project.source, field.category, sort).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,110 @@
+package versions
+
+import (
+	"bytes"
+	"fmt"
+	"lpms/app/models"
+	"lpms/app/models/tables"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"github.com/goccy/go-json"
+	"gorm.io/gorm"
+)
+
+// legacyContract 月度进度 contracts 字段中的合同, 字段与合同台账接口一致
+type legacyContract struct {
+	ContractNo   string     `json:"contract_no"`
+	Name         string     `json:"name"`
+	Counterparty string     `json:"counterparty"`
+	Amount       *float64   `json:"amount"`
+	SignDate     *time.Time `json:"sign_date"`
+	ContractType *int       `json:"contract_type"`
+	Comment      string     `json:"comment"`
+}
+
+var legacyContractFields = map[string]bool{
+	"contract_no": true, "name": true, "counterparty": true, "amount": true,
+	"sign_date": true, "contract_type": true, "comment": true,
+}
+
+// parseLegacyContract 解析一条历史合同, 含其他字段或字段类型不符时原样保存在备注中, 避免丢失数据
+func parseLegacyContract(raw json.RawMessage) models.Contract {
+	fields := make(map[string]json.RawMessage)
+	var c legacyContract
+	parsed := json.Unmarshal(raw, &fields) == nil && json.Unmarshal(raw, &c) == nil
+	for key := range fields {
+		if !legacyContractFields[key] {
+			parsed = false
+		}
+	}
+	if !parsed {
+		return models.Contract{Comment: "历史合同信息: " + string(raw)}
+	}
+	return models.Contract{
+		ContractNo:   c.ContractNo,
+		Name:         c.Name,
+		Counterparty: c.Counterparty,
+		Amount:       c.Amount,
+		SignDate:     c.SignDate,
+		ContractType: c.ContractType,
+		Comment:      c.Comment,
+	}
+}
+
+// legacyContracts 解析月度进度 contracts 字段, 数组按元素逐条解析, 其他非空内容作为一条合同保存在备注中
+func legacyContracts(raw json.RawMessage) []models.Contract {
+	raw = bytes.TrimSpace(raw)
+	switch string(raw) {
+	case "", "null", "[]", "{}", `""`:
+		return nil
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return []models.Contract{parseLegacyContract(raw)}
+	}
+	contracts := make([]models.Contract, 0, len(items))
+	for _, item := range items {
+		if s := string(bytes.TrimSpace(item)); s == "null" || s == "{}" {
+			continue
+		}
+		contracts = append(contracts, parseLegacyContract(item))
+	}
+	return contracts
+}
+
+// V0014LegacyContract 将月度进度 contracts 字段中的历史合同迁入合同台账, 已在台账中填报合同的进度跳过;
+// 原字段保留不再读取
+var V0014LegacyContract = &gormigrate.Migration{
+	ID: "0014_legacy_contract",
+	Migrate: func(tx *gorm.DB) error {
+		var progresses []models.GovProgress
+		err := tx.Select("id", "project_id", "year", "month", "create_by", "contracts").
+			Where("contracts IS NOT NULL").
+			Where(fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s c WHERE c.progress_id = %s.id)",
+				tables.Contract, tables.GovProgress)).
+			Order("id").Find(&progresses).Error
+		if err != nil {
+			return err
+		}
+		contracts := make([]models.Contract, 0)
+		for i := range progresses {
+			p := &progresses[i]
+			for _, contract := range legacyContracts(p.Contracts) {
+				progressID := p.ID
+				contract.ProjectID = p.ProjectID
+				contract.ProgressID = &progressID
+				contract.Year = p.Year
+				contract.Month = p.Month
+				contract.Attachments = json.RawMessage("[]")
+				contract.CreateBy = p.CreateBy
+				contract.UpdateBy = p.CreateBy
+				contracts = append(contracts, contract)
+			}
+		}
+		if len(contracts) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(contracts, 500).Error
+	},
+}
@@ -0,0 +1,42 @@
+package versions
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+func TestLegacyContracts(t *testing.T) {
+	for _, raw := range []string{"", "null", "[]", "{}", `""`, "[null, {}]"} {
+		if got := legacyContracts(json.RawMessage(raw)); len(got) != 0 {
+			t.Errorf("expected no contract for %q, got %+v", raw, got)
+		}
+	}
+
+	got := legacyContracts(json.RawMessage(`[
+{"contract_no": "HT-1", "name": "施工合同", "counterparty": "某建设公司", "amount": 12.5,
+ "sign_date": "2022-03-01T00:00:00+08:00", "contract_type": 0, "comment": "备注"},
+{"contract_no": "HT-2", "amount": "30"},
+{"no": "HT-3"}]`))
+	if len(got) != 3 {
+		t.Fatalf("expected 3 contracts, got %d", len(got))
+	}
+	c := got[0]
+	if c.ContractNo != "HT-1" || c.Name != "施工合同" || c.Counterparty != "某建设公司" || c.Amount == nil ||
+		*c.Amount != 12.5 || c.SignDate == nil || c.SignDate.Month() != 3 || c.ContractType == nil ||
+		*c.ContractType != 0 || c.Comment != "备注" {
+		t.Fatalf("unexpected contract %+v", c)
+	}
+	// 字段类型不符或含其他字段时原样保存在备注中
+	for _, c := range got[1:] {
+		if c.ContractNo != "" || !strings.HasPrefix(c.Comment, "历史合同信息: {") {
+			t.Fatalf("expected raw contract kept in comment, got %+v", c)
+		}
+	}
+
+	got = legacyContracts(json.RawMessage(`"2022年签订施工合同一份"`))
+	if len(got) != 1 || got[0].Comment != `历史合同信息: "2022年签订施工合同一份"` {
+		t.Fatalf("expected text kept in comment, got %+v", got)
+	}
+}
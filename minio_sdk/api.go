@@ -1,12 +1,20 @@
 package minio_sdk
 
-import "io"
+import (
+	"io"
+	"net/url"
+	"time"
+)
 
 type Client interface {
 	UploadObject(bucketName, objName string, content []byte) error
 	UploadObjectFromReader(bucketName, objName string, reader io.Reader, objSize int64) error
 	UploadObjectFromFile(bucketName, objName, filePath string) error
 	DownloadObject(bucketName, objName string) ([]byte, error)
+	OpenObject(bucketName, objName string) (*ObjectContent, error)
+	StatObject(bucketName, objName string) (*Object, error)
+	PresignPutObject(bucketName, objName string, expires time.Duration) (string, error)
+	PresignGetObject(bucketName, objName string, expires time.Duration, params url.Values) (string, error)
 	DeleteObject(bucketName, objName string) error
 	ListBuckets() ([]Bucket, error)
 	ListObjects(bucketName string) ([]Object, error)
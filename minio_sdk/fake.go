@@ -0,0 +1,199 @@
+package minio_sdk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// fakeClient 进程内的对象存储, 仅用于测试及本地调试. 预签名地址不可实际访问,
+// 模拟客户端直传时直接调用 UploadObject 写入
+type fakeClient struct {
+	mu      sync.Mutex
+	buckets map[string]*fakeBucket
+}
+
+type fakeBucket struct {
+	createAt time.Time
+	objects  map[string]*fakeObject
+}
+
+type fakeObject struct {
+	content  []byte
+	modified time.Time
+}
+
+type fakeContent struct {
+	*bytes.Reader
+}
+
+func (fakeContent) Close() error { return nil }
+
+// NewFake 进程内对象存储
+func NewFake() Client {
+	return &fakeClient{buckets: make(map[string]*fakeBucket)}
+}
+
+// bucket 取桶, 不存在时创建
+func (f *fakeClient) bucket(bucketName string) *fakeBucket {
+	b, ok := f.buckets[bucketName]
+	if !ok {
+		b = &fakeBucket{createAt: time.Now(), objects: make(map[string]*fakeObject)}
+		f.buckets[bucketName] = b
+	}
+	return b
+}
+
+func (f *fakeClient) object(bucketName, objName string) (*fakeObject, error) {
+	b, ok := f.buckets[bucketName]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	obj, ok := b.objects[objName]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return obj, nil
+}
+
+func (f *fakeClient) UploadObject(bucketName, objName string, content []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.bucket(bucketName).objects[objName] = &fakeObject{content: append([]byte(nil), content...), modified: time.Now()}
+	return nil
+}
+
+func (f *fakeClient) UploadObjectFromReader(bucketName, objName string, reader io.Reader, objSize int64) error {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	if objSize >= 0 && int64(len(content)) != objSize {
+		return fmt.Errorf("object size %d does not match %d", len(content), objSize)
+	}
+	return f.UploadObject(bucketName, objName, content)
+}
+
+func (f *fakeClient) UploadObjectFromFile(bucketName, objName, filePath string) error {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	return f.UploadObject(bucketName, objName, content)
+}
+
+func (f *fakeClient) DownloadObject(bucketName, objName string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	obj, err := f.object(bucketName, objName)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), obj.content...), nil
+}
+
+func (f *fakeClient) OpenObject(bucketName, objName string) (*ObjectContent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	obj, err := f.object(bucketName, objName)
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectContent{
+		ReadSeekCloser: fakeContent{bytes.NewReader(obj.content)},
+		Size:           int64(len(obj.content)),
+		ContentType:    "application/octet-stream",
+		ETag:           fmt.Sprintf("%x-%d", obj.modified.UnixNano(), len(obj.content)),
+		LastModified:   obj.modified,
+	}, nil
+}
+
+func (f *fakeClient) StatObject(bucketName, objName string) (*Object, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	obj, err := f.object(bucketName, objName)
+	if err != nil {
+		return nil, err
+	}
+	return &Object{Name: objName, Size: int64(len(obj.content)), ContentType: "application/octet-stream",
+		LastModified: obj.modified}, nil
+}
+
+func fakeURL(method, bucketName, objName string, expires time.Duration, params url.Values) string {
+	query := url.Values{}
+	for k, v := range params {
+		query[k] = v
+	}
+	query.Set("X-Fake-Method", method)
+	query.Set("X-Fake-Expires", time.Now().Add(expires).Format(time.RFC3339))
+	u := url.URL{Scheme: "http", Host: "fake-storage", Path: "/" + bucketName + "/" + objName, RawQuery: query.Encode()}
+	return u.String()
+}
+
+func (f *fakeClient) PresignPutObject(bucketName, objName string, expires time.Duration) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.bucket(bucketName)
+	return fakeURL("PUT", bucketName, objName, expires, nil), nil
+}
+
+func (f *fakeClient) PresignGetObject(bucketName, objName string, expires time.Duration, params url.Values) (string,
+	error) {
+	return fakeURL("GET", bucketName, objName, expires, params), nil
+}
+
+func (f *fakeClient) DeleteObject(bucketName, objName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if b, ok := f.buckets[bucketName]; ok {
+		delete(b.objects, objName)
+	}
+	return nil
+}
+
+func (f *fakeClient) ListBuckets() ([]Bucket, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buckets := make([]Bucket, 0, len(f.buckets))
+	for name, b := range f.buckets {
+		buckets = append(buckets, Bucket{Name: name, CreateAt: b.createAt})
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Name < buckets[j].Name })
+	return buckets, nil
+}
+
+func (f *fakeClient) ListObjects(bucketName string) ([]Object, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	objects := make([]Object, 0)
+	b, ok := f.buckets[bucketName]
+	if !ok {
+		return objects, nil
+	}
+	for name, obj := range b.objects {
+		objects = append(objects, Object{Name: name, Size: int64(len(obj.content)), ContentType: "application/octet-stream",
+			LastModified: obj.modified})
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Name < objects[j].Name })
+	return objects, nil
+}
+
+func (f *fakeClient) DeleteBucket(bucketName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, ok := f.buckets[bucketName]
+	if !ok {
+		return nil
+	}
+	if len(b.objects) > 0 {
+		return errors.New("bucket is not empty")
+	}
+	delete(f.buckets, bucketName)
+	return nil
+}
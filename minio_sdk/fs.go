@@ -0,0 +1,278 @@
+package minio_sdk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fsTempDir 写入中的临时文件目录, 以 . 开头不会被当作桶
+const fsTempDir = ".tmp"
+
+// ErrPresignUnsupported 存储不支持预签名地址
+var ErrPresignUnsupported = errors.New("presigned url is not supported by storage")
+
+// fsClient 本地文件系统存储, 桶对应根目录下的子目录, 对象名称中的 / 对应子目录.
+// 写入先写到根目录下的临时目录再重命名, 读取时不会看到写了一半的文件; 不支持预签名地址
+type fsClient struct {
+	root string
+}
+
+// NewFS 以 root 为根目录的文件系统存储, 目录不存在时创建
+func NewFS(root string) (Client, error) {
+	if root == "" {
+		return nil, errors.New("storage path is required")
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Join(root, fsTempDir), 0o755); err != nil {
+		return nil, err
+	}
+	return &fsClient{root: root}, nil
+}
+
+func (f *fsClient) bucketPath(bucketName string) (string, error) {
+	if bucketName == "" || strings.HasPrefix(bucketName, ".") || strings.ContainsAny(bucketName, `/\`) {
+		return "", fmt.Errorf("invalid bucket name %q", bucketName)
+	}
+	return filepath.Join(f.root, bucketName), nil
+}
+
+// objectPath 对象文件路径, 对象名称须为不含 . 及 .. 路径段的相对路径
+func (f *fsClient) objectPath(bucketName, objName string) (string, error) {
+	dir, err := f.bucketPath(bucketName)
+	if err != nil {
+		return "", err
+	}
+	if objName == "" || strings.HasPrefix(objName, "/") || strings.Contains(objName, `\`) ||
+		path.Clean(objName) != objName || objName == ".." || strings.HasPrefix(objName, "../") {
+		return "", fmt.Errorf("invalid object name %q", objName)
+	}
+	return filepath.Join(dir, filepath.FromSlash(objName)), nil
+}
+
+func fsContentType(objName string) string {
+	if contentType := mime.TypeByExtension(path.Ext(objName)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+func fsETag(info fs.FileInfo) string {
+	return fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size())
+}
+
+// stat 对象文件属性, 不存在或为目录时返回 ErrObjectNotFound
+func (f *fsClient) stat(bucketName, objName string) (string, fs.FileInfo, error) {
+	p, err := f.objectPath(bucketName, objName)
+	if err != nil {
+		return "", nil, err
+	}
+	info, err := os.Stat(p)
+	if errors.Is(err, fs.ErrNotExist) || err == nil && info.IsDir() {
+		return "", nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return p, info, nil
+}
+
+// write 写入临时文件并同步到磁盘后重命名为对象文件, size 为 -1 时不校验大小
+func (f *fsClient) write(bucketName, objName string, reader io.Reader, size int64) error {
+	target, err := f.objectPath(bucketName, objName)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Join(f.root, fsTempDir), "upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	n, err := io.Copy(tmp, reader)
+	if err == nil && size >= 0 && n != size {
+		err = fmt.Errorf("object size %d does not match %d", n, size)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (f *fsClient) UploadObject(bucketName, objName string, content []byte) error {
+	return f.write(bucketName, objName, bytes.NewReader(content), int64(len(content)))
+}
+
+func (f *fsClient) UploadObjectFromReader(bucketName, objName string, reader io.Reader, objSize int64) error {
+	return f.write(bucketName, objName, reader, objSize)
+}
+
+func (f *fsClient) UploadObjectFromFile(bucketName, objName, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return f.write(bucketName, objName, file, -1)
+}
+
+func (f *fsClient) DownloadObject(bucketName, objName string) ([]byte, error) {
+	p, _, err := f.stat(bucketName, objName)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(p)
+}
+
+func (f *fsClient) OpenObject(bucketName, objName string) (*ObjectContent, error) {
+	p, _, err := f.stat(bucketName, objName)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	// 以打开后的属性为准, 避免期间被覆盖
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &ObjectContent{
+		ReadSeekCloser: file,
+		Size:           info.Size(),
+		ContentType:    fsContentType(objName),
+		ETag:           fsETag(info),
+		LastModified:   info.ModTime(),
+	}, nil
+}
+
+func (f *fsClient) StatObject(bucketName, objName string) (*Object, error) {
+	_, info, err := f.stat(bucketName, objName)
+	if err != nil {
+		return nil, err
+	}
+	return &Object{Name: objName, Size: info.Size(), ContentType: fsContentType(objName),
+		LastModified: info.ModTime()}, nil
+}
+
+func (f *fsClient) PresignPutObject(bucketName, objName string, expires time.Duration) (string, error) {
+	return "", ErrPresignUnsupported
+}
+
+func (f *fsClient) PresignGetObject(bucketName, objName string, expires time.Duration, params url.Values) (string,
+	error) {
+	return "", ErrPresignUnsupported
+}
+
+// DeleteObject 删除对象文件及因此变空的上级目录, 对象不存在时忽略
+func (f *fsClient) DeleteObject(bucketName, objName string) error {
+	p, err := f.objectPath(bucketName, objName)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	bucket, _ := f.bucketPath(bucketName)
+	for dir := filepath.Dir(p); dir != bucket; dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+func (f *fsClient) ListBuckets() ([]Bucket, error) {
+	entries, err := os.ReadDir(f.root)
+	if err != nil {
+		return nil, err
+	}
+	buckets := make([]Bucket, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, Bucket{Name: entry.Name(), CreateAt: info.ModTime()})
+	}
+	return buckets, nil
+}
+
+// ListObjects 递归列出桶内全部对象, 桶不存在时返回空
+func (f *fsClient) ListObjects(bucketName string) ([]Object, error) {
+	dir, err := f.bucketPath(bucketName)
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]Object, 0)
+	err = filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if p == dir && errors.Is(err, fs.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		objects = append(objects, Object{Name: name, Size: info.Size(), ContentType: fsContentType(name),
+			LastModified: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// DeleteBucket 删除桶目录, 桶内有对象时返回错误
+func (f *fsClient) DeleteBucket(bucketName string) error {
+	dir, err := f.bucketPath(bucketName)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(dir); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
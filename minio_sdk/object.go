@@ -2,17 +2,23 @@ package minio_sdk
 
 import (
 	"context"
+	"errors"
 	"io"
+	"net/url"
 	"time"
 
 	"github.com/minio/minio-go/v7"
 )
 
+// ErrObjectNotFound 对象不存在
+var ErrObjectNotFound = errors.New("object not found")
+
 type Object struct {
-	Name        string    `json:"name"`
-	Size        int64     `json:"size"`
-	ExpireAt    time.Time `json:"expire_at"`
-	ContentType string    `json:"content_type"`
+	Name         string    `json:"name"`
+	Size         int64     `json:"size"`
+	ExpireAt     time.Time `json:"expire_at"`
+	ContentType  string    `json:"content_type"`
+	LastModified time.Time `json:"last_modified"`
 }
 
 func (c *client) UploadObject(bucketName, objName string, content []byte) error {
@@ -53,19 +59,99 @@ func (c *client) DownloadObject(bucketName, objName string) ([]byte, error) {
 	return io.ReadAll(obj)
 }
 
+// ObjectContent 可按需读取、定位的对象内容及属性, 使用后须关闭
+type ObjectContent struct {
+	io.ReadSeekCloser
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+}
+
+// OpenObject 打开对象, 内容在读取时才从存储获取, 对象不存在时返回错误
+func (c *client) OpenObject(bucketName, objName string) (*ObjectContent, error) {
+	obj, err := c.client.GetObject(context.Background(), bucketName, objName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	info, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+	return &ObjectContent{
+		ReadSeekCloser: obj,
+		Size:           info.Size,
+		ContentType:    info.ContentType,
+		ETag:           info.ETag,
+		LastModified:   info.LastModified,
+	}, nil
+}
+
+// StatObject 对象属性, 对象不存在时返回 ErrObjectNotFound
+func (c *client) StatObject(bucketName, objName string) (*Object, error) {
+	info, err := c.client.StatObject(context.Background(), bucketName, objName, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+	return &Object{
+		Name:         info.Key,
+		Size:         info.Size,
+		ExpireAt:     info.Expires,
+		ContentType:  info.ContentType,
+		LastModified: info.LastModified,
+	}, nil
+}
+
+// PresignPutObject 生成直传对象的 PUT 地址
+func (c *client) PresignPutObject(bucketName, objName string, expires time.Duration) (string, error) {
+	if err := c.MakeBucket(bucketName); err != nil {
+		return "", err
+	}
+	u, err := c.client.PresignedPutObject(context.Background(), bucketName, objName, expires)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// PresignGetObject 生成下载对象的 GET 地址, params 可指定 response-content-type 等响应头
+func (c *client) PresignGetObject(bucketName, objName string, expires time.Duration, params url.Values) (string,
+	error) {
+	u, err := c.client.PresignedGetObject(context.Background(), bucketName, objName, expires, params)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
 func (c *client) DeleteObject(bucketName, objName string) error {
 	return c.client.RemoveObject(context.Background(), bucketName, objName, minio.RemoveObjectOptions{})
 }
 
+// ListObjects 递归列出桶内全部对象, 桶不存在时返回空
 func (c *client) ListObjects(bucketName string) ([]Object, error) {
 	objects := make([]Object, 0)
-	objs := c.client.ListObjects(context.Background(), bucketName, minio.ListObjectsOptions{})
+	objs := c.client.ListObjects(context.Background(), bucketName, minio.ListObjectsOptions{Recursive: true})
 	for obj := range objs {
+		if obj.Err != nil {
+			if minio.ToErrorResponse(obj.Err).Code == "NoSuchBucket" {
+				return objects, nil
+			}
+			return nil, obj.Err
+		}
 		objects = append(objects, Object{
-			Name:        obj.Key,
-			Size:        obj.Size,
-			ExpireAt:    obj.Expires,
-			ContentType: obj.ContentType,
+			Name:         obj.Key,
+			Size:         obj.Size,
+			ExpireAt:     obj.Expires,
+			ContentType:  obj.ContentType,
+			LastModified: obj.LastModified,
 		})
 	}
 	return objects, nil